@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -10,30 +11,58 @@ import (
 
 	jwt "github.com/dgrijalva/jwt-go"
 	"github.com/wesleywcr/dev-book/api/config"
+	"github.com/wesleywcr/dev-book/api/models"
 )
 
-func CreateToken(userID uint64) (string, error) {
+// CreateToken signs a token for the user carrying the session it belongs to.
+// The role is not carried, as it is read from the database at every request.
+func CreateToken(userID uint64, sessionID uint64) (string, error) {
 	permitions := jwt.MapClaims{}
 	permitions["authorized"] = true
-	permitions["exp"] = time.Now().Add(time.Hour * 6).Unix() //6hrs
+	permitions["exp"] = time.Now().Add(config.Settings.JWT.Expiration).Unix()
 	permitions["userId"] = userID
+	permitions["sessionId"] = sessionID
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, permitions)
-	return token.SignedString([]byte(config.SecretKey))
+	return token.SignedString([]byte(config.Settings.JWT.Secret))
 }
 
-func ValidateToken(r *http.Request) error {
-	tokenString := extractToken(r)
-	token, error := jwt.Parse(tokenString, returnVerificationKey)
+// ChallengeLifetime is how long the challenge of a two-factor login lasts.
+const ChallengeLifetime = 5 * time.Minute
+
+// CreateChallengeToken signs the token of a user who passed the password step
+// of the login and must still enter a two-factor code, carrying the ID of
+// the challenge, which is stored so the token is accepted only once. It
+// cannot be used as an access token.
+func CreateChallengeToken(userID uint64, challengeID string) (string, error) {
+	permitions := jwt.MapClaims{}
+	permitions["challenge"] = true
+	permitions["jti"] = challengeID
+	permitions["exp"] = time.Now().Add(ChallengeLifetime).Unix()
+	permitions["userId"] = userID
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, permitions)
+	return token.SignedString([]byte(config.Settings.JWT.Secret))
+}
+
+// ValidateChallengeToken returns the user a challenge token was issued to and
+// the ID of the challenge.
+func ValidateChallengeToken(tokenString string) (uint64, string, error) {
+	permitions, error := parseClaims(tokenString)
 	if error != nil {
-		return error
+		return 0, "", error
 	}
-
-	if _, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		return nil
+	challengeId, _ := permitions["jti"].(string)
+	if challenge, _ := permitions["challenge"].(bool); !challenge || challengeId == "" {
+		return 0, "", errors.New("Desafio inválido")
 	}
-	return errors.New("Token inválido!")
+	userId, _ := permitions["userId"].(float64)
+	return uint64(userId), challengeId, nil
+}
 
+func ValidateToken(r *http.Request) error {
+	_, error := extractClaims(r)
+	return error
 }
 
 func extractToken(r *http.Request) string {
@@ -46,25 +75,75 @@ func extractToken(r *http.Request) string {
 }
 
 func ExtractUserId(r *http.Request) (uint64, error) {
-	tokenString := extractToken(r)
-	token, error := jwt.Parse(tokenString, returnVerificationKey)
+	permitions, error := extractClaims(r)
 	if error != nil {
 		return 0, error
 	}
-	if permitions, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		userId, error := strconv.ParseUint(fmt.Sprintf("%.0f", permitions["userId"]), 10, 64)
-		if error != nil {
-			return 0, error
-		}
-		return userId, nil
+	userId, error := strconv.ParseUint(fmt.Sprintf("%.0f", permitions["userId"]), 10, 64)
+	if error != nil {
+		return 0, error
+	}
+	return userId, nil
+}
+
+// ExtractSessionId returns the session the token belongs to, or 0 for the
+// tokens issued before sessions existed.
+func ExtractSessionId(r *http.Request) (uint64, error) {
+	permitions, error := extractClaims(r)
+	if error != nil {
+		return 0, error
+	}
+	sessionId, _ := permitions["sessionId"].(float64)
+	return uint64(sessionId), nil
+}
+
+// roleKey is the key of the current role of the user in the context of a
+// request.
+type roleKey struct{}
+
+// WithRole returns the request carrying the role the user has now, as read
+// from the database, which ExtractRole returns.
+func WithRole(r *http.Request, role models.Role) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), roleKey{}, role))
+}
+
+// ExtractRole returns the current role of the user, set by WithRole, so a
+// new role applies at once.
+func ExtractRole(r *http.Request) (models.Role, error) {
+	role, _ := r.Context().Value(roleKey{}).(models.Role)
+	if role == "" {
+		return "", errors.New("Sessão inválida, faça login novamente")
+	}
+	return role, nil
+}
 
+// extractClaims returns the claims of the access token of the request. The
+// challenge tokens of the two-factor login are refused.
+func extractClaims(r *http.Request) (jwt.MapClaims, error) {
+	permitions, error := parseClaims(extractToken(r))
+	if error != nil {
+		return nil, error
+	}
+	if challenge, _ := permitions["challenge"].(bool); challenge {
+		return nil, errors.New("Token inválido")
+	}
+	return permitions, nil
+}
+
+func parseClaims(tokenString string) (jwt.MapClaims, error) {
+	token, error := jwt.Parse(tokenString, returnVerificationKey)
+	if error != nil {
+		return nil, error
+	}
+	if permitions, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
+		return permitions, nil
 	}
-	return 0, errors.New("Token inválido")
+	return nil, errors.New("Token inválido")
 }
 
 func returnVerificationKey(token *jwt.Token) (interface{}, error) {
 	if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 		return nil, fmt.Errorf("Método de assinatura inesperado! %v", token.Header["alg"])
 	}
-	return config.SecretKey, nil
+	return []byte(config.Settings.JWT.Secret), nil
 }
@@ -1,37 +1,212 @@
 package config
 
 import (
-	"fmt"
-	"log"
-	"os"
-	"strconv"
-
-	"github.com/joho/godotenv"
+	"time"
 )
 
-var (
-	StringConectionDB = ""
-	Port              = 0
-	SecretKey         []byte
-)
+// Config holds every setting of the API. Each field can be set, in increasing
+// order of precedence, by its default, the config file, the environment
+// variable named in its env tag and the command-line flag named in its flag tag.
+type Config struct {
+	Server   Server   `yaml:"server" toml:"server"`
+	Database Database `yaml:"database" toml:"database"`
+	JWT      JWT      `yaml:"jwt" toml:"jwt"`
+	Log      Log      `yaml:"log" toml:"log"`
+	Storage  Storage  `yaml:"storage" toml:"storage"`
+	Search   Search   `yaml:"search" toml:"search"`
+	Timeline Timeline `yaml:"timeline" toml:"timeline"`
+	Policy   Policy   `yaml:"policy" toml:"policy"`
+}
+
+type Server struct {
+	Port         int           `yaml:"port" toml:"port" env:"API_PORT" flag:"port"`
+	ReadTimeout  time.Duration `yaml:"readTimeout" toml:"readTimeout" env:"API_READ_TIMEOUT" flag:"read-timeout"`
+	WriteTimeout time.Duration `yaml:"writeTimeout" toml:"writeTimeout" env:"API_WRITE_TIMEOUT" flag:"write-timeout"`
+	IdleTimeout  time.Duration `yaml:"idleTimeout" toml:"idleTimeout" env:"API_IDLE_TIMEOUT" flag:"idle-timeout"`
+	// TrustProxy takes the client IP from the X-Forwarded-For and X-Real-IP
+	// headers, which only a reverse proxy in front of the API can be trusted
+	// to set.
+	TrustProxy bool `yaml:"trustProxy" toml:"trustProxy" env:"API_TRUST_PROXY" flag:"trust-proxy"`
+}
+
+type Database struct {
+	Driver   string `yaml:"driver" toml:"driver" env:"DB_DRIVER" flag:"db-driver"`
+	Host     string `yaml:"host" toml:"host" env:"DB_HOST" flag:"db-host"`
+	Port     int    `yaml:"port" toml:"port" env:"DB_PORT" flag:"db-port"`
+	User     string `yaml:"user" toml:"user" env:"DB_USER" flag:"db-user"`
+	Password string `yaml:"password" toml:"password" env:"DB_PASSWORD" flag:"db-password"`
+	// Name is the database name, or the path of the database file for sqlite.
+	Name string `yaml:"name" toml:"name" env:"DB_NAME" flag:"db-name"`
+	// TLS is one of disable, require, verify-ca or verify-full.
+	TLS             string        `yaml:"tls" toml:"tls" env:"DB_TLS" flag:"db-tls"`
+	MaxOpenConns    int           `yaml:"maxOpenConns" toml:"maxOpenConns" env:"DB_MAX_OPEN_CONNS" flag:"db-max-open-conns"`
+	MaxIdleConns    int           `yaml:"maxIdleConns" toml:"maxIdleConns" env:"DB_MAX_IDLE_CONNS" flag:"db-max-idle-conns"`
+	ConnMaxLifetime time.Duration `yaml:"connMaxLifetime" toml:"connMaxLifetime" env:"DB_CONN_MAX_LIFETIME" flag:"db-conn-max-lifetime"`
+}
+
+type JWT struct {
+	Secret     string        `yaml:"secret" toml:"secret" env:"SECRET_KEY" flag:"secret-key"`
+	Expiration time.Duration `yaml:"expiration" toml:"expiration" env:"JWT_EXPIRATION" flag:"jwt-expiration"`
+}
+
+type Log struct {
+	// Level is one of debug, info, warn or error.
+	Level string `yaml:"level" toml:"level" env:"LOG_LEVEL" flag:"log-level"`
+}
+
+// Storage configures where uploaded files are kept.
+type Storage struct {
+	// Driver is one of local or s3.
+	Driver string `yaml:"driver" toml:"driver" env:"STORAGE_DRIVER" flag:"storage-driver"`
+	// Path is the directory of the local driver.
+	Path string `yaml:"path" toml:"path" env:"STORAGE_PATH" flag:"storage-path"`
+	// BaseURL prefixes the keys in the URLs handed to clients. The files of
+	// the local driver are served by the API under its path.
+	BaseURL string `yaml:"baseURL" toml:"baseURL" env:"STORAGE_BASE_URL" flag:"storage-base-url"`
+	// MaxUploadSize is the largest file accepted, in bytes.
+	MaxUploadSize int `yaml:"maxUploadSize" toml:"maxUploadSize" env:"STORAGE_MAX_UPLOAD_SIZE" flag:"storage-max-upload-size"`
+	S3            S3  `yaml:"s3" toml:"s3"`
+}
+
+// S3 configures the s3 driver, which works with any S3-compatible service.
+type S3 struct {
+	Endpoint  string `yaml:"endpoint" toml:"endpoint" env:"STORAGE_S3_ENDPOINT" flag:"storage-s3-endpoint"`
+	Region    string `yaml:"region" toml:"region" env:"STORAGE_S3_REGION" flag:"storage-s3-region"`
+	Bucket    string `yaml:"bucket" toml:"bucket" env:"STORAGE_S3_BUCKET" flag:"storage-s3-bucket"`
+	AccessKey string `yaml:"accessKey" toml:"accessKey" env:"STORAGE_S3_ACCESS_KEY" flag:"storage-s3-access-key"`
+	SecretKey string `yaml:"secretKey" toml:"secretKey" env:"STORAGE_S3_SECRET_KEY" flag:"storage-s3-secret-key"`
+	// PathStyle addresses the bucket as endpoint/bucket instead of
+	// bucket.endpoint, as MinIO and most local stand-ins expect.
+	PathStyle bool `yaml:"pathStyle" toml:"pathStyle" env:"STORAGE_S3_PATH_STYLE" flag:"storage-s3-path-style"`
+}
+
+// Search configures the full-text search over publications.
+type Search struct {
+	// Backend is database, which uses the full-text indexes of MySQL or
+	// Postgres, memory, an index kept inside the process, or auto, which
+	// picks memory for SQLite and database otherwise.
+	Backend string `yaml:"backend" toml:"backend" env:"SEARCH_BACKEND" flag:"search-backend"`
+}
 
-func Loading() {
-	var erro error
+// Timeline configures the materialized home timelines.
+type Timeline struct {
+	// FanOutLimit is the number of followers above which the publications
+	// of an account are merged into the timelines when they are read instead
+	// of being copied into each of them.
+	FanOutLimit int `yaml:"fanOutLimit" toml:"fanOutLimit" env:"TIMELINE_FAN_OUT_LIMIT" flag:"timeline-fan-out-limit"`
+}
+
+// Policy configures the checks publications go through before being stored.
+// A limit of 0 disables its check.
+type Policy struct {
+	// BlockedWords rejects the publications containing any of these words or
+	// phrases, FlaggedWords accepts them but reports them to the moderators.
+	// Both are matched as whole words, ignoring case.
+	BlockedWords []string `yaml:"blockedWords" toml:"blockedWords" env:"POLICY_BLOCKED_WORDS" flag:"policy-blocked-words"`
+	FlaggedWords []string `yaml:"flaggedWords" toml:"flaggedWords" env:"POLICY_FLAGGED_WORDS" flag:"policy-flagged-words"`
+	// MaxLinks is how many links a publication can hold.
+	MaxLinks int `yaml:"maxLinks" toml:"maxLinks" env:"POLICY_MAX_LINKS" flag:"policy-max-links"`
+	// DuplicateWindow is how long an author cannot publish the same content again.
+	DuplicateWindow time.Duration `yaml:"duplicateWindow" toml:"duplicateWindow" env:"POLICY_DUPLICATE_WINDOW" flag:"policy-duplicate-window"`
+	// RateLimit is how many publications an author can make per RateWindow.
+	// Half of it within a tenth of the window is reported as a burst.
+	RateLimit  int           `yaml:"rateLimit" toml:"rateLimit" env:"POLICY_RATE_LIMIT" flag:"policy-rate-limit"`
+	RateWindow time.Duration `yaml:"rateWindow" toml:"rateWindow" env:"POLICY_RATE_WINDOW" flag:"policy-rate-window"`
+}
 
-	if erro = godotenv.Load(); erro != nil {
-		log.Fatal(erro)
+// Settings is the configuration the API is running with, filled by Loading.
+var Settings Config
+
+// Defaults returns the configuration used when no source sets a value.
+func Defaults() Config {
+	return Config{
+		Server: Server{
+			Port:         5000,
+			ReadTimeout:  15 * time.Second,
+			WriteTimeout: 15 * time.Second,
+			IdleTimeout:  60 * time.Second,
+		},
+		Database: Database{
+			Driver:          "mysql",
+			Host:            "localhost",
+			TLS:             "disable",
+			MaxOpenConns:    25,
+			MaxIdleConns:    25,
+			ConnMaxLifetime: 5 * time.Minute,
+		},
+		JWT: JWT{
+			Expiration: 6 * time.Hour,
+		},
+		Log: Log{
+			Level: "info",
+		},
+		Storage: Storage{
+			Driver:        "local",
+			Path:          "uploads",
+			BaseURL:       "/media",
+			MaxUploadSize: 5 << 20,
+			S3: S3{
+				Region: "us-east-1",
+			},
+		},
+		Search: Search{
+			Backend: "auto",
+		},
+		Timeline: Timeline{
+			FanOutLimit: 10000,
+		},
+		Policy: Policy{
+			MaxLinks:        3,
+			DuplicateWindow: 24 * time.Hour,
+			RateLimit:       30,
+			RateWindow:      time.Hour,
+		},
 	}
+}
 
-	Port, erro = strconv.Atoi(os.Getenv("API_PORT"))
-	if erro != nil {
-		Port = 5000
+// Loading reads every configuration source into Settings and refuses invalid
+// configurations. args are the command-line arguments without the program name.
+func Loading(args []string) error {
+	settings, error := Load(args)
+	if error != nil {
+		return error
 	}
+	Settings = settings
+	return nil
+}
+
+// Load builds a validated Config from defaults, the optional config file
+// (-config flag or CONFIG_FILE), the environment (including a .env file) and args.
+func Load(args []string) (Config, error) {
+	settings := Defaults()
 
-	StringConectionDB = fmt.Sprintf("%s:%s@/%s?charset=utf8&parseTime=True&loc=Local",
-		os.Getenv("DB_USER"),
-		os.Getenv("DB_PASSWORD"),
-		os.Getenv("DB_NAME"),
-	)
+	flags, configFile, error := parseFlags(args)
+	if error != nil {
+		return Config{}, error
+	}
 
-	SecretKey = []byte(os.Getenv("SECRET_KEY"))
+	if error = loadDotEnv(); error != nil {
+		return Config{}, error
+	}
+
+	if configFile == "" {
+		configFile = lookupEnv("CONFIG_FILE")
+	}
+	if configFile != "" {
+		if error = loadFile(configFile, &settings); error != nil {
+			return Config{}, error
+		}
+	}
+
+	if error = apply(&settings, lookupEnv, "env"); error != nil {
+		return Config{}, error
+	}
+	if error = apply(&settings, flags, "flag"); error != nil {
+		return Config{}, error
+	}
+
+	if error = settings.Validate(); error != nil {
+		return Config{}, error
+	}
+	return settings, nil
 }
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// lookup returns the raw value a source holds for a key, or "" when unset.
+type lookup func(key string) string
+
+func lookupEnv(key string) string {
+	return os.Getenv(key)
+}
+
+// loadDotEnv exports the variables of an optional .env file. Variables already
+// present in the environment win over the file.
+func loadDotEnv() error {
+	if error := godotenv.Load(); error != nil && !errors.Is(error, fs.ErrNotExist) {
+		return error
+	}
+	return nil
+}
+
+func loadFile(path string, settings *Config) error {
+	content, error := os.ReadFile(path)
+	if error != nil {
+		return error
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		error = yaml.Unmarshal(content, settings)
+	case ".toml":
+		error = toml.Unmarshal(content, settings)
+	default:
+		return fmt.Errorf("Formato de arquivo de configuração não suportado: %s", path)
+	}
+	if error != nil {
+		return fmt.Errorf("Arquivo de configuração %s inválido: %w", path, error)
+	}
+	return nil
+}
+
+// parseFlags declares one flag per flag tag of Config, plus -config, and
+// returns a lookup over the flags explicitly set in args.
+func parseFlags(args []string) (lookup, string, error) {
+	set := flag.NewFlagSet("api", flag.ContinueOnError)
+	configFile := set.String("config", "", "path of a YAML or TOML config file")
+
+	values := map[string]*string{}
+	walk(reflect.ValueOf(Defaults()), func(field reflect.StructField, _ reflect.Value) {
+		name := field.Tag.Get("flag")
+		values[name] = set.String(name, "", "overrides "+field.Tag.Get("env"))
+	})
+
+	if error := set.Parse(args); error != nil {
+		return nil, "", error
+	}
+
+	explicit := map[string]string{}
+	set.Visit(func(f *flag.Flag) {
+		if value, ok := values[f.Name]; ok {
+			explicit[f.Name] = *value
+		}
+	})
+
+	return func(key string) string { return explicit[key] }, *configFile, nil
+}
+
+// apply sets every field whose tag key (env or flag) has a value in source.
+func apply(settings *Config, source lookup, key string) error {
+	var error error
+	walk(reflect.ValueOf(settings).Elem(), func(field reflect.StructField, value reflect.Value) {
+		name := field.Tag.Get(key)
+		raw := source(name)
+		if raw == "" || error != nil {
+			return
+		}
+		if parseError := setValue(value, raw); parseError != nil {
+			error = fmt.Errorf("Valor inválido para %s: %w", name, parseError)
+		}
+	})
+	return error
+}
+
+// walk calls visit for every leaf field of a Config section carrying an env tag.
+func walk(value reflect.Value, visit func(reflect.StructField, reflect.Value)) {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if field.Type.Kind() == reflect.Struct {
+			walk(value.Field(i), visit)
+			continue
+		}
+		if field.Tag.Get("env") != "" {
+			visit(field, value.Field(i))
+		}
+	}
+}
+
+func setValue(value reflect.Value, raw string) error {
+	if value.Type() == reflect.TypeOf(time.Duration(0)) {
+		duration, error := time.ParseDuration(raw)
+		if error != nil {
+			return error
+		}
+		value.SetInt(int64(duration))
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Int:
+		number, error := strconv.Atoi(raw)
+		if error != nil {
+			return error
+		}
+		value.SetInt(int64(number))
+	case reflect.Bool:
+		boolean, error := strconv.ParseBool(raw)
+		if error != nil {
+			return error
+		}
+		value.SetBool(boolean)
+	case reflect.Slice:
+		if value.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("tipo %s não suportado", value.Type())
+		}
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		value.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("tipo %s não suportado", value.Type())
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Validate reports every problem that would keep the API from running safely.
+func (settings Config) Validate() error {
+	var problems []string
+
+	if settings.JWT.Secret == "" {
+		problems = append(problems, "SECRET_KEY é obrigatório")
+	}
+	if settings.JWT.Expiration <= 0 {
+		problems = append(problems, "JWT_EXPIRATION deve ser positivo")
+	}
+
+	if settings.Server.Port <= 0 || settings.Server.Port > 65535 {
+		problems = append(problems, fmt.Sprintf("API_PORT inválida: %d", settings.Server.Port))
+	}
+	if settings.Server.ReadTimeout < 0 || settings.Server.WriteTimeout < 0 || settings.Server.IdleTimeout < 0 {
+		problems = append(problems, "os timeouts do servidor não podem ser negativos")
+	}
+
+	database := settings.Database
+	switch database.Driver {
+	case "mysql", "postgres":
+		if database.User == "" {
+			problems = append(problems, "DB_USER é obrigatório")
+		}
+		if database.Port < 0 || database.Port > 65535 {
+			problems = append(problems, fmt.Sprintf("DB_PORT inválida: %d", database.Port))
+		}
+	case "sqlite":
+	default:
+		problems = append(problems, fmt.Sprintf("DB_DRIVER não suportado: %q", database.Driver))
+	}
+	if database.Name == "" {
+		problems = append(problems, "DB_NAME é obrigatório")
+	}
+	switch database.TLS {
+	case "disable", "require", "verify-ca", "verify-full":
+	default:
+		problems = append(problems, fmt.Sprintf("DB_TLS inválido: %q", database.TLS))
+	}
+	if database.MaxOpenConns < 0 || database.MaxIdleConns < 0 || database.ConnMaxLifetime < 0 {
+		problems = append(problems, "as configurações do pool de conexões não podem ser negativas")
+	}
+
+	switch settings.Log.Level {
+	case "debug", "info", "warn", "error":
+	default:
+		problems = append(problems, fmt.Sprintf("LOG_LEVEL inválido: %q", settings.Log.Level))
+	}
+
+	storage := settings.Storage
+	switch storage.Driver {
+	case "local":
+		if storage.Path == "" {
+			problems = append(problems, "STORAGE_PATH é obrigatório")
+		}
+	case "s3":
+		if storage.S3.Endpoint == "" || storage.S3.Bucket == "" || storage.S3.Region == "" {
+			problems = append(problems, "STORAGE_S3_ENDPOINT, STORAGE_S3_BUCKET e STORAGE_S3_REGION são obrigatórios")
+		}
+		if storage.S3.AccessKey == "" || storage.S3.SecretKey == "" {
+			problems = append(problems, "STORAGE_S3_ACCESS_KEY e STORAGE_S3_SECRET_KEY são obrigatórios")
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("STORAGE_DRIVER não suportado: %q", storage.Driver))
+	}
+	if storage.MaxUploadSize <= 0 {
+		problems = append(problems, "STORAGE_MAX_UPLOAD_SIZE deve ser positivo")
+	}
+
+	switch settings.Search.Backend {
+	case "auto", "memory":
+	case "database":
+		if database.Driver == "sqlite" {
+			problems = append(problems, "SEARCH_BACKEND database não é suportado com SQLite, use memory")
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("SEARCH_BACKEND não suportado: %q", settings.Search.Backend))
+	}
+
+	if settings.Timeline.FanOutLimit < 0 {
+		problems = append(problems, "TIMELINE_FAN_OUT_LIMIT não pode ser negativo")
+	}
+
+	policy := settings.Policy
+	if policy.MaxLinks < 0 || policy.RateLimit < 0 || policy.DuplicateWindow < 0 || policy.RateWindow < 0 {
+		problems = append(problems, "Os limites de POLICY_* não podem ser negativos")
+	}
+	if policy.RateLimit > 0 && policy.RateWindow == 0 {
+		problems = append(problems, "POLICY_RATE_WINDOW é obrigatório quando POLICY_RATE_LIMIT é definido")
+	}
+
+	if len(problems) > 0 {
+		return errors.New("Configuração inválida: " + strings.Join(problems, "; "))
+	}
+	return nil
+}
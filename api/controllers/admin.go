@@ -0,0 +1,252 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/wesleywcr/dev-book/api/auth"
+	"github.com/wesleywcr/dev-book/api/db"
+	"github.com/wesleywcr/dev-book/api/models"
+	"github.com/wesleywcr/dev-book/api/response"
+)
+
+// AdminListUsers lists every account for the administrators.
+// @Summary List accounts
+// @Description Retrieve the users, newest first, optionally only those with a role or only the suspended or active accounts. Requires the admin role
+// @Tags Admin
+// @Produce json
+// @Param role query string false "Only users with this role" Enums(user, moderator, admin)
+// @Param suspended query bool false "Only suspended (true) or active (false) accounts"
+// @Param page query int false "Page number, starting at 1"
+// @Param limit query int false "Users per page, up to 100"
+// @Success 200 {array} models.User
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /admin/users [get]
+// @Security Bearer
+func AdminListUsers(w http.ResponseWriter, r *http.Request) {
+	page, error := pageFromQuery(r)
+	if error != nil {
+		response.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	query := r.URL.Query()
+	var suspended *bool
+	if value := query.Get("suspended"); value != "" {
+		parsed, error := strconv.ParseBool(value)
+		if error != nil {
+			response.Error(w, http.StatusBadRequest, errors.New("O parâmetro suspended deve ser true ou false"))
+			return
+		}
+		suspended = &parsed
+	}
+
+	db, error := db.ConnectDB()
+	if error != nil {
+		response.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+
+	service := newUserService(db)
+	users, error := service.ListAccounts(models.Role(query.Get("role")), suspended, page)
+	if error != nil {
+		response.Error(w, statusCode(error), error)
+		return
+	}
+	response.JSON(w, http.StatusOK, users)
+}
+
+// AdminSuspendUser suspends an account.
+// @Summary Suspend an account
+// @Description Suspend an account, which can no longer log in nor use its tokens. Administrators cannot be suspended. Requires the admin role
+// @Tags Admin
+// @Produce json
+// @Param userId path int true "User ID"
+// @Success 200 {object} models.User
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /admin/users/{userId}/suspend [post]
+// @Security Bearer
+func AdminSuspendUser(w http.ResponseWriter, r *http.Request) {
+	suspend(w, r, true)
+}
+
+// AdminUnsuspendUser lifts the suspension of an account.
+// @Summary Unsuspend an account
+// @Description Lift the suspension of an account. Requires the admin role
+// @Tags Admin
+// @Produce json
+// @Param userId path int true "User ID"
+// @Success 200 {object} models.User
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /admin/users/{userId}/suspend [delete]
+// @Security Bearer
+func AdminUnsuspendUser(w http.ResponseWriter, r *http.Request) {
+	suspend(w, r, false)
+}
+
+func suspend(w http.ResponseWriter, r *http.Request, suspended bool) {
+	actorId, error := auth.ExtractUserId(r)
+	if error != nil {
+		response.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+
+	parameters := mux.Vars(r)
+	userId, error := strconv.ParseUint(parameters["userId"], 10, 64)
+	if error != nil {
+		response.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := db.ConnectDB()
+	if error != nil {
+		response.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+
+	service := newUserService(db)
+	user, error := service.Suspend(actorId, userId, suspended)
+	if error != nil {
+		response.Error(w, statusCode(error), error)
+		return
+	}
+	action := models.AuditUserSuspended
+	if !suspended {
+		action = models.AuditUserUnsuspended
+	}
+	audit(r, db, models.AuditEvent{
+		ActorID:    actorId,
+		Action:     action,
+		TargetType: models.AuditTargetUser,
+		TargetID:   userId,
+	})
+	response.JSON(w, http.StatusOK, user)
+}
+
+// AdminAssignRole changes the role of a user.
+// @Summary Assign a role
+// @Description Make a user a regular user, a moderator or an administrator. Administrators cannot change their own role. Requires the admin role
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param userId path int true "User ID"
+// @Param role body models.RoleAssignment true "New role"
+// @Success 200 {object} models.User
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /admin/users/{userId}/role [put]
+// @Security Bearer
+func AdminAssignRole(w http.ResponseWriter, r *http.Request) {
+	actorId, error := auth.ExtractUserId(r)
+	if error != nil {
+		response.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+
+	parameters := mux.Vars(r)
+	userId, error := strconv.ParseUint(parameters["userId"], 10, 64)
+	if error != nil {
+		response.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	bodyRequest, error := io.ReadAll(r.Body)
+	if error != nil {
+		response.Error(w, http.StatusUnprocessableEntity, error)
+		return
+	}
+
+	var assignment models.RoleAssignment
+	if error = json.Unmarshal(bodyRequest, &assignment); error != nil {
+		response.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := db.ConnectDB()
+	if error != nil {
+		response.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+
+	service := newUserService(db)
+	user, error := service.AssignRole(actorId, userId, assignment.Role)
+	if error != nil {
+		response.Error(w, statusCode(error), error)
+		return
+	}
+	audit(r, db, models.AuditEvent{
+		ActorID:    actorId,
+		Action:     models.AuditRoleChanged,
+		TargetType: models.AuditTargetUser,
+		TargetID:   userId,
+		Detail:     string(user.Role),
+	})
+	response.JSON(w, http.StatusOK, user)
+}
+
+// AdminDeletePublication deletes any publication.
+// @Summary Delete any publication
+// @Description Delete a publication whoever its author, removing it from the search index. Requires the admin role
+// @Tags Admin
+// @Produce json
+// @Param publicationId path int true "Publication ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /admin/publications/{publicationId} [delete]
+// @Security Bearer
+func AdminDeletePublication(w http.ResponseWriter, r *http.Request) {
+	actorId, error := auth.ExtractUserId(r)
+	if error != nil {
+		response.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+
+	parameters := mux.Vars(r)
+	publicationId, error := strconv.ParseUint(parameters["publicationId"], 10, 64)
+	if error != nil {
+		response.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := db.ConnectDB()
+	if error != nil {
+		response.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+
+	service := newPublicationService(db)
+	if error = service.Remove(publicationId); error != nil {
+		response.Error(w, statusCode(error), error)
+		return
+	}
+	audit(r, db, models.AuditEvent{
+		ActorID:    actorId,
+		Action:     models.AuditPublicationRemoved,
+		TargetType: models.AuditTargetPublication,
+		TargetID:   publicationId,
+	})
+	response.JSON(w, http.StatusNoContent, nil)
+}
@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/wesleywcr/dev-book/api/auth"
+	"github.com/wesleywcr/dev-book/api/db"
+	"github.com/wesleywcr/dev-book/api/response"
+	"github.com/wesleywcr/dev-book/api/services"
+)
+
+// UploadAttachments attaches images to a publication.
+// @Summary Attach images to a publication
+// @Description Upload JPEG, PNG, GIF or WebP images, up to 4 per publication, to a publication owned by the authenticated user. A thumbnail is generated for each one
+// @Tags Publications
+// @Accept multipart/form-data
+// @Produce json
+// @Param publicationId path int true "Publication ID"
+// @Param images formData file true "Images to attach"
+// @Success 201 {array} models.Attachment
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 413 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /publications/{publicationId}/attachments [post]
+// @Security Bearer
+func UploadAttachments(w http.ResponseWriter, r *http.Request) {
+	userId, error := auth.ExtractUserId(r)
+	if error != nil {
+		response.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+
+	parameters := mux.Vars(r)
+	publicationId, error := strconv.ParseUint(parameters["publicationId"], 10, 64)
+	if error != nil {
+		response.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	uploads, status, error := readUploads(w, r, "images", services.MaxAttachments)
+	if error != nil {
+		response.Error(w, status, error)
+		return
+	}
+
+	db, error := db.ConnectDB()
+	if error != nil {
+		response.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	service := newPublicationService(db)
+	attachments, error := service.Attach(r.Context(), userId, publicationId, uploads)
+	if error != nil {
+		response.Error(w, statusCode(error), error)
+		return
+	}
+	response.JSON(w, http.StatusCreated, attachments)
+}
+
+// DeleteAttachment removes an image from a publication.
+// @Summary Remove an image from a publication
+// @Description Remove an image attached to a publication owned by the authenticated user
+// @Tags Publications
+// @Produce json
+// @Param publicationId path int true "Publication ID"
+// @Param attachmentId path int true "Attachment ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /publications/{publicationId}/attachments/{attachmentId} [delete]
+// @Security Bearer
+func DeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	userId, error := auth.ExtractUserId(r)
+	if error != nil {
+		response.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+
+	parameters := mux.Vars(r)
+	publicationId, error := strconv.ParseUint(parameters["publicationId"], 10, 64)
+	if error != nil {
+		response.Error(w, http.StatusBadRequest, error)
+		return
+	}
+	attachmentId, error := strconv.ParseUint(parameters["attachmentId"], 10, 64)
+	if error != nil {
+		response.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := db.ConnectDB()
+	if error != nil {
+		response.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	service := newPublicationService(db)
+	if error = service.Detach(r.Context(), userId, publicationId, attachmentId); error != nil {
+		response.Error(w, statusCode(error), error)
+		return
+	}
+	response.JSON(w, http.StatusNoContent, nil)
+}
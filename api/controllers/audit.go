@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/wesleywcr/dev-book/api/auth"
+	"github.com/wesleywcr/dev-book/api/config"
+	"github.com/wesleywcr/dev-book/api/db"
+	"github.com/wesleywcr/dev-book/api/models"
+	"github.com/wesleywcr/dev-book/api/response"
+)
+
+// ListSecurityEvents retrieves the recent activity on the account of the
+// authenticated user.
+// @Summary My security events
+// @Description Retrieve the logins, failed login attempts, password changes and actions of the staff on the account of the authenticated user, newest first
+// @Tags Users
+// @Produce json
+// @Param page query int false "Page number, starting at 1"
+// @Param limit query int false "Events per page, up to 100"
+// @Success 200 {array} models.AuditEvent
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /users/me/security-events [get]
+// @Security Bearer
+func ListSecurityEvents(w http.ResponseWriter, r *http.Request) {
+	userId, error := auth.ExtractUserId(r)
+	if error != nil {
+		response.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+
+	page, error := pageFromQuery(r)
+	if error != nil {
+		response.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := db.ConnectDB()
+	if error != nil {
+		response.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+
+	service := newAuditService(db)
+	events, error := service.SecurityEvents(userId, page)
+	if error != nil {
+		response.Error(w, statusCode(error), error)
+		return
+	}
+	response.JSON(w, http.StatusOK, events)
+}
+
+// audit records an action taken through r in the audit log, with the address
+// and user agent of the client. A failure is logged but does not fail the
+// request, which already succeeded.
+func audit(r *http.Request, db *db.DB, event models.AuditEvent) {
+	event.IP = clientIP(r)
+	event.UserAgent = r.UserAgent()
+	if error := newAuditService(db).Record(event); error != nil {
+		slog.Warn("audit event not recorded", "action", event.Action, "error", error)
+	}
+}
+
+// clientIP returns the address of the client, as told by the reverse proxy
+// when it is trusted.
+func clientIP(r *http.Request) string {
+	if config.Settings.Server.TrustProxy {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			first, _, _ := strings.Cut(forwarded, ",")
+			return strings.TrimSpace(first)
+		}
+		if real := r.Header.Get("X-Real-IP"); real != "" {
+			return strings.TrimSpace(real)
+		}
+	}
+
+	host, _, error := net.SplitHostPort(r.RemoteAddr)
+	if error != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
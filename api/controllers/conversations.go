@@ -0,0 +1,219 @@
+package controllers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/wesleywcr/dev-book/api/auth"
+	"github.com/wesleywcr/dev-book/api/db"
+	"github.com/wesleywcr/dev-book/api/models"
+	"github.com/wesleywcr/dev-book/api/response"
+)
+
+// StartConversation starts a conversation with a user.
+// @Summary Start a conversation
+// @Description Start a private conversation between the authenticated user and another user, or return the one they already have
+// @Tags Conversations
+// @Accept json
+// @Produce json
+// @Param conversation body models.NewConversation true "User to talk to"
+// @Success 200 {object} models.Conversation
+// @Success 201 {object} models.Conversation
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /conversations [post]
+// @Security Bearer
+func StartConversation(w http.ResponseWriter, r *http.Request) {
+	userId, error := auth.ExtractUserId(r)
+	if error != nil {
+		response.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	bodyRequest, error := io.ReadAll(r.Body)
+	if error != nil {
+		response.Error(w, http.StatusUnprocessableEntity, error)
+		return
+	}
+
+	var newConversation models.NewConversation
+
+	if error = json.Unmarshal(bodyRequest, &newConversation); error != nil {
+		response.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := db.ConnectDB()
+	if error != nil {
+		response.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+
+	service := newConversationService(db)
+	conversation, created, error := service.Start(userId, newConversation.UserID)
+	if error != nil {
+		response.Error(w, statusCode(error), error)
+		return
+	}
+
+	if created {
+		response.JSON(w, http.StatusCreated, conversation)
+		return
+	}
+	response.JSON(w, http.StatusOK, conversation)
+}
+
+// ListConversations retrieves the conversations of the authenticated user.
+// @Summary List conversations
+// @Description Retrieve the conversations of the authenticated user with a preview of the last message and the unread count, the most recently active first
+// @Tags Conversations
+// @Produce json
+// @Param page query int false "Page number, starting at 1"
+// @Param limit query int false "Conversations per page, up to 100"
+// @Success 200 {array} models.Conversation
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /conversations [get]
+// @Security Bearer
+func ListConversations(w http.ResponseWriter, r *http.Request) {
+	userId, error := auth.ExtractUserId(r)
+	if error != nil {
+		response.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+
+	page, error := pageFromQuery(r)
+	if error != nil {
+		response.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := db.ConnectDB()
+	if error != nil {
+		response.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+
+	service := newConversationService(db)
+	conversations, error := service.List(userId, page)
+	if error != nil {
+		response.Error(w, statusCode(error), error)
+		return
+	}
+	response.JSON(w, http.StatusOK, conversations)
+}
+
+// SendMessage sends a message to a conversation.
+// @Summary Send a message
+// @Description Send a message to a conversation the authenticated user takes part in
+// @Tags Conversations
+// @Accept json
+// @Produce json
+// @Param conversationId path int true "Conversation ID"
+// @Param message body models.Message true "Message content"
+// @Success 201 {object} models.Message
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /conversations/{conversationId}/messages [post]
+// @Security Bearer
+func SendMessage(w http.ResponseWriter, r *http.Request) {
+	userId, error := auth.ExtractUserId(r)
+	if error != nil {
+		response.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+
+	parameters := mux.Vars(r)
+	conversationId, error := strconv.ParseUint(parameters["conversationId"], 10, 64)
+	if error != nil {
+		response.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	bodyRequest, error := io.ReadAll(r.Body)
+	if error != nil {
+		response.Error(w, http.StatusUnprocessableEntity, error)
+		return
+	}
+
+	var message models.Message
+
+	if error = json.Unmarshal(bodyRequest, &message); error != nil {
+		response.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := db.ConnectDB()
+	if error != nil {
+		response.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+
+	service := newConversationService(db)
+	message, error = service.Send(userId, conversationId, message)
+	if error != nil {
+		response.Error(w, statusCode(error), error)
+		return
+	}
+	response.JSON(w, http.StatusCreated, message)
+}
+
+// ListMessages retrieves the history of a conversation.
+// @Summary List messages
+// @Description Retrieve the messages of a conversation the authenticated user takes part in, newest first, marking them as read
+// @Tags Conversations
+// @Produce json
+// @Param conversationId path int true "Conversation ID"
+// @Param page query int false "Page number, starting at 1"
+// @Param limit query int false "Messages per page, up to 100"
+// @Success 200 {array} models.Message
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /conversations/{conversationId}/messages [get]
+// @Security Bearer
+func ListMessages(w http.ResponseWriter, r *http.Request) {
+	userId, error := auth.ExtractUserId(r)
+	if error != nil {
+		response.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+
+	parameters := mux.Vars(r)
+	conversationId, error := strconv.ParseUint(parameters["conversationId"], 10, 64)
+	if error != nil {
+		response.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	page, error := pageFromQuery(r)
+	if error != nil {
+		response.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := db.ConnectDB()
+	if error != nil {
+		response.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+
+	service := newConversationService(db)
+	messages, error := service.Messages(userId, conversationId, page)
+	if error != nil {
+		response.Error(w, statusCode(error), error)
+		return
+	}
+	response.JSON(w, http.StatusOK, messages)
+}
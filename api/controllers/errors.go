@@ -0,0 +1,26 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/wesleywcr/dev-book/api/services"
+)
+
+// statusCode translates an error returned by a service into an HTTP status.
+func statusCode(error error) int {
+	switch {
+	case errors.Is(error, services.ErrInvalid):
+		return http.StatusBadRequest
+	case errors.Is(error, services.ErrUnauthorized):
+		return http.StatusUnauthorized
+	case errors.Is(error, services.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(error, services.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(error, services.ErrUnprocessable):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
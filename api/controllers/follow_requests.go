@@ -0,0 +1,132 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/wesleywcr/dev-book/api/auth"
+	"github.com/wesleywcr/dev-book/api/db"
+	"github.com/wesleywcr/dev-book/api/response"
+)
+
+// ListFollowRequests retrieves the pending follow requests of the authenticated user.
+// @Summary List follow requests
+// @Description Retrieve the users waiting for the authenticated user to approve them as followers, oldest first
+// @Tags Users
+// @Produce json
+// @Param page query int false "Page number, starting at 1"
+// @Param limit query int false "Requests per page, up to 100"
+// @Success 200 {array} models.FollowRequest
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /users/me/follow-requests [get]
+// @Security Bearer
+func ListFollowRequests(w http.ResponseWriter, r *http.Request) {
+	userId, error := auth.ExtractUserId(r)
+	if error != nil {
+		response.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+
+	page, error := pageFromQuery(r)
+	if error != nil {
+		response.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := db.ConnectDB()
+	if error != nil {
+		response.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+
+	service := newUserService(db)
+	requests, error := service.FollowRequests(userId, page)
+	if error != nil {
+		response.Error(w, statusCode(error), error)
+		return
+	}
+	response.JSON(w, http.StatusOK, requests)
+}
+
+// ApproveFollowRequest accepts a follow request.
+// @Summary Approve a follow request
+// @Description Make the requesting user a follower of the authenticated user
+// @Tags Users
+// @Produce json
+// @Param followerId path int true "ID of the user who requested to follow"
+// @Success 204 "No Content"
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /users/me/follow-requests/{followerId}/approve [post]
+// @Security Bearer
+func ApproveFollowRequest(w http.ResponseWriter, r *http.Request) {
+	userId, error := auth.ExtractUserId(r)
+	if error != nil {
+		response.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	parameters := mux.Vars(r)
+
+	followerId, error := strconv.ParseUint(parameters["followerId"], 10, 64)
+	if error != nil {
+		response.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := db.ConnectDB()
+	if error != nil {
+		response.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	service := newUserService(db)
+	if error = service.ApproveFollowRequest(userId, followerId); error != nil {
+		response.Error(w, statusCode(error), error)
+		return
+	}
+	response.JSON(w, http.StatusNoContent, nil)
+}
+
+// RejectFollowRequest declines a follow request.
+// @Summary Reject a follow request
+// @Description Discard the request of a user to follow the authenticated user
+// @Tags Users
+// @Produce json
+// @Param followerId path int true "ID of the user who requested to follow"
+// @Success 204 "No Content"
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /users/me/follow-requests/{followerId}/reject [post]
+// @Security Bearer
+func RejectFollowRequest(w http.ResponseWriter, r *http.Request) {
+	userId, error := auth.ExtractUserId(r)
+	if error != nil {
+		response.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	parameters := mux.Vars(r)
+
+	followerId, error := strconv.ParseUint(parameters["followerId"], 10, 64)
+	if error != nil {
+		response.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := db.ConnectDB()
+	if error != nil {
+		response.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	service := newUserService(db)
+	if error = service.RejectFollowRequest(userId, followerId); error != nil {
+		response.Error(w, statusCode(error), error)
+		return
+	}
+	response.JSON(w, http.StatusNoContent, nil)
+}
@@ -2,27 +2,30 @@ package controllers
 
 import (
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 
 	"github.com/wesleywcr/dev-book/api/auth"
 	"github.com/wesleywcr/dev-book/api/db"
 	"github.com/wesleywcr/dev-book/api/models"
-	"github.com/wesleywcr/dev-book/api/repositories"
 	"github.com/wesleywcr/dev-book/api/response"
-	"github.com/wesleywcr/dev-book/api/security"
+	"github.com/wesleywcr/dev-book/api/services"
 )
 
 // Login authenticates a user and returns a token.
 // @Summary User login
-// @Description Authenticate a user and return a JWT token
+// @Description Authenticate a user and return a JWT token. When the user enabled two-factor authentication, a challenge token is returned instead, to be exchanged for the JWT token at /login/two-factor
 // @Tags Authentication
 // @Accept json
 // @Produce plain
 // @Param credentials body models.User true "User credentials"
 // @Success 200 {string} string "JWT Token"
+// @Success 202 {object} models.TwoFactorChallenge
 // @Failure 422 {object} response.ErrorResponse
 // @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
 // @Router /login [post]
 func Login(w http.ResponseWriter, r *http.Request) {
@@ -43,23 +46,127 @@ func Login(w http.ResponseWriter, r *http.Request) {
 		response.Error(w, http.StatusInternalServerError, error)
 		return
 	}
-
-	repository := repositories.NewRepositoryOfUsers(db)
-	userSalvedInDB, error := repository.SearchEmail(user.Email)
+	service := newUserService(db)
+	user, error = service.Authenticate(user.Email, user.Password)
+	if error != nil {
+		if user.ID != 0 {
+			audit(r, db, models.AuditEvent{
+				Action:     models.AuditLoginFailed,
+				TargetType: models.AuditTargetUser,
+				TargetID:   user.ID,
+			})
+		}
+		response.Error(w, statusCode(error), error)
+		return
+	}
+	twoFactorService := newTwoFactorService(db)
+	twoFactor, error := twoFactorService.Enabled(user.ID)
 	if error != nil {
 		response.Error(w, http.StatusInternalServerError, error)
 		return
 	}
+	if twoFactor {
+		challengeId, error := twoFactorService.Challenge(user.ID)
+		if error != nil {
+			response.Error(w, http.StatusInternalServerError, error)
+			return
+		}
+		challenge, error := auth.CreateChallengeToken(user.ID, challengeId)
+		if error != nil {
+			response.Error(w, http.StatusInternalServerError, error)
+			return
+		}
+		response.JSON(w, http.StatusAccepted, models.TwoFactorChallenge{
+			ChallengeToken: challenge,
+			ExpiresIn:      int(auth.ChallengeLifetime.Seconds()),
+		})
+		return
+	}
+
+	logIn(w, r, db, user)
+}
+
+// LoginTwoFactor completes the login of a user with two-factor authentication.
+// @Summary Two-factor login
+// @Description Exchange the challenge token returned by /login for a JWT token with a code of the authenticator app or a recovery code, which cannot be used again. Each challenge token is accepted once, and only the one of the last login. Too many wrong codes lock the login for a while
+// @Tags Authentication
+// @Accept json
+// @Produce plain
+// @Param login body models.TwoFactorLogin true "Challenge token and code"
+// @Success 200 {string} string "JWT Token"
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /login/two-factor [post]
+func LoginTwoFactor(w http.ResponseWriter, r *http.Request) {
+	bodyRequest, error := io.ReadAll(r.Body)
+	if error != nil {
+		response.Error(w, http.StatusUnprocessableEntity, error)
+		return
+	}
 
-	if error = security.VerificatedPassoword(userSalvedInDB.Password, user.Password); error != nil {
+	var login models.TwoFactorLogin
+	if error = json.Unmarshal(bodyRequest, &login); error != nil {
+		response.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	userId, challengeId, error := auth.ValidateChallengeToken(login.ChallengeToken)
+	if error != nil {
 		response.Error(w, http.StatusUnauthorized, error)
 		return
 	}
-	token, error := auth.CreateToken(userSalvedInDB.ID)
+
+	db, error := db.ConnectDB()
+	if error != nil {
+		response.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	user, recovery, error := newTwoFactorService(db).Complete(userId, challengeId, login.Code)
+	if error != nil {
+		if errors.Is(error, services.ErrUnauthorized) {
+			audit(r, db, models.AuditEvent{
+				Action:     models.AuditLoginFailed,
+				TargetType: models.AuditTargetUser,
+				TargetID:   userId,
+				Detail:     "two_factor",
+			})
+		}
+		response.Error(w, statusCode(error), error)
+		return
+	}
+	if recovery {
+		audit(r, db, models.AuditEvent{
+			ActorID:    user.ID,
+			Action:     models.AuditRecoveryCodeUsed,
+			TargetType: models.AuditTargetUser,
+			TargetID:   user.ID,
+		})
+	}
+
+	logIn(w, r, db, user)
+}
+
+// logIn opens a session for the authenticated user and writes its token.
+func logIn(w http.ResponseWriter, r *http.Request, db *db.DB, user models.User) {
+	session, error := newSessionService(db).Start(user.ID, clientIP(r), r.UserAgent())
+	if error != nil {
+		response.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	token, error := auth.CreateToken(user.ID, session.ID)
 	if error != nil {
 		response.Error(w, http.StatusInternalServerError, error)
 		return
 	}
+	audit(r, db, models.AuditEvent{
+		ActorID:    user.ID,
+		Action:     models.AuditLogin,
+		TargetType: models.AuditTargetUser,
+		TargetID:   user.ID,
+	})
 
 	w.Write([]byte(token))
 }
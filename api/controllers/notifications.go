@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/wesleywcr/dev-book/api/auth"
+	"github.com/wesleywcr/dev-book/api/db"
+	"github.com/wesleywcr/dev-book/api/response"
+)
+
+// ListNotifications retrieves the notifications of the authenticated user.
+// @Summary List notifications
+// @Description Retrieve the follows, likes and mentions received by the authenticated user, aggregated per publication, and the unread count
+// @Tags Notifications
+// @Produce json
+// @Param page query int false "Page number, starting at 1"
+// @Param limit query int false "Notifications per page, up to 100"
+// @Success 200 {object} models.NotificationPage
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /notifications [get]
+// @Security Bearer
+func ListNotifications(w http.ResponseWriter, r *http.Request) {
+	userId, error := auth.ExtractUserId(r)
+	if error != nil {
+		response.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+
+	page, error := pageFromQuery(r)
+	if error != nil {
+		response.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := db.ConnectDB()
+	if error != nil {
+		response.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+
+	service := newNotificationService(db)
+	notifications, error := service.List(userId, page)
+	if error != nil {
+		response.Error(w, statusCode(error), error)
+		return
+	}
+	response.JSON(w, http.StatusOK, notifications)
+}
+
+// ReadNotifications marks the notifications of the authenticated user as read.
+// @Summary Mark notifications as read
+// @Description Mark every notification of the authenticated user as read
+// @Tags Notifications
+// @Produce json
+// @Success 204 "No Content"
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /notifications/read [post]
+// @Security Bearer
+func ReadNotifications(w http.ResponseWriter, r *http.Request) {
+	userId, error := auth.ExtractUserId(r)
+	if error != nil {
+		response.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+
+	db, error := db.ConnectDB()
+	if error != nil {
+		response.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+
+	service := newNotificationService(db)
+	if error = service.MarkRead(userId); error != nil {
+		response.Error(w, statusCode(error), error)
+		return
+	}
+	response.JSON(w, http.StatusNoContent, nil)
+}
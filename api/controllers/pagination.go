@@ -0,0 +1,37 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/wesleywcr/dev-book/api/models"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// pageFromQuery reads the ?page= and ?limit= query parameters of a listing.
+func pageFromQuery(r *http.Request) (models.Page, error) {
+	page := models.Page{Number: 1, Limit: defaultPageLimit}
+	query := r.URL.Query()
+
+	if value := query.Get("page"); value != "" {
+		number, error := strconv.ParseUint(value, 10, 64)
+		if error != nil || number == 0 {
+			return models.Page{}, errors.New("O parâmetro page deve ser um número maior que zero")
+		}
+		page.Number = number
+	}
+
+	if value := query.Get("limit"); value != "" {
+		limit, error := strconv.ParseUint(value, 10, 64)
+		if error != nil || limit == 0 || limit > maxPageLimit {
+			return models.Page{}, errors.New("O parâmetro limit deve estar entre 1 e 100")
+		}
+		page.Limit = limit
+	}
+	return page, nil
+}
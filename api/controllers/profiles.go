@@ -0,0 +1,154 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/wesleywcr/dev-book/api/auth"
+	"github.com/wesleywcr/dev-book/api/db"
+	"github.com/wesleywcr/dev-book/api/response"
+	"github.com/wesleywcr/dev-book/api/services"
+)
+
+// UploadAvatar replaces the avatar of a user.
+// @Summary Upload the avatar of a user
+// @Description Upload a JPEG, PNG, GIF or WebP image, cropped and scaled to 400x400, as the avatar of the authenticated user
+// @Tags Users
+// @Accept multipart/form-data
+// @Produce json
+// @Param userId path int true "User ID"
+// @Param image formData file true "Avatar image"
+// @Success 200 {object} models.User
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 413 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /users/{userId}/avatar [post]
+// @Security Bearer
+func UploadAvatar(w http.ResponseWriter, r *http.Request) {
+	uploadProfileImage(w, r, services.Avatar)
+}
+
+// DeleteAvatar removes the avatar of a user.
+// @Summary Remove the avatar of a user
+// @Description Remove the avatar of the authenticated user
+// @Tags Users
+// @Produce json
+// @Param userId path int true "User ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /users/{userId}/avatar [delete]
+// @Security Bearer
+func DeleteAvatar(w http.ResponseWriter, r *http.Request) {
+	removeProfileImage(w, r, services.Avatar)
+}
+
+// UploadBanner replaces the banner of a user.
+// @Summary Upload the banner of a user
+// @Description Upload a JPEG, PNG, GIF or WebP image, cropped and scaled to 1500x500, as the banner of the authenticated user
+// @Tags Users
+// @Accept multipart/form-data
+// @Produce json
+// @Param userId path int true "User ID"
+// @Param image formData file true "Banner image"
+// @Success 200 {object} models.User
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 413 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /users/{userId}/banner [post]
+// @Security Bearer
+func UploadBanner(w http.ResponseWriter, r *http.Request) {
+	uploadProfileImage(w, r, services.Banner)
+}
+
+// DeleteBanner removes the banner of a user.
+// @Summary Remove the banner of a user
+// @Description Remove the banner of the authenticated user
+// @Tags Users
+// @Produce json
+// @Param userId path int true "User ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /users/{userId}/banner [delete]
+// @Security Bearer
+func DeleteBanner(w http.ResponseWriter, r *http.Request) {
+	removeProfileImage(w, r, services.Banner)
+}
+
+func uploadProfileImage(w http.ResponseWriter, r *http.Request, image services.ProfileImage) {
+	actorId, error := auth.ExtractUserId(r)
+	if error != nil {
+		response.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+
+	parameters := mux.Vars(r)
+	userId, error := strconv.ParseUint(parameters["userId"], 10, 64)
+	if error != nil {
+		response.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	uploads, status, error := readUploads(w, r, "image", 1)
+	if error != nil {
+		response.Error(w, status, error)
+		return
+	}
+	if len(uploads) == 0 {
+		response.Error(w, http.StatusBadRequest, errors.New("Envie uma imagem no campo image"))
+		return
+	}
+
+	db, error := db.ConnectDB()
+	if error != nil {
+		response.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	service := newUserService(db)
+	user, error := service.SetProfileImage(r.Context(), actorId, userId, image, uploads[0])
+	if error != nil {
+		response.Error(w, statusCode(error), error)
+		return
+	}
+	response.JSON(w, http.StatusOK, user)
+}
+
+func removeProfileImage(w http.ResponseWriter, r *http.Request, image services.ProfileImage) {
+	actorId, error := auth.ExtractUserId(r)
+	if error != nil {
+		response.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+
+	parameters := mux.Vars(r)
+	userId, error := strconv.ParseUint(parameters["userId"], 10, 64)
+	if error != nil {
+		response.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := db.ConnectDB()
+	if error != nil {
+		response.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	service := newUserService(db)
+	if error = service.RemoveProfileImage(r.Context(), actorId, userId, image); error != nil {
+		response.Error(w, statusCode(error), error)
+		return
+	}
+	response.JSON(w, http.StatusNoContent, nil)
+}
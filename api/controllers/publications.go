@@ -11,13 +11,12 @@ import (
 	"github.com/wesleywcr/dev-book/api/auth"
 	"github.com/wesleywcr/dev-book/api/db"
 	"github.com/wesleywcr/dev-book/api/models"
-	"github.com/wesleywcr/dev-book/api/repositories"
 	"github.com/wesleywcr/dev-book/api/response"
 )
 
 // CreatePublication creates a new publication.
 // @Summary Create a publication
-// @Description Create a new publication for the authenticated user
+// @Description Create a new publication for the authenticated user. Setting originalId quotes that publication. Publications refused by the content policy (blocked words, too many links, repeated content, posting too often) get a 422 with the reasons
 // @Tags Publications
 // @Accept json
 // @Produce json
@@ -26,6 +25,8 @@ import (
 // @Failure 401 {object} response.ErrorResponse
 // @Failure 422 {object} response.ErrorResponse
 // @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
 // @Router /publications [post]
 // @Security Bearer
@@ -48,22 +49,15 @@ func CreatePublication(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	publication.AuthorID = userId
-	if error := publication.Prepare(); error != nil {
-		response.Error(w, http.StatusBadRequest, error)
-		return
-	}
 	db, error := db.ConnectDB()
 	if error != nil {
 		response.Error(w, http.StatusInternalServerError, error)
 		return
 	}
-	defer db.Close()
-
-	repository := repositories.NewRepositoryOfPublications(db)
-	publication.ID, error = repository.Create(publication)
+	service := newPublicationService(db)
+	publication, error = service.Create(userId, publication)
 	if error != nil {
-		response.Error(w, http.StatusInternalServerError, error)
+		response.Error(w, statusCode(error), error)
 		return
 	}
 
@@ -72,16 +66,25 @@ func CreatePublication(w http.ResponseWriter, r *http.Request) {
 
 // GetPublications retrieves a publication by ID.
 // @Summary Get a publication
-// @Description Retrieve a publication by its ID
+// @Description Retrieve a publication by its ID. Publications of private accounts are only visible to their approved followers, and none is visible when the viewer and the author blocked one another
 // @Tags Publications
 // @Produce json
 // @Param publicationId path int true "Publication ID"
 // @Success 200 {object} models.Publication
 // @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
 // @Router /publications/{publicationId} [get]
 // @Security Bearer
 func GetPublicationsById(w http.ResponseWriter, r *http.Request) {
+	viewerId, error := auth.ExtractUserId(r)
+	if error != nil {
+		response.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+
 	parameters := mux.Vars(r)
 	publicationId, error := strconv.ParseUint(parameters["publicationId"], 10, 64)
 	if error != nil {
@@ -93,12 +96,10 @@ func GetPublicationsById(w http.ResponseWriter, r *http.Request) {
 		response.Error(w, http.StatusInternalServerError, error)
 		return
 	}
-	defer db.Close()
-
-	repository := repositories.NewRepositoryOfPublications(db)
-	publication, error := repository.SearchPublicationsById(publicationId)
+	service := newPublicationService(db)
+	publication, error := service.Get(viewerId, publicationId)
 	if error != nil {
-		response.Error(w, http.StatusInternalServerError, error)
+		response.Error(w, statusCode(error), error)
 		return
 	}
 
@@ -106,11 +107,14 @@ func GetPublicationsById(w http.ResponseWriter, r *http.Request) {
 
 }
 
-// SearchPublication retrieves all publications for the authenticated user.
-// @Summary List publications
-// @Description Retrieve all publications for the authenticated user
+// GetPublications retrieves the home timeline of the authenticated user.
+// @Summary Home timeline
+// @Description Retrieve the publications of the authenticated user and of the users they follow, newest first. With mode=ranked, the publications of the last week, including those of second-degree connections, ordered by relevance
 // @Tags Publications
 // @Produce json
+// @Param mode query string false "chronological (default) or ranked"
+// @Param page query int false "Page number, starting at 1"
+// @Param limit query int false "Publications per page, up to 100"
 // @Success 200 {array} models.Publication
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
@@ -122,17 +126,29 @@ func GetPublications(w http.ResponseWriter, r *http.Request) {
 		response.Error(w, http.StatusUnauthorized, error)
 		return
 	}
+	page, error := pageFromQuery(r)
+	if error != nil {
+		response.Error(w, http.StatusBadRequest, error)
+		return
+	}
 	db, error := db.ConnectDB()
 	if error != nil {
 		response.Error(w, http.StatusInternalServerError, error)
 		return
 	}
-	defer db.Close()
-
-	repository := repositories.NewRepositoryOfPublications(db)
-	publications, error := repository.SearchPublications(userID)
+	service := newPublicationService(db)
+	var publications []models.Publication
+	switch r.URL.Query().Get("mode") {
+	case "", "chronological":
+		publications, error = service.Feed(userID, page)
+	case "ranked":
+		publications, error = service.RankedFeed(userID, page)
+	default:
+		response.Error(w, http.StatusBadRequest, errors.New("O parâmetro mode deve ser chronological ou ranked"))
+		return
+	}
 	if error != nil {
-		response.Error(w, http.StatusInternalServerError, error)
+		response.Error(w, statusCode(error), error)
 		return
 	}
 	response.JSON(w, http.StatusOK, publications)
@@ -140,7 +156,7 @@ func GetPublications(w http.ResponseWriter, r *http.Request) {
 
 // UpdatedPublication updates a publication.
 // @Summary Update a publication
-// @Description Update a publication owned by the authenticated user
+// @Description Update a publication owned by the authenticated user. Content refused by the content policy gets a 422 with the reasons
 // @Tags Publications
 // @Accept json
 // @Produce json
@@ -149,6 +165,8 @@ func GetPublications(w http.ResponseWriter, r *http.Request) {
 // @Success 204 "No Content"
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
 // @Router /publications/{publicationId} [put]
 // @Security Bearer
@@ -166,26 +184,6 @@ func UpdatedPublication(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	db, error := db.ConnectDB()
-	if error != nil {
-		response.Error(w, http.StatusInternalServerError, error)
-		return
-	}
-	defer db.Close()
-
-	repository := repositories.NewRepositoryOfPublications(db)
-
-	publicationSalvedDB, error := repository.SearchPublicationsById(publicationId)
-	if error != nil {
-		response.Error(w, http.StatusInternalServerError, error)
-		return
-	}
-
-	if publicationSalvedDB.AuthorID != userId {
-		response.Error(w, http.StatusForbidden, errors.New("Não é possível atualizar uma publicação que não seja a sua"))
-		return
-	}
-
 	bodyRequest, error := io.ReadAll(r.Body)
 	if error != nil {
 		response.Error(w, http.StatusUnprocessableEntity, error)
@@ -199,13 +197,14 @@ func UpdatedPublication(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if error = publication.Prepare(); error != nil {
-		response.Error(w, http.StatusBadRequest, error)
+	db, error := db.ConnectDB()
+	if error != nil {
+		response.Error(w, http.StatusInternalServerError, error)
 		return
 	}
-
-	if error = repository.Update(publicationId, publication); error != nil {
-		response.Error(w, http.StatusBadRequest, error)
+	service := newPublicationService(db)
+	if error = service.Update(userId, publicationId, publication); error != nil {
+		response.Error(w, statusCode(error), error)
 		return
 	}
 
@@ -214,13 +213,14 @@ func UpdatedPublication(w http.ResponseWriter, r *http.Request) {
 
 // DeletePublication deletes a publication.
 // @Summary Delete a publication
-// @Description Delete a publication owned by the authenticated user
+// @Description Delete a publication owned by the authenticated user. It can be restored for 30 days
 // @Tags Publications
 // @Produce json
 // @Param publicationId path int true "Publication ID"
 // @Success 204 "No Content"
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
 // @Router /publications/{publicationId} [delete]
 // @Security Bearer
@@ -243,22 +243,9 @@ func DeletePublication(w http.ResponseWriter, r *http.Request) {
 		response.Error(w, http.StatusInternalServerError, error)
 		return
 	}
-	defer db.Close()
-
-	repository := repositories.NewRepositoryOfPublications(db)
-
-	publicationSalvedDB, error := repository.SearchPublicationsById(publicationId)
-	if error != nil {
-		response.Error(w, http.StatusInternalServerError, error)
-		return
-	}
-
-	if publicationSalvedDB.AuthorID != userId {
-		response.Error(w, http.StatusForbidden, errors.New("Não é possível deletar uma publicação que não seja a sua"))
-		return
-	}
-	if error := repository.Delete(publicationId); error != nil {
-		response.Error(w, http.StatusInternalServerError, error)
+	service := newPublicationService(db)
+	if error := service.Delete(userId, publicationId); error != nil {
+		response.Error(w, statusCode(error), error)
 		return
 	}
 
@@ -273,10 +260,17 @@ func DeletePublication(w http.ResponseWriter, r *http.Request) {
 // @Param userId path int true "User ID"
 // @Success 200 {array} models.Publication
 // @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
 // @Router /users/{userId}/publications [get]
 // @Security Bearer
 func SearchPublicationsByUserId(w http.ResponseWriter, r *http.Request) {
+	viewerId, error := auth.ExtractUserId(r)
+	if error != nil {
+		response.Error(w, http.StatusUnauthorized, error)
+		return
+	}
 	parameters := mux.Vars(r)
 	userId, error := strconv.ParseUint(parameters["userId"], 10, 64)
 	if error != nil {
@@ -288,12 +282,10 @@ func SearchPublicationsByUserId(w http.ResponseWriter, r *http.Request) {
 		response.Error(w, http.StatusInternalServerError, error)
 		return
 	}
-	defer db.Close()
-
-	repository := repositories.NewRepositoryOfPublications(db)
-	publications, error := repository.SearchPublicationByUserId(userId)
+	service := newPublicationService(db)
+	publications, error := service.ByAuthor(viewerId, userId)
 	if error != nil {
-		response.Error(w, http.StatusInternalServerError, error)
+		response.Error(w, statusCode(error), error)
 		return
 	}
 	response.JSON(w, http.StatusOK, publications)
@@ -301,16 +293,25 @@ func SearchPublicationsByUserId(w http.ResponseWriter, r *http.Request) {
 
 // LikePublication likes a publication.
 // @Summary Like a publication
-// @Description Add a like to a publication
+// @Description Add a like to a publication and notify its author
 // @Tags Publications
 // @Produce json
 // @Param publicationId path int true "Publication ID"
 // @Success 204 "No Content"
 // @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
 // @Router /publications/{publicationId}/like [post]
 // @Security Bearer
 func LikePublication(w http.ResponseWriter, r *http.Request) {
+	userId, error := auth.ExtractUserId(r)
+	if error != nil {
+		response.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+
 	parameters := mux.Vars(r)
 	publicationId, error := strconv.ParseUint(parameters["publicationId"], 10, 64)
 	if error != nil {
@@ -322,12 +323,10 @@ func LikePublication(w http.ResponseWriter, r *http.Request) {
 		response.Error(w, http.StatusInternalServerError, error)
 		return
 	}
-	defer db.Close()
-
-	repository := repositories.NewRepositoryOfPublications(db)
+	service := newPublicationService(db)
 
-	if error := repository.Like(publicationId); error != nil {
-		response.Error(w, http.StatusInternalServerError, error)
+	if error := service.Like(userId, publicationId); error != nil {
+		response.Error(w, statusCode(error), error)
 		return
 	}
 
@@ -357,14 +356,219 @@ func DeslikePublication(w http.ResponseWriter, r *http.Request) {
 		response.Error(w, http.StatusInternalServerError, error)
 		return
 	}
-	defer db.Close()
+	service := newPublicationService(db)
 
-	repository := repositories.NewRepositoryOfPublications(db)
+	if error := service.Deslike(publicationId); error != nil {
+		response.Error(w, statusCode(error), error)
+		return
+	}
 
-	if error := repository.Deslike(publicationId); error != nil {
+	response.JSON(w, http.StatusNoContent, nil)
+}
+
+// SearchMentions retrieves the publications mentioning the authenticated user.
+// @Summary List mentions
+// @Description Retrieve the publications mentioning the authenticated user with @nickname, newest first
+// @Tags Publications
+// @Produce json
+// @Param page query int false "Page number, starting at 1"
+// @Param limit query int false "Publications per page, up to 100"
+// @Success 200 {array} models.Publication
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /users/me/mentions [get]
+// @Security Bearer
+func SearchMentions(w http.ResponseWriter, r *http.Request) {
+	userId, error := auth.ExtractUserId(r)
+	if error != nil {
+		response.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+
+	page, error := pageFromQuery(r)
+	if error != nil {
+		response.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := db.ConnectDB()
+	if error != nil {
 		response.Error(w, http.StatusInternalServerError, error)
 		return
 	}
 
+	service := newPublicationService(db)
+	publications, error := service.Mentions(userId, page)
+	if error != nil {
+		response.Error(w, statusCode(error), error)
+		return
+	}
+	response.JSON(w, http.StatusOK, publications)
+}
+
+// ListPublicationRevisions retrieves the edit history of a publication.
+// @Summary List publication revisions
+// @Description Retrieve the previous versions of a publication owned by the authenticated user, newest first
+// @Tags Publications
+// @Produce json
+// @Param publicationId path int true "Publication ID"
+// @Success 200 {array} models.PublicationRevision
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /publications/{publicationId}/revisions [get]
+// @Security Bearer
+func ListPublicationRevisions(w http.ResponseWriter, r *http.Request) {
+	userId, error := auth.ExtractUserId(r)
+	if error != nil {
+		response.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+
+	parameters := mux.Vars(r)
+	publicationId, error := strconv.ParseUint(parameters["publicationId"], 10, 64)
+	if error != nil {
+		response.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := db.ConnectDB()
+	if error != nil {
+		response.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	service := newPublicationService(db)
+	revisions, error := service.Revisions(userId, publicationId)
+	if error != nil {
+		response.Error(w, statusCode(error), error)
+		return
+	}
+	response.JSON(w, http.StatusOK, revisions)
+}
+
+// RestorePublication brings back a deleted publication.
+// @Summary Restore a publication
+// @Description Restore a publication the authenticated user deleted less than 30 days ago
+// @Tags Publications
+// @Produce json
+// @Param publicationId path int true "Publication ID"
+// @Success 200 {object} models.Publication
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /publications/{publicationId}/restore [post]
+// @Security Bearer
+func RestorePublication(w http.ResponseWriter, r *http.Request) {
+	userId, error := auth.ExtractUserId(r)
+	if error != nil {
+		response.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+
+	parameters := mux.Vars(r)
+	publicationId, error := strconv.ParseUint(parameters["publicationId"], 10, 64)
+	if error != nil {
+		response.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := db.ConnectDB()
+	if error != nil {
+		response.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	service := newPublicationService(db)
+	publication, error := service.Restore(userId, publicationId)
+	if error != nil {
+		response.Error(w, statusCode(error), error)
+		return
+	}
+	response.JSON(w, http.StatusOK, publication)
+}
+
+// RepostPublication shares a publication with the followers of the authenticated user.
+// @Summary Repost a publication
+// @Description Share a publication with the followers of the authenticated user, attributed to them
+// @Tags Publications
+// @Produce json
+// @Param publicationId path int true "Publication ID"
+// @Success 201 {object} models.Publication
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /publications/{publicationId}/repost [post]
+// @Security Bearer
+func RepostPublication(w http.ResponseWriter, r *http.Request) {
+	userId, error := auth.ExtractUserId(r)
+	if error != nil {
+		response.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+
+	parameters := mux.Vars(r)
+	publicationId, error := strconv.ParseUint(parameters["publicationId"], 10, 64)
+	if error != nil {
+		response.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := db.ConnectDB()
+	if error != nil {
+		response.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	service := newPublicationService(db)
+	publication, error := service.Repost(userId, publicationId)
+	if error != nil {
+		response.Error(w, statusCode(error), error)
+		return
+	}
+	response.JSON(w, http.StatusCreated, publication)
+}
+
+// UndoRepost removes the repost of a publication.
+// @Summary Undo a repost
+// @Description Remove the repost the authenticated user made of a publication
+// @Tags Publications
+// @Produce json
+// @Param publicationId path int true "Publication ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /publications/{publicationId}/repost [delete]
+// @Security Bearer
+func UndoRepost(w http.ResponseWriter, r *http.Request) {
+	userId, error := auth.ExtractUserId(r)
+	if error != nil {
+		response.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+
+	parameters := mux.Vars(r)
+	publicationId, error := strconv.ParseUint(parameters["publicationId"], 10, 64)
+	if error != nil {
+		response.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := db.ConnectDB()
+	if error != nil {
+		response.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	service := newPublicationService(db)
+	if error = service.Unrepost(userId, publicationId); error != nil {
+		response.Error(w, statusCode(error), error)
+		return
+	}
 	response.JSON(w, http.StatusNoContent, nil)
 }
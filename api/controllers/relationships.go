@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/wesleywcr/dev-book/api/auth"
+	"github.com/wesleywcr/dev-book/api/db"
+	"github.com/wesleywcr/dev-book/api/response"
+)
+
+// GetRelationship tells how the authenticated user relates to another user.
+// @Summary Relationship with a user
+// @Description Tell whether the authenticated user follows, is followed by, blocked, was blocked by or muted a user, and whether a follow request is pending either way
+// @Tags Users
+// @Produce json
+// @Param userId path int true "User ID"
+// @Success 200 {object} models.Relationship
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /users/{userId}/relationship [get]
+// @Security Bearer
+func GetRelationship(w http.ResponseWriter, r *http.Request) {
+	viewerId, error := auth.ExtractUserId(r)
+	if error != nil {
+		response.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+
+	parameters := mux.Vars(r)
+	userId, error := strconv.ParseUint(parameters["userId"], 10, 64)
+	if error != nil {
+		response.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := db.ConnectDB()
+	if error != nil {
+		response.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+
+	service := newUserService(db)
+	relationship, error := service.Relationship(viewerId, userId)
+	if error != nil {
+		response.Error(w, statusCode(error), error)
+		return
+	}
+	response.JSON(w, http.StatusOK, relationship)
+}
+
+// ListMutuals retrieves the connections shared with a user.
+// @Summary Mutual connections
+// @Description Retrieve the users followed by the authenticated user who follow a user, in nickname order
+// @Tags Users
+// @Produce json
+// @Param userId path int true "User ID"
+// @Param page query int false "Page number, starting at 1"
+// @Param limit query int false "Users per page, up to 100"
+// @Success 200 {array} models.User
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /users/{userId}/mutuals [get]
+// @Security Bearer
+func ListMutuals(w http.ResponseWriter, r *http.Request) {
+	viewerId, error := auth.ExtractUserId(r)
+	if error != nil {
+		response.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+
+	parameters := mux.Vars(r)
+	userId, error := strconv.ParseUint(parameters["userId"], 10, 64)
+	if error != nil {
+		response.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	page, error := pageFromQuery(r)
+	if error != nil {
+		response.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := db.ConnectDB()
+	if error != nil {
+		response.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+
+	service := newUserService(db)
+	mutuals, error := service.Mutuals(viewerId, userId, page)
+	if error != nil {
+		response.Error(w, statusCode(error), error)
+		return
+	}
+	response.JSON(w, http.StatusOK, mutuals)
+}
@@ -0,0 +1,369 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/wesleywcr/dev-book/api/auth"
+	"github.com/wesleywcr/dev-book/api/db"
+	"github.com/wesleywcr/dev-book/api/models"
+	"github.com/wesleywcr/dev-book/api/response"
+)
+
+// ReportPublication flags a publication for the moderators.
+// @Summary Report a publication
+// @Description Report an abusive publication to the moderators, or return the open report the authenticated user already made about it. Reporting a repost reports its original
+// @Tags Moderation
+// @Accept json
+// @Produce json
+// @Param publicationId path int true "Publication ID"
+// @Param report body models.NewReport true "Reason and details"
+// @Success 200 {object} models.Report
+// @Success 201 {object} models.Report
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /publications/{publicationId}/report [post]
+// @Security Bearer
+func ReportPublication(w http.ResponseWriter, r *http.Request) {
+	reporterId, error := auth.ExtractUserId(r)
+	if error != nil {
+		response.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+
+	parameters := mux.Vars(r)
+	publicationId, error := strconv.ParseUint(parameters["publicationId"], 10, 64)
+	if error != nil {
+		response.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	newReport, error := readNewReport(w, r)
+	if error != nil {
+		return
+	}
+
+	db, error := db.ConnectDB()
+	if error != nil {
+		response.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+
+	service := newModerationService(db)
+	report, created, error := service.ReportPublication(reporterId, publicationId, newReport)
+	respondReport(w, report, created, error)
+}
+
+// ReportUser flags a user for the moderators.
+// @Summary Report a user
+// @Description Report an abusive user to the moderators, or return the open report the authenticated user already made about them
+// @Tags Moderation
+// @Accept json
+// @Produce json
+// @Param userId path int true "User ID"
+// @Param report body models.NewReport true "Reason and details"
+// @Success 200 {object} models.Report
+// @Success 201 {object} models.Report
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /users/{userId}/report [post]
+// @Security Bearer
+func ReportUser(w http.ResponseWriter, r *http.Request) {
+	reporterId, error := auth.ExtractUserId(r)
+	if error != nil {
+		response.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+
+	parameters := mux.Vars(r)
+	userId, error := strconv.ParseUint(parameters["userId"], 10, 64)
+	if error != nil {
+		response.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	newReport, error := readNewReport(w, r)
+	if error != nil {
+		return
+	}
+
+	db, error := db.ConnectDB()
+	if error != nil {
+		response.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+
+	service := newModerationService(db)
+	report, created, error := service.ReportUser(reporterId, userId, newReport)
+	respondReport(w, report, created, error)
+}
+
+// readNewReport decodes the body of a report, answering the request itself
+// when it cannot.
+func readNewReport(w http.ResponseWriter, r *http.Request) (models.Report, error) {
+	bodyRequest, error := io.ReadAll(r.Body)
+	if error != nil {
+		response.Error(w, http.StatusUnprocessableEntity, error)
+		return models.Report{}, error
+	}
+
+	var newReport models.NewReport
+	if error = json.Unmarshal(bodyRequest, &newReport); error != nil {
+		response.Error(w, http.StatusBadRequest, error)
+		return models.Report{}, error
+	}
+	return models.Report{Reason: newReport.Reason, Details: newReport.Details}, nil
+}
+
+func respondReport(w http.ResponseWriter, report models.Report, created bool, error error) {
+	if error != nil {
+		response.Error(w, statusCode(error), error)
+		return
+	}
+	if created {
+		response.JSON(w, http.StatusCreated, report)
+		return
+	}
+	response.JSON(w, http.StatusOK, report)
+}
+
+// ListReports retrieves the moderation queue.
+// @Summary Moderation queue
+// @Description Retrieve the reports, oldest first, open ones by default. Requires the moderator role
+// @Tags Moderation
+// @Produce json
+// @Param status query string false "Status of the reports, open by default" Enums(open, resolved, dismissed, all)
+// @Param reason query string false "Only reports with this reason" Enums(spam, harassment, hate, violence, nudity, misinformation, other)
+// @Param type query string false "Only reports about publications or about users" Enums(publication, user)
+// @Param userId query int false "Only reports about this user or their publications"
+// @Param automatic query bool false "Only the reports filed by the content policy (true) or by users (false)"
+// @Param page query int false "Page number, starting at 1"
+// @Param limit query int false "Reports per page, up to 100"
+// @Success 200 {array} models.Report
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /moderation/reports [get]
+// @Security Bearer
+func ListReports(w http.ResponseWriter, r *http.Request) {
+	page, error := pageFromQuery(r)
+	if error != nil {
+		response.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := models.ReportFilter{
+		Status: models.ReportStatus(query.Get("status")),
+		Reason: models.ReportReason(query.Get("reason")),
+		Target: query.Get("type"),
+	}
+	switch filter.Status {
+	case "":
+		filter.Status = models.ReportOpen
+	case "all":
+		filter.Status = ""
+	}
+	if filter.UserID, error = userIdFromQuery(r); error != nil {
+		response.Error(w, http.StatusBadRequest, error)
+		return
+	}
+	if value := query.Get("automatic"); value != "" {
+		automatic, error := strconv.ParseBool(value)
+		if error != nil {
+			response.Error(w, http.StatusBadRequest, errors.New("O parâmetro automatic deve ser true ou false"))
+			return
+		}
+		filter.Automatic = &automatic
+	}
+
+	db, error := db.ConnectDB()
+	if error != nil {
+		response.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+
+	service := newModerationService(db)
+	reports, error := service.Queue(filter, page)
+	if error != nil {
+		response.Error(w, statusCode(error), error)
+		return
+	}
+	response.JSON(w, http.StatusOK, reports)
+}
+
+// ActOnReport applies the decision of a moderator about a report.
+// @Summary Act on a report
+// @Description Hide the reported publication, warn or suspend the reported user, or dismiss the report. Every open report about the same publication or user is closed too, and the action is recorded in the audit log. Requires the moderator role
+// @Tags Moderation
+// @Accept json
+// @Produce json
+// @Param reportId path int true "Report ID"
+// @Param decision body models.ModerationDecision true "Action and note"
+// @Success 201 {object} models.ModerationAction
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /moderation/reports/{reportId}/actions [post]
+// @Security Bearer
+func ActOnReport(w http.ResponseWriter, r *http.Request) {
+	moderatorId, error := auth.ExtractUserId(r)
+	if error != nil {
+		response.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+
+	parameters := mux.Vars(r)
+	reportId, error := strconv.ParseUint(parameters["reportId"], 10, 64)
+	if error != nil {
+		response.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	bodyRequest, error := io.ReadAll(r.Body)
+	if error != nil {
+		response.Error(w, http.StatusUnprocessableEntity, error)
+		return
+	}
+
+	var decision models.ModerationDecision
+	if error = json.Unmarshal(bodyRequest, &decision); error != nil {
+		response.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := db.ConnectDB()
+	if error != nil {
+		response.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+
+	service := newModerationService(db)
+	action, error := service.Act(moderatorId, reportId, models.ModerationAction{Kind: decision.Action, Note: decision.Note})
+	if error != nil {
+		response.Error(w, statusCode(error), error)
+		return
+	}
+	if action.Kind == models.ModerationSuspend {
+		audit(r, db, models.AuditEvent{
+			ActorID:    moderatorId,
+			Action:     models.AuditUserSuspended,
+			TargetType: models.AuditTargetUser,
+			TargetID:   action.UserID,
+		})
+	}
+	response.JSON(w, http.StatusCreated, action)
+}
+
+// ListModerationActions retrieves the audit log of the moderators.
+// @Summary Moderation audit log
+// @Description Retrieve the actions taken by the moderators, newest first. Requires the moderator role
+// @Tags Moderation
+// @Produce json
+// @Param userId query int false "Only actions about this user or their publications"
+// @Param page query int false "Page number, starting at 1"
+// @Param limit query int false "Actions per page, up to 100"
+// @Success 200 {array} models.ModerationAction
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /moderation/actions [get]
+// @Security Bearer
+func ListModerationActions(w http.ResponseWriter, r *http.Request) {
+	page, error := pageFromQuery(r)
+	if error != nil {
+		response.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	userId, error := userIdFromQuery(r)
+	if error != nil {
+		response.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := db.ConnectDB()
+	if error != nil {
+		response.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+
+	service := newModerationService(db)
+	actions, error := service.AuditLog(userId, page)
+	if error != nil {
+		response.Error(w, statusCode(error), error)
+		return
+	}
+	response.JSON(w, http.StatusOK, actions)
+}
+
+// ListWarnings retrieves the warnings the authenticated user received.
+// @Summary My warnings
+// @Description Retrieve the warnings the moderators sent to the authenticated user, newest first
+// @Tags Moderation
+// @Produce json
+// @Param page query int false "Page number, starting at 1"
+// @Param limit query int false "Warnings per page, up to 100"
+// @Success 200 {array} models.Warning
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /users/me/warnings [get]
+// @Security Bearer
+func ListWarnings(w http.ResponseWriter, r *http.Request) {
+	userId, error := auth.ExtractUserId(r)
+	if error != nil {
+		response.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+
+	page, error := pageFromQuery(r)
+	if error != nil {
+		response.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := db.ConnectDB()
+	if error != nil {
+		response.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+
+	service := newModerationService(db)
+	warnings, error := service.Warnings(userId, page)
+	if error != nil {
+		response.Error(w, statusCode(error), error)
+		return
+	}
+	response.JSON(w, http.StatusOK, warnings)
+}
+
+// userIdFromQuery reads the optional userId filter of a listing.
+func userIdFromQuery(r *http.Request) (uint64, error) {
+	value := r.URL.Query().Get("userId")
+	if value == "" {
+		return 0, nil
+	}
+	userId, error := strconv.ParseUint(value, 10, 64)
+	if error != nil {
+		return 0, errors.New("O parâmetro userId deve ser um número")
+	}
+	return userId, nil
+}
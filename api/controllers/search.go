@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/wesleywcr/dev-book/api/auth"
+	"github.com/wesleywcr/dev-book/api/db"
+	"github.com/wesleywcr/dev-book/api/models"
+	"github.com/wesleywcr/dev-book/api/response"
+)
+
+// dateLayout is the format of the dates accepted by the search filters.
+const dateLayout = "2006-01-02"
+
+// SearchPublications runs a full-text search over publications.
+// @Summary Search publications
+// @Description Retrieve the publications whose title or content match the terms, the most relevant first
+// @Tags Search
+// @Produce json
+// @Param q query string true "Terms to search for"
+// @Param author query string false "Nickname of the author"
+// @Param tag query string false "Hashtag, with or without #"
+// @Param from query string false "First creation date, as YYYY-MM-DD"
+// @Param until query string false "Last creation date, as YYYY-MM-DD"
+// @Param page query int false "Page number, starting at 1"
+// @Param limit query int false "Publications per page, up to 100"
+// @Success 200 {array} models.Publication
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /search/publications [get]
+// @Security Bearer
+func SearchPublications(w http.ResponseWriter, r *http.Request) {
+	userId, error := auth.ExtractUserId(r)
+	if error != nil {
+		response.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+
+	page, error := pageFromQuery(r)
+	if error != nil {
+		response.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	parameters := r.URL.Query()
+	query := models.SearchQuery{Terms: parameters.Get("q"), Tag: parameters.Get("tag"), Page: page}
+	if query.From, error = dateFromQuery(r, "from"); error != nil {
+		response.Error(w, http.StatusBadRequest, error)
+		return
+	}
+	if query.Until, error = dateFromQuery(r, "until"); error != nil {
+		response.Error(w, http.StatusBadRequest, error)
+		return
+	}
+	if !query.Until.IsZero() {
+		// Until names the last day included.
+		query.Until = query.Until.AddDate(0, 0, 1)
+	}
+
+	db, error := db.ConnectDB()
+	if error != nil {
+		response.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+
+	service := newSearchService(db)
+	publications, error := service.Publications(userId, parameters.Get("author"), query)
+	if error != nil {
+		response.Error(w, statusCode(error), error)
+		return
+	}
+	response.JSON(w, http.StatusOK, publications)
+}
+
+// dateFromQuery parses the YYYY-MM-DD date of a query parameter, in UTC. A
+// missing parameter is the zero time.
+func dateFromQuery(r *http.Request, name string) (time.Time, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	date, error := time.Parse(dateLayout, value)
+	if error != nil {
+		return time.Time{}, fmt.Errorf("O parâmetro %s deve ser uma data no formato AAAA-MM-DD", name)
+	}
+	return date, nil
+}
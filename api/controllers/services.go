@@ -0,0 +1,117 @@
+package controllers
+
+import (
+	"github.com/wesleywcr/dev-book/api/config"
+	"github.com/wesleywcr/dev-book/api/db"
+	"github.com/wesleywcr/dev-book/api/events"
+	"github.com/wesleywcr/dev-book/api/policy"
+	"github.com/wesleywcr/dev-book/api/repositories"
+	"github.com/wesleywcr/dev-book/api/search"
+	"github.com/wesleywcr/dev-book/api/services"
+	"github.com/wesleywcr/dev-book/api/storage"
+)
+
+// The constructors below wire each service to the repositories of a connection.
+
+func newUserService(db *db.DB) *services.UserService {
+	return services.NewUserService(
+		repositories.NewRepositoryOfUsers(db),
+		repositories.NewRepositoryOfNotifications(db),
+		events.Default,
+		storage.Default,
+		newTimeline(db),
+	)
+}
+
+func newPublicationService(db *db.DB) *services.PublicationService {
+	return services.NewPublicationService(
+		repositories.NewRepositoryOfPublications(db),
+		repositories.NewRepositoryOfTags(db),
+		repositories.NewRepositoryOfMentions(db),
+		repositories.NewRepositoryOfUsers(db),
+		repositories.NewRepositoryOfNotifications(db),
+		repositories.NewRepositoryOfAttachments(db),
+		events.Default,
+		storage.Default,
+		newSearchBackend(db),
+		newTimeline(db),
+		policy.Default,
+		repositories.NewRepositoryOfReports(db),
+	)
+}
+
+func newTagService(db *db.DB) *services.TagService {
+	return services.NewTagService(
+		repositories.NewRepositoryOfTags(db),
+		repositories.NewRepositoryOfPublications(db),
+		repositories.NewRepositoryOfMentions(db),
+		repositories.NewRepositoryOfAttachments(db),
+		storage.Default,
+	)
+}
+
+func newNotificationService(db *db.DB) *services.NotificationService {
+	return services.NewNotificationService(repositories.NewRepositoryOfNotifications(db))
+}
+
+func newSuggestionService(db *db.DB) *services.SuggestionService {
+	return services.NewSuggestionService(repositories.NewRepositoryOfSuggestions(db))
+}
+
+func newConversationService(db *db.DB) *services.ConversationService {
+	return services.NewConversationService(
+		repositories.NewRepositoryOfConversations(db),
+		repositories.NewRepositoryOfUsers(db),
+		events.Default,
+	)
+}
+
+func newModerationService(db *db.DB) *services.ModerationService {
+	return services.NewModerationService(
+		repositories.NewRepositoryOfReports(db),
+		repositories.NewRepositoryOfUsers(db),
+		repositories.NewRepositoryOfPublications(db),
+		newSearchBackend(db),
+		events.Default,
+	)
+}
+
+func newSearchService(db *db.DB) *services.SearchService {
+	return services.NewSearchService(
+		newSearchBackend(db),
+		repositories.NewRepositoryOfPublications(db),
+		repositories.NewRepositoryOfUsers(db),
+		repositories.NewRepositoryOfMentions(db),
+		repositories.NewRepositoryOfAttachments(db),
+		storage.Default,
+	)
+}
+
+// newSearchBackend returns the in-process index when it is enabled, and the
+// full-text search of the database otherwise.
+func newSearchBackend(db *db.DB) services.SearchBackend {
+	if search.Default != nil {
+		return search.Default
+	}
+	return repositories.NewPublicationSearch(db)
+}
+
+func newTimeline(db *db.DB) *services.Timeline {
+	return services.NewTimeline(
+		repositories.NewRepositoryOfTimelines(db),
+		config.Settings.Timeline.FanOutLimit,
+		services.DefaultRanker,
+	)
+}
+
+func newAuditService(db *db.DB) *services.AuditService {
+	return services.NewAuditService(repositories.NewRepositoryOfAuditEvents(db))
+}
+
+func newSessionService(db *db.DB) *services.SessionService {
+	return services.NewSessionService(repositories.NewRepositoryOfSessions(db), events.Default, config.Settings.JWT.Expiration)
+}
+
+func newTwoFactorService(db *db.DB) *services.TwoFactorService {
+	return services.NewTwoFactorService(repositories.NewRepositoryOfTwoFactor(db), repositories.NewRepositoryOfUsers(db))
+}
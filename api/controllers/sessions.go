@@ -0,0 +1,143 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/wesleywcr/dev-book/api/auth"
+	"github.com/wesleywcr/dev-book/api/db"
+	"github.com/wesleywcr/dev-book/api/models"
+	"github.com/wesleywcr/dev-book/api/response"
+)
+
+// ListSessions retrieves where the authenticated user is logged in.
+// @Summary My sessions
+// @Description Retrieve the open sessions of the authenticated user with the IP and user agent of their device, the most recently used first. The session of the token used is marked as current
+// @Tags Authentication
+// @Produce json
+// @Success 200 {array} models.Session
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /users/me/sessions [get]
+// @Security Bearer
+func ListSessions(w http.ResponseWriter, r *http.Request) {
+	userId, error := auth.ExtractUserId(r)
+	if error != nil {
+		response.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	sessionId, error := auth.ExtractSessionId(r)
+	if error != nil {
+		response.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+
+	db, error := db.ConnectDB()
+	if error != nil {
+		response.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+
+	service := newSessionService(db)
+	sessions, error := service.List(userId, sessionId)
+	if error != nil {
+		response.Error(w, statusCode(error), error)
+		return
+	}
+	response.JSON(w, http.StatusOK, sessions)
+}
+
+// RevokeSession logs the authenticated user out of a device.
+// @Summary Revoke a session
+// @Description End a session of the authenticated user; its tokens stop working. Revoking the current session logs out
+// @Tags Authentication
+// @Produce json
+// @Param sessionId path int true "Session ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /users/me/sessions/{sessionId} [delete]
+// @Security Bearer
+func RevokeSession(w http.ResponseWriter, r *http.Request) {
+	userId, error := auth.ExtractUserId(r)
+	if error != nil {
+		response.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+
+	parameters := mux.Vars(r)
+	sessionId, error := strconv.ParseUint(parameters["sessionId"], 10, 64)
+	if error != nil {
+		response.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := db.ConnectDB()
+	if error != nil {
+		response.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+
+	service := newSessionService(db)
+	if error = service.Revoke(userId, sessionId); error != nil {
+		response.Error(w, statusCode(error), error)
+		return
+	}
+	audit(r, db, models.AuditEvent{
+		ActorID:    userId,
+		Action:     models.AuditSessionRevoked,
+		TargetType: models.AuditTargetUser,
+		TargetID:   userId,
+		Detail:     strconv.FormatUint(sessionId, 10),
+	})
+	response.JSON(w, http.StatusNoContent, nil)
+}
+
+// RevokeOtherSessions logs the authenticated user out everywhere else.
+// @Summary Log out everywhere else
+// @Description End every session of the authenticated user but the current one
+// @Tags Authentication
+// @Produce json
+// @Success 204 "No Content"
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /users/me/sessions [delete]
+// @Security Bearer
+func RevokeOtherSessions(w http.ResponseWriter, r *http.Request) {
+	userId, error := auth.ExtractUserId(r)
+	if error != nil {
+		response.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	sessionId, error := auth.ExtractSessionId(r)
+	if error != nil {
+		response.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+
+	db, error := db.ConnectDB()
+	if error != nil {
+		response.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+
+	service := newSessionService(db)
+	revoked, error := service.RevokeOthers(userId, sessionId)
+	if error != nil {
+		response.Error(w, statusCode(error), error)
+		return
+	}
+	if revoked > 0 {
+		audit(r, db, models.AuditEvent{
+			ActorID:    userId,
+			Action:     models.AuditOtherSessionsRevoked,
+			TargetType: models.AuditTargetUser,
+			TargetID:   userId,
+			Detail:     strconv.FormatInt(revoked, 10),
+		})
+	}
+	response.JSON(w, http.StatusNoContent, nil)
+}
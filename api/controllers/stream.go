@@ -0,0 +1,122 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/wesleywcr/dev-book/api/auth"
+	"github.com/wesleywcr/dev-book/api/db"
+	"github.com/wesleywcr/dev-book/api/events"
+	"github.com/wesleywcr/dev-book/api/response"
+)
+
+// heartbeatInterval keeps idle streams from being closed by proxies.
+const heartbeatInterval = 25 * time.Second
+
+// Stream pushes the events of the authenticated user as Server-Sent Events.
+// @Summary Event stream
+// @Description Server-Sent Events stream of the authenticated user: "publication" when someone they follow publishes, "like" when their publication is liked, "follow" when they gain a follower, "message" for direct messages and "warning" for warnings of the moderators. Events caused by users they blocked or muted, or who blocked them, are skipped. The stream ends with "session_revoked" when its session is revoked
+// @Tags Stream
+// @Produce text/event-stream
+// @Success 200 {object} events.Event "One event per message, as JSON in the data field"
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /stream [get]
+// @Security Bearer
+func Stream(w http.ResponseWriter, r *http.Request) {
+	userId, error := auth.ExtractUserId(r)
+	if error != nil {
+		response.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	sessionId, error := auth.ExtractSessionId(r)
+	if error != nil {
+		response.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+
+	db, error := db.ConnectDB()
+	if error != nil {
+		response.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	users := newUserService(db)
+	sessions := newSessionService(db)
+
+	controller := http.NewResponseController(w)
+	// the stream outlives the server write timeout
+	if error = controller.SetWriteDeadline(time.Time{}); error != nil {
+		response.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+
+	stream, error := events.Default.Subscribe(r.Context(), events.UserTopic(userId))
+	if error != nil {
+		response.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	revocation, error := events.Default.Subscribe(r.Context(), events.SessionTopic(sessionId))
+	if error != nil {
+		response.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	controller.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case event, open := <-stream:
+			if !open {
+				return
+			}
+			if event.Actor != 0 {
+				silenced, error := users.Silenced(userId, event.Actor)
+				if error != nil {
+					slog.Warn("event not streamed", "type", event.Type, "user", userId, "error", error)
+				}
+				if error != nil || silenced {
+					continue
+				}
+			}
+			if error := writeEvent(w, event); error != nil {
+				return
+			}
+		case event, open := <-revocation:
+			if open {
+				writeEvent(w, event)
+				controller.Flush()
+			}
+			return
+		case <-heartbeat.C:
+			// revocations published on another instance are not seen here
+			if active, error := sessions.Active(sessionId, userId); error == nil && !active {
+				return
+			}
+			if _, error := fmt.Fprint(w, ": ping\n\n"); error != nil {
+				return
+			}
+		}
+		if error := controller.Flush(); error != nil {
+			return
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, event events.Event) error {
+	data, error := json.Marshal(event)
+	if error != nil {
+		return nil
+	}
+	_, error = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+	return error
+}
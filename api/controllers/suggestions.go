@@ -0,0 +1,50 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/wesleywcr/dev-book/api/auth"
+	"github.com/wesleywcr/dev-book/api/db"
+	"github.com/wesleywcr/dev-book/api/response"
+)
+
+// ListSuggestions recommends accounts to the authenticated user.
+// @Summary Who to follow
+// @Description Recommend accounts followed by the users the authenticated user follows, sharing followers with them or using the same hashtags, with the reason of each suggestion
+// @Tags Users
+// @Produce json
+// @Param page query int false "Page number, starting at 1"
+// @Param limit query int false "Suggestions per page, up to 100"
+// @Success 200 {array} models.Suggestion
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /users/me/suggestions [get]
+// @Security Bearer
+func ListSuggestions(w http.ResponseWriter, r *http.Request) {
+	userId, error := auth.ExtractUserId(r)
+	if error != nil {
+		response.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+
+	page, error := pageFromQuery(r)
+	if error != nil {
+		response.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := db.ConnectDB()
+	if error != nil {
+		response.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+
+	service := newSuggestionService(db)
+	suggestions, error := service.Suggest(userId, page)
+	if error != nil {
+		response.Error(w, statusCode(error), error)
+		return
+	}
+	response.JSON(w, http.StatusOK, suggestions)
+}
@@ -0,0 +1,105 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/wesleywcr/dev-book/api/auth"
+	"github.com/wesleywcr/dev-book/api/db"
+	"github.com/wesleywcr/dev-book/api/response"
+)
+
+// SearchPublicationsByTag retrieves the publications using a hashtag.
+// @Summary Get publications by hashtag
+// @Description Retrieve the publications whose content uses a hashtag, newest first, leaving out those of private accounts the viewer does not follow and of users blocked either way
+// @Tags Tags
+// @Produce json
+// @Param tag path string true "Hashtag, with or without #"
+// @Param page query int false "Page number, starting at 1"
+// @Param limit query int false "Publications per page, up to 100"
+// @Success 200 {array} models.Publication
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /tags/{tag}/publications [get]
+// @Security Bearer
+func SearchPublicationsByTag(w http.ResponseWriter, r *http.Request) {
+	viewerId, error := auth.ExtractUserId(r)
+	if error != nil {
+		response.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+
+	parameters := mux.Vars(r)
+
+	page, error := pageFromQuery(r)
+	if error != nil {
+		response.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := db.ConnectDB()
+	if error != nil {
+		response.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+
+	service := newTagService(db)
+	publications, error := service.Publications(viewerId, parameters["tag"], page)
+	if error != nil {
+		response.Error(w, statusCode(error), error)
+		return
+	}
+	response.JSON(w, http.StatusOK, publications)
+}
+
+// TrendingTags retrieves the most used hashtags.
+// @Summary Trending hashtags
+// @Description Retrieve the hashtags used by the most publications created in a recent window
+// @Tags Tags
+// @Produce json
+// @Param window query string false "Sliding window as a duration, e.g. 24h (default), up to 720h"
+// @Param limit query int false "Number of tags, up to 100 (default 10)"
+// @Success 200 {array} models.Tag
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /tags/trending [get]
+// @Security Bearer
+func TrendingTags(w http.ResponseWriter, r *http.Request) {
+	window := 24 * time.Hour
+	if value := r.URL.Query().Get("window"); value != "" {
+		duration, error := time.ParseDuration(value)
+		if error != nil {
+			response.Error(w, http.StatusBadRequest, error)
+			return
+		}
+		window = duration
+	}
+
+	var limit uint64 = 10
+	if value := r.URL.Query().Get("limit"); value != "" {
+		number, error := strconv.ParseUint(value, 10, 64)
+		if error != nil || number == 0 || number > maxPageLimit {
+			response.Error(w, http.StatusBadRequest, errors.New("O parâmetro limit deve estar entre 1 e 100"))
+			return
+		}
+		limit = number
+	}
+
+	db, error := db.ConnectDB()
+	if error != nil {
+		response.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+
+	service := newTagService(db)
+	tags, error := service.Trending(window, limit)
+	if error != nil {
+		response.Error(w, statusCode(error), error)
+		return
+	}
+	response.JSON(w, http.StatusOK, tags)
+}
@@ -0,0 +1,186 @@
+package controllers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/wesleywcr/dev-book/api/auth"
+	"github.com/wesleywcr/dev-book/api/db"
+	"github.com/wesleywcr/dev-book/api/models"
+	"github.com/wesleywcr/dev-book/api/response"
+)
+
+// GetTwoFactor tells whether the authenticated user enabled two-factor authentication.
+// @Summary Two-factor status
+// @Description Retrieve whether two-factor authentication is enabled for the authenticated user and how many recovery codes are left
+// @Tags Authentication
+// @Produce json
+// @Success 200 {object} models.TwoFactorStatus
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /users/me/two-factor [get]
+// @Security Bearer
+func GetTwoFactor(w http.ResponseWriter, r *http.Request) {
+	userId, error := auth.ExtractUserId(r)
+	if error != nil {
+		response.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+
+	db, error := db.ConnectDB()
+	if error != nil {
+		response.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+
+	service := newTwoFactorService(db)
+	status, error := service.Status(userId)
+	if error != nil {
+		response.Error(w, statusCode(error), error)
+		return
+	}
+	response.JSON(w, http.StatusOK, status)
+}
+
+// EnrollTwoFactor starts enabling two-factor authentication.
+// @Summary Enroll in two-factor authentication
+// @Description Generate a TOTP secret for the authenticated user, also as an otpauth URI to show as a QR code to an authenticator app. It takes effect once confirmed with a first code; enrolling again before that replaces it
+// @Tags Authentication
+// @Produce json
+// @Success 200 {object} models.TwoFactorEnrollment
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /users/me/two-factor [post]
+// @Security Bearer
+func EnrollTwoFactor(w http.ResponseWriter, r *http.Request) {
+	userId, error := auth.ExtractUserId(r)
+	if error != nil {
+		response.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+
+	db, error := db.ConnectDB()
+	if error != nil {
+		response.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+
+	service := newTwoFactorService(db)
+	enrollment, error := service.Enroll(userId)
+	if error != nil {
+		response.Error(w, statusCode(error), error)
+		return
+	}
+	response.JSON(w, http.StatusOK, enrollment)
+}
+
+// ConfirmTwoFactor enables two-factor authentication with a first code.
+// @Summary Confirm two-factor authentication
+// @Description Enable two-factor authentication with a code of the enrolled secret. The recovery codes returned are shown only once and each replaces a code a single time
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param code body models.TwoFactorCode true "Code of the authenticator app"
+// @Success 200 {object} models.RecoveryCodes
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /users/me/two-factor/confirm [post]
+// @Security Bearer
+func ConfirmTwoFactor(w http.ResponseWriter, r *http.Request) {
+	userId, error := auth.ExtractUserId(r)
+	if error != nil {
+		response.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+
+	bodyRequest, error := io.ReadAll(r.Body)
+	if error != nil {
+		response.Error(w, http.StatusUnprocessableEntity, error)
+		return
+	}
+
+	var code models.TwoFactorCode
+	if error = json.Unmarshal(bodyRequest, &code); error != nil {
+		response.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := db.ConnectDB()
+	if error != nil {
+		response.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+
+	service := newTwoFactorService(db)
+	codes, error := service.Confirm(userId, code.Code)
+	if error != nil {
+		response.Error(w, statusCode(error), error)
+		return
+	}
+	audit(r, db, models.AuditEvent{
+		ActorID:    userId,
+		Action:     models.AuditTwoFactorEnabled,
+		TargetType: models.AuditTargetUser,
+		TargetID:   userId,
+	})
+	response.JSON(w, http.StatusOK, models.RecoveryCodes{Codes: codes})
+}
+
+// DisableTwoFactor turns off two-factor authentication.
+// @Summary Disable two-factor authentication
+// @Description Disable two-factor authentication for the authenticated user, who confirms it with a code of the authenticator app or a recovery code
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param code body models.TwoFactorCode true "Code of the authenticator app or recovery code"
+// @Success 204 "No Content"
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /users/me/two-factor [delete]
+// @Security Bearer
+func DisableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	userId, error := auth.ExtractUserId(r)
+	if error != nil {
+		response.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+
+	bodyRequest, error := io.ReadAll(r.Body)
+	if error != nil {
+		response.Error(w, http.StatusUnprocessableEntity, error)
+		return
+	}
+
+	var code models.TwoFactorCode
+	if error = json.Unmarshal(bodyRequest, &code); error != nil {
+		response.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := db.ConnectDB()
+	if error != nil {
+		response.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+
+	service := newTwoFactorService(db)
+	if error = service.Disable(userId, code.Code); error != nil {
+		response.Error(w, statusCode(error), error)
+		return
+	}
+	audit(r, db, models.AuditEvent{
+		ActorID:    userId,
+		Action:     models.AuditTwoFactorDisabled,
+		TargetType: models.AuditTargetUser,
+		TargetID:   userId,
+	})
+	response.JSON(w, http.StatusNoContent, nil)
+}
@@ -0,0 +1,58 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/wesleywcr/dev-book/api/config"
+	"github.com/wesleywcr/dev-book/api/models"
+)
+
+// multipartMemory is how much of a multipart body is kept in memory; the
+// rest goes to temporary files.
+const multipartMemory = 8 << 20
+
+// readUploads reads up to maxFiles files sent in the multipart field, each no
+// larger than the configured STORAGE_MAX_UPLOAD_SIZE. On failure it also
+// returns the status to answer with.
+func readUploads(w http.ResponseWriter, r *http.Request, field string, maxFiles int) ([]models.Upload, int, error) {
+	maxSize := int64(config.Settings.Storage.MaxUploadSize)
+	r.Body = http.MaxBytesReader(w, r.Body, maxSize*int64(maxFiles)+1<<20)
+
+	if error := r.ParseMultipartForm(multipartMemory); error != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(error, &tooLarge) {
+			return nil, http.StatusRequestEntityTooLarge, errors.New("O envio excede o tamanho máximo permitido")
+		}
+		return nil, http.StatusBadRequest, error
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	headers := r.MultipartForm.File[field]
+	if len(headers) > maxFiles {
+		return nil, http.StatusBadRequest, fmt.Errorf("Envie no máximo %d arquivos", maxFiles)
+	}
+
+	var uploads []models.Upload
+	for _, header := range headers {
+		if header.Size > maxSize {
+			return nil, http.StatusRequestEntityTooLarge, fmt.Errorf(
+				"O arquivo %s excede o tamanho máximo de %d KB", header.Filename, maxSize>>10,
+			)
+		}
+
+		file, error := header.Open()
+		if error != nil {
+			return nil, http.StatusBadRequest, error
+		}
+		content, error := io.ReadAll(io.LimitReader(file, maxSize))
+		file.Close()
+		if error != nil {
+			return nil, http.StatusBadRequest, error
+		}
+		uploads = append(uploads, models.Upload{Filename: header.Filename, Content: content})
+	}
+	return uploads, 0, nil
+}
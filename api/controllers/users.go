@@ -2,7 +2,6 @@ package controllers
 
 import (
 	"encoding/json"
-	"errors"
 	"io"
 	"net/http"
 	"strconv"
@@ -12,9 +11,7 @@ import (
 	"github.com/wesleywcr/dev-book/api/auth"
 	"github.com/wesleywcr/dev-book/api/db"
 	"github.com/wesleywcr/dev-book/api/models"
-	"github.com/wesleywcr/dev-book/api/repositories"
 	"github.com/wesleywcr/dev-book/api/response"
-	"github.com/wesleywcr/dev-book/api/security"
 )
 
 // CreateUser creates a new user.
@@ -42,10 +39,6 @@ func CreateUser(w http.ResponseWriter, r *http.Request) {
 		response.Error(w, http.StatusBadRequest, error)
 		return
 	}
-	if error = user.Prepare("register"); error != nil {
-		response.Error(w, http.StatusBadRequest, error)
-		return
-	}
 
 	//connect DB
 	db, error := db.ConnectDB()
@@ -54,12 +47,10 @@ func CreateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	defer db.Close()
-	// insert in DB
-	repository := repositories.NewRepositoryOfUsers(db)
-	user.ID, error = repository.Create(user)
+	service := newUserService(db)
+	user, error = service.Create(user)
 	if error != nil {
-		response.Error(w, http.StatusInternalServerError, error)
+		response.Error(w, statusCode(error), error)
 		return
 	}
 	response.JSON(w, http.StatusCreated, user)
@@ -84,13 +75,11 @@ func ListUsers(w http.ResponseWriter, r *http.Request) {
 		response.Error(w, http.StatusInternalServerError, error)
 		return
 	}
-	defer db.Close()
-
-	respository := repositories.NewRepositoryOfUsers(db)
+	service := newUserService(db)
 
-	users, error := respository.Search(nameOrNickname)
+	users, error := service.Search(nameOrNickname)
 	if error != nil {
-		response.Error(w, http.StatusInternalServerError, error)
+		response.Error(w, statusCode(error), error)
 		return
 	}
 
@@ -99,7 +88,7 @@ func ListUsers(w http.ResponseWriter, r *http.Request) {
 
 // ListUser retrieves a specific user by ID.
 // @Summary Get a user by ID
-// @Description Retrieve a user's details by their ID
+// @Description Retrieve a user's profile by their ID, with their follower, following and publication counts
 // @Tags Users
 // @Produce json
 // @Param userId path int true "User ID"
@@ -122,13 +111,11 @@ func ListUser(w http.ResponseWriter, r *http.Request) {
 		response.Error(w, http.StatusInternalServerError, error)
 		return
 	}
-	defer db.Close()
-
-	repository := repositories.NewRepositoryOfUsers(db)
+	service := newUserService(db)
 
-	user, error := repository.SearchPerId(userId)
+	user, error := service.Get(userId)
 	if error != nil {
-		response.Error(w, http.StatusInternalServerError, error)
+		response.Error(w, statusCode(error), error)
 		return
 	}
 	response.JSON(w, http.StatusOK, user)
@@ -164,10 +151,6 @@ func UpdateUser(w http.ResponseWriter, r *http.Request) {
 		response.Error(w, http.StatusUnauthorized, error)
 		return
 	}
-	if userId != userIdToken {
-		response.Error(w, http.StatusForbidden, errors.New("Não é possível atualizar um usuário que não é o seu"))
-		return
-	}
 
 	bodyRequest, error := io.ReadAll(r.Body)
 	if error != nil {
@@ -181,21 +164,14 @@ func UpdateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if error := user.Prepare("update"); error != nil {
-		response.Error(w, http.StatusBadRequest, error)
-		return
-	}
-
 	db, error := db.ConnectDB()
 	if error != nil {
 		response.Error(w, http.StatusInternalServerError, error)
 		return
 	}
-	defer db.Close()
-
-	repository := repositories.NewRepositoryOfUsers(db)
-	if error = repository.Update(userId, user); error != nil {
-		response.Error(w, http.StatusInternalServerError, error)
+	service := newUserService(db)
+	if error = service.Update(userIdToken, userId, user); error != nil {
+		response.Error(w, statusCode(error), error)
 		return
 	}
 
@@ -230,33 +206,34 @@ func DeleteUser(w http.ResponseWriter, r *http.Request) {
 		response.Error(w, http.StatusUnauthorized, error)
 		return
 	}
-	if userId != userIdToken {
-		response.Error(w, http.StatusForbidden, errors.New("Não é possível deletar um usuário que não é o seu"))
-		return
-	}
 
 	db, error := db.ConnectDB()
 	if error != nil {
 		response.Error(w, http.StatusInternalServerError, error)
 		return
 	}
-	defer db.Close()
-
-	repository := repositories.NewRepositoryOfUsers(db)
-	if error = repository.Delete(userId); error != nil {
-		response.Error(w, http.StatusBadRequest, error)
+	service := newUserService(db)
+	if error = service.Delete(userIdToken, userId); error != nil {
+		response.Error(w, statusCode(error), error)
 		return
 	}
+	audit(r, db, models.AuditEvent{
+		ActorID:    userIdToken,
+		Action:     models.AuditAccountDeleted,
+		TargetType: models.AuditTargetUser,
+		TargetID:   userId,
+	})
 	response.JSON(w, http.StatusNoContent, nil)
 
 }
 
 // FollowUser allows a user to follow another user.
 // @Summary Follow a user
-// @Description Follow another user by their ID
+// @Description Follow another user by their ID. Following a private account sends a follow request instead
 // @Tags Users
 // @Produce json
 // @Param userId path int true "User ID to follow"
+// @Success 202 "Follow request sent"
 // @Success 204 "No Content"
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 401 {object} response.ErrorResponse
@@ -277,21 +254,21 @@ func FollowUser(w http.ResponseWriter, r *http.Request) {
 		response.Error(w, http.StatusBadRequest, error)
 		return
 	}
-	if followerId == userId {
-		response.Error(w, http.StatusForbidden, errors.New("Não é possível seguir você mesmo"))
-		return
-	}
 
 	db, error := db.ConnectDB()
 	if error != nil {
 		response.Error(w, http.StatusInternalServerError, error)
 		return
 	}
-	defer db.Close()
+	service := newUserService(db)
+	pending, error := service.Follow(followerId, userId)
+	if error != nil {
+		response.Error(w, statusCode(error), error)
+		return
+	}
 
-	repository := repositories.NewRepositoryOfUsers(db)
-	if error = repository.Follow(userId, followerId); error != nil {
-		response.Error(w, http.StatusInternalServerError, error)
+	if pending {
+		response.JSON(w, http.StatusAccepted, nil)
 		return
 	}
 	response.JSON(w, http.StatusNoContent, nil)
@@ -325,21 +302,15 @@ func UnFollowUser(w http.ResponseWriter, r *http.Request) {
 		response.Error(w, http.StatusBadRequest, error)
 		return
 	}
-	if followerId == userId {
-		response.Error(w, http.StatusForbidden, errors.New("Não é possível deixar de seguir você mesmo"))
-		return
-	}
 
 	db, error := db.ConnectDB()
 	if error != nil {
 		response.Error(w, http.StatusInternalServerError, error)
 		return
 	}
-	defer db.Close()
-
-	repository := repositories.NewRepositoryOfUsers(db)
-	if error = repository.UnFollow(userId, followerId); error != nil {
-		response.Error(w, http.StatusInternalServerError, error)
+	service := newUserService(db)
+	if error = service.UnFollow(followerId, userId); error != nil {
+		response.Error(w, statusCode(error), error)
 		return
 	}
 	response.JSON(w, http.StatusNoContent, nil)
@@ -354,10 +325,17 @@ func UnFollowUser(w http.ResponseWriter, r *http.Request) {
 // @Param userId path int true "User ID"
 // @Success 200 {array} models.User
 // @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
 // @Router /users/{userId}/followers [get]
 // @Security Bearer
 func SearchFollowers(w http.ResponseWriter, r *http.Request) {
+	viewerId, error := auth.ExtractUserId(r)
+	if error != nil {
+		response.Error(w, http.StatusUnauthorized, error)
+		return
+	}
 	parameters := mux.Vars(r)
 
 	userId, error := strconv.ParseUint(parameters["userId"], 10, 64)
@@ -371,12 +349,10 @@ func SearchFollowers(w http.ResponseWriter, r *http.Request) {
 		response.Error(w, http.StatusInternalServerError, error)
 		return
 	}
-	defer db.Close()
-
-	repository := repositories.NewRepositoryOfUsers(db)
-	followers, error := repository.SearchFollowers(userId)
+	service := newUserService(db)
+	followers, error := service.Followers(viewerId, userId)
 	if error != nil {
-		response.Error(w, http.StatusInternalServerError, error)
+		response.Error(w, statusCode(error), error)
 		return
 	}
 	response.JSON(w, http.StatusOK, followers)
@@ -391,10 +367,17 @@ func SearchFollowers(w http.ResponseWriter, r *http.Request) {
 // @Param userId path int true "User ID"
 // @Success 200 {array} models.User
 // @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
 // @Router /users/{userId}/following [get]
 // @Security Bearer
 func SearchFollowing(w http.ResponseWriter, r *http.Request) {
+	viewerId, error := auth.ExtractUserId(r)
+	if error != nil {
+		response.Error(w, http.StatusUnauthorized, error)
+		return
+	}
 	parameters := mux.Vars(r)
 
 	userId, error := strconv.ParseUint(parameters["userId"], 10, 64)
@@ -408,13 +391,11 @@ func SearchFollowing(w http.ResponseWriter, r *http.Request) {
 		response.Error(w, http.StatusInternalServerError, error)
 		return
 	}
-	defer db.Close()
-
-	repository := repositories.NewRepositoryOfUsers(db)
+	service := newUserService(db)
 
-	users, error := repository.SearchFollowing(userId)
+	users, error := service.Following(viewerId, userId)
 	if error != nil {
-		response.Error(w, http.StatusInternalServerError, error)
+		response.Error(w, statusCode(error), error)
 		return
 	}
 	response.JSON(w, http.StatusOK, users)
@@ -450,12 +431,12 @@ func UpdatePassword(w http.ResponseWriter, r *http.Request) {
 		response.Error(w, http.StatusBadRequest, error)
 		return
 	}
-	if userIdToken != userId {
-		response.Error(w, http.StatusForbidden, errors.New("Não é possível atualizar senha de um usuário que não seja o seu"))
-		return
-	}
 
 	bodyRequest, error := io.ReadAll(r.Body)
+	if error != nil {
+		response.Error(w, http.StatusUnprocessableEntity, error)
+		return
+	}
 
 	var password models.Password
 
@@ -469,29 +450,176 @@ func UpdatePassword(w http.ResponseWriter, r *http.Request) {
 		response.Error(w, http.StatusInternalServerError, error)
 		return
 	}
-	defer db.Close()
+	service := newUserService(db)
+	if error := service.UpdatePassword(userIdToken, userId, password); error != nil {
+		response.Error(w, statusCode(error), error)
+		return
+	}
+	audit(r, db, models.AuditEvent{
+		ActorID:    userIdToken,
+		Action:     models.AuditPasswordChange,
+		TargetType: models.AuditTargetUser,
+		TargetID:   userId,
+	})
+	response.JSON(w, http.StatusNoContent, nil)
+}
 
-	repository := repositories.NewRepositoryOfUsers(db)
+// BlockUser blocks a user.
+// @Summary Block a user
+// @Description Block a user, removing the follow relationships between both and preventing new follows, likes and messages
+// @Tags Users
+// @Produce json
+// @Param userId path int true "User ID to block"
+// @Success 204 "No Content"
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /users/{userId}/block [post]
+// @Security Bearer
+func BlockUser(w http.ResponseWriter, r *http.Request) {
+	actorId, error := auth.ExtractUserId(r)
+	if error != nil {
+		response.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	parameters := mux.Vars(r)
 
-	passwordSavedDB, error := repository.GetPassword(userId)
+	userId, error := strconv.ParseUint(parameters["userId"], 10, 64)
+	if error != nil {
+		response.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := db.ConnectDB()
 	if error != nil {
 		response.Error(w, http.StatusInternalServerError, error)
 		return
 	}
-	if error = security.VerificatedPassoword(passwordSavedDB, password.Current); error != nil {
-		response.Error(w, http.StatusInternalServerError, errors.New("A senha atual está incorreta"))
+	service := newUserService(db)
+	if error = service.Block(actorId, userId); error != nil {
+		response.Error(w, statusCode(error), error)
 		return
 	}
+	response.JSON(w, http.StatusNoContent, nil)
+}
 
-	passwordWithHash, error := security.Hash(password.New)
+// UnblockUser removes a block.
+// @Summary Unblock a user
+// @Description Remove the block the authenticated user placed on another user
+// @Tags Users
+// @Produce json
+// @Param userId path int true "User ID to unblock"
+// @Success 204 "No Content"
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /users/{userId}/block [delete]
+// @Security Bearer
+func UnblockUser(w http.ResponseWriter, r *http.Request) {
+	actorId, error := auth.ExtractUserId(r)
+	if error != nil {
+		response.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	parameters := mux.Vars(r)
+
+	userId, error := strconv.ParseUint(parameters["userId"], 10, 64)
+	if error != nil {
+		response.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := db.ConnectDB()
+	if error != nil {
+		response.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	service := newUserService(db)
+	if error = service.Unblock(actorId, userId); error != nil {
+		response.Error(w, statusCode(error), error)
+		return
+	}
+	response.JSON(w, http.StatusNoContent, nil)
+}
+
+// MuteUser mutes a user.
+// @Summary Mute a user
+// @Description Hide the publications of a user from the feed of the authenticated user
+// @Tags Users
+// @Produce json
+// @Param userId path int true "User ID to mute"
+// @Success 204 "No Content"
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /users/{userId}/mute [post]
+// @Security Bearer
+func MuteUser(w http.ResponseWriter, r *http.Request) {
+	actorId, error := auth.ExtractUserId(r)
+	if error != nil {
+		response.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	parameters := mux.Vars(r)
+
+	userId, error := strconv.ParseUint(parameters["userId"], 10, 64)
 	if error != nil {
 		response.Error(w, http.StatusBadRequest, error)
 		return
 	}
 
-	if error := repository.UpdatePassword(userId, string(passwordWithHash)); error != nil {
+	db, error := db.ConnectDB()
+	if error != nil {
 		response.Error(w, http.StatusInternalServerError, error)
 		return
 	}
+	service := newUserService(db)
+	if error = service.Mute(actorId, userId); error != nil {
+		response.Error(w, statusCode(error), error)
+		return
+	}
+	response.JSON(w, http.StatusNoContent, nil)
+}
+
+// UnmuteUser unmutes a user.
+// @Summary Unmute a user
+// @Description Show again the publications of a muted user in the feed of the authenticated user
+// @Tags Users
+// @Produce json
+// @Param userId path int true "User ID to unmute"
+// @Success 204 "No Content"
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /users/{userId}/mute [delete]
+// @Security Bearer
+func UnmuteUser(w http.ResponseWriter, r *http.Request) {
+	actorId, error := auth.ExtractUserId(r)
+	if error != nil {
+		response.Error(w, http.StatusUnauthorized, error)
+		return
+	}
+	parameters := mux.Vars(r)
+
+	userId, error := strconv.ParseUint(parameters["userId"], 10, 64)
+	if error != nil {
+		response.Error(w, http.StatusBadRequest, error)
+		return
+	}
+
+	db, error := db.ConnectDB()
+	if error != nil {
+		response.Error(w, http.StatusInternalServerError, error)
+		return
+	}
+	service := newUserService(db)
+	if error = service.Unmute(actorId, userId); error != nil {
+		response.Error(w, statusCode(error), error)
+		return
+	}
 	response.JSON(w, http.StatusNoContent, nil)
 }
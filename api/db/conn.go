@@ -2,21 +2,143 @@ package db
 
 import (
 	"database/sql"
+	"errors"
 
 	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
 	"github.com/wesleywcr/dev-book/api/config"
 )
 
-func ConnectDB() (*sql.DB, error) {
-	db, err := sql.Open("mysql", config.StringConectionDB)
-	if err != nil {
-		return nil, err
+// DB is a connection that rewrites queries for its Dialect, so repositories
+// can be written once with "?" placeholders.
+type DB struct {
+	*sql.DB
+	Dialect Dialect
+}
+
+var pool *DB
+
+// Open connects to the configured database and keeps the connection pool
+// shared by every ConnectDB call.
+func Open() error {
+	settings := config.Settings.Database
+
+	dialect, error := ParseDialect(settings.Driver)
+	if error != nil {
+		return error
+	}
+
+	db, error := sql.Open(dialect.driverName(), dataSourceName(dialect, settings))
+	if error != nil {
+		return error
 	}
+	db.SetMaxOpenConns(settings.MaxOpenConns)
+	db.SetMaxIdleConns(settings.MaxIdleConns)
+	db.SetConnMaxLifetime(settings.ConnMaxLifetime)
 
-	if err = db.Ping(); err != nil {
+	if error = db.Ping(); error != nil {
 		db.Close()
-		return nil, err
+		return error
 	}
 
-	return db, nil
+	pool = &DB{db, dialect}
+	return nil
+}
+
+// ConnectDB returns the connection pool opened by Open.
+func ConnectDB() (*DB, error) {
+	if pool == nil {
+		return nil, errors.New("Banco de dados não conectado")
+	}
+	return pool, nil
+}
+
+func (db *DB) Prepare(query string) (*sql.Stmt, error) {
+	return db.DB.Prepare(db.Dialect.Rebind(query))
+}
+
+func (db *DB) Exec(query string, args ...any) (sql.Result, error) {
+	return db.DB.Exec(db.Dialect.Rebind(query), args...)
+}
+
+func (db *DB) Query(query string, args ...any) (*sql.Rows, error) {
+	return db.DB.Query(db.Dialect.Rebind(query), args...)
+}
+
+func (db *DB) QueryRow(query string, args ...any) *sql.Row {
+	return db.DB.QueryRow(db.Dialect.Rebind(query), args...)
+}
+
+// Insert runs an insert statement and returns the generated id.
+func (db *DB) Insert(query string, args ...any) (uint64, error) {
+	return insert(db.DB, db.Dialect, query, args...)
+}
+
+// Transaction runs fn in a transaction, committed when fn succeeds and rolled
+// back when it fails.
+func (db *DB) Transaction(fn func(tx *Tx) error) error {
+	sqlTx, error := db.DB.Begin()
+	if error != nil {
+		return error
+	}
+
+	if error = fn(&Tx{sqlTx, db.Dialect}); error != nil {
+		sqlTx.Rollback()
+		return error
+	}
+	return sqlTx.Commit()
+}
+
+// Tx is a transaction that rewrites queries for its Dialect, as DB does.
+type Tx struct {
+	*sql.Tx
+	Dialect Dialect
+}
+
+func (tx *Tx) Exec(query string, args ...any) (sql.Result, error) {
+	return tx.Tx.Exec(tx.Dialect.Rebind(query), args...)
+}
+
+func (tx *Tx) Query(query string, args ...any) (*sql.Rows, error) {
+	return tx.Tx.Query(tx.Dialect.Rebind(query), args...)
+}
+
+func (tx *Tx) QueryRow(query string, args ...any) *sql.Row {
+	return tx.Tx.QueryRow(tx.Dialect.Rebind(query), args...)
+}
+
+// Insert runs an insert statement in the transaction and returns the
+// generated id.
+func (tx *Tx) Insert(query string, args ...any) (uint64, error) {
+	return insert(tx.Tx, tx.Dialect, query, args...)
+}
+
+// executor is what *sql.DB and *sql.Tx share.
+type executor interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// insert runs an insert statement and returns the generated id. Postgres has
+// no LastInsertId, so the id is read back with "returning id" instead.
+func insert(executor executor, dialect Dialect, query string, args ...any) (uint64, error) {
+	if dialect == Postgres {
+		var lastInsertId uint64
+		if error := executor.QueryRow(dialect.Rebind(query+" returning id"), args...).Scan(&lastInsertId); error != nil {
+			return 0, error
+		}
+		return lastInsertId, nil
+	}
+
+	result, error := executor.Exec(dialect.Rebind(query), args...)
+	if error != nil {
+		return 0, error
+	}
+
+	lastInsertId, error := result.LastInsertId()
+	if error != nil {
+		return 0, error
+	}
+	return uint64(lastInsertId), nil
 }
@@ -0,0 +1,83 @@
+package db
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Dialect identifies the SQL flavour spoken by the configured storage driver.
+type Dialect string
+
+const (
+	MySQL    Dialect = "mysql"
+	Postgres Dialect = "postgres"
+	SQLite   Dialect = "sqlite"
+)
+
+func ParseDialect(driver string) (Dialect, error) {
+	switch dialect := Dialect(strings.ToLower(driver)); dialect {
+	case MySQL, Postgres, SQLite:
+		return dialect, nil
+	case "":
+		return MySQL, nil
+	default:
+		return "", fmt.Errorf("Driver de banco de dados não suportado: %s", driver)
+	}
+}
+
+// driverName is the name the dialect's driver registers with database/sql.
+func (dialect Dialect) driverName() string {
+	if dialect == SQLite {
+		return "sqlite3"
+	}
+	return string(dialect)
+}
+
+// TransactionalDDL reports whether schema changes can be rolled back with a
+// transaction. MySQL commits each of them implicitly.
+func (dialect Dialect) TransactionalDDL() bool {
+	return dialect != MySQL
+}
+
+// Rebind rewrites the "?" placeholders used across the repositories into the
+// placeholder style of the dialect.
+func (dialect Dialect) Rebind(query string) string {
+	if dialect != Postgres {
+		return query
+	}
+
+	var builder strings.Builder
+	var quote rune
+	position := 0
+
+	for _, char := range query {
+		switch {
+		case quote != 0:
+			if char == quote {
+				quote = 0
+			}
+		case char == '\'' || char == '"' || char == '`':
+			quote = char
+		case char == '?':
+			position++
+			builder.WriteString("$" + strconv.Itoa(position))
+			continue
+		}
+		builder.WriteRune(char)
+	}
+	return builder.String()
+}
+
+// InsertIgnore builds an insert that silently skips rows violating a unique
+// key, e.g. InsertIgnore("followers (user_id, follower_id) values (?, ?)").
+func (dialect Dialect) InsertIgnore(into string) string {
+	switch dialect {
+	case Postgres:
+		return "insert into " + into + " on conflict do nothing"
+	case SQLite:
+		return "insert or ignore into " + into
+	default:
+		return "insert ignore into " + into
+	}
+}
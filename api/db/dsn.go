@@ -0,0 +1,59 @@
+package db
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/wesleywcr/dev-book/api/config"
+)
+
+// dataSourceName builds the driver specific connection string for settings.
+func dataSourceName(dialect Dialect, settings config.Database) string {
+	switch dialect {
+	case Postgres:
+		port := settings.Port
+		if port == 0 {
+			port = 5432
+		}
+		dsn := url.URL{
+			Scheme:   "postgres",
+			User:     url.UserPassword(settings.User, settings.Password),
+			Host:     net.JoinHostPort(settings.Host, strconv.Itoa(port)),
+			Path:     "/" + settings.Name,
+			RawQuery: url.Values{"sslmode": {settings.TLS}}.Encode(),
+		}
+		return dsn.String()
+
+	case SQLite:
+		return fmt.Sprintf("file:%s?_foreign_keys=on&_busy_timeout=5000", settings.Name)
+
+	default:
+		port := settings.Port
+		if port == 0 {
+			port = 3306
+		}
+		dsn := mysql.NewConfig()
+		dsn.User = settings.User
+		dsn.Passwd = settings.Password
+		dsn.Net = "tcp"
+		dsn.Addr = net.JoinHostPort(settings.Host, strconv.Itoa(port))
+		dsn.DBName = settings.Name
+		dsn.ParseTime = true
+		dsn.Loc = time.Local
+		dsn.Params = map[string]string{"charset": "utf8"}
+
+		switch settings.TLS {
+		case "disable":
+			dsn.TLSConfig = "false"
+		case "require":
+			dsn.TLSConfig = "skip-verify"
+		default:
+			dsn.TLSConfig = "true"
+		}
+		return dsn.FormatDSN()
+	}
+}
@@ -0,0 +1,101 @@
+package db
+
+import (
+	"embed"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+//go:embed migrations
+var migrations embed.FS
+
+// Migrate applies, in file name order, the migrations of the configured
+// dialect that were not applied yet.
+func Migrate() error {
+	db, error := ConnectDB()
+	if error != nil {
+		return error
+	}
+
+	if _, error = db.Exec(`create table if not exists schema_migrations(
+		version varchar(100) primary key,
+		applied_at timestamp default current_timestamp
+	)`); error != nil {
+		return error
+	}
+
+	applied, error := appliedMigrations(db)
+	if error != nil {
+		return error
+	}
+
+	dir := path.Join("migrations", string(db.Dialect))
+	files, error := fs.Glob(migrations, path.Join(dir, "*.sql"))
+	if error != nil {
+		return error
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		version := strings.TrimSuffix(path.Base(file), ".sql")
+		if applied[version] {
+			continue
+		}
+
+		content, error := migrations.ReadFile(file)
+		if error != nil {
+			return error
+		}
+		if error = apply(db, version, string(content)); error != nil {
+			return error
+		}
+	}
+	return nil
+}
+
+func appliedMigrations(db *DB) (map[string]bool, error) {
+	rows, error := db.Query("select version from schema_migrations")
+	if error != nil {
+		return nil, error
+	}
+	defer rows.Close()
+
+	applied := map[string]bool{}
+	for rows.Next() {
+		var version string
+		if error = rows.Scan(&version); error != nil {
+			return nil, error
+		}
+		applied[version] = true
+	}
+	return applied, rows.Err()
+}
+
+// apply runs each statement of a migration file and records it. Statements
+// are split on ";" at the end of a line so no driver needs multi-statement
+// support. Where the dialect allows schema changes in a transaction, a
+// failing migration leaves nothing behind and is retried on the next start.
+func apply(db *DB, version, content string) error {
+	if !db.Dialect.TransactionalDDL() {
+		return run(db, version, content)
+	}
+	return db.Transaction(func(tx *Tx) error {
+		return run(tx, version, content)
+	})
+}
+
+func run(executor executor, version, content string) error {
+	for _, statement := range strings.Split(content, ";\n") {
+		if strings.TrimSpace(statement) == "" {
+			continue
+		}
+		if _, error := executor.Exec(statement); error != nil {
+			return error
+		}
+	}
+
+	_, error := executor.Exec("insert into schema_migrations (version) values (?)", version)
+	return error
+}
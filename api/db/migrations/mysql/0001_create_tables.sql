@@ -1,12 +1,4 @@
-CREATE DATABASE IF NOT EXISTS db;
-USE db;
-
-
-DROP TABLE IF EXISTS publications;
-DROP TABLE IF EXISTS followers;
-DROP TABLE IF EXISTS users;
-
-CREATE TABLE users(
+CREATE TABLE IF NOT EXISTS users(
     id int auto_increment primary key,
     name varchar(50) not null,
     nickname varchar(50) not null unique,
@@ -15,7 +7,7 @@ CREATE TABLE users(
     created_at timestamp default current_timestamp()
 ) ENGINE=INNODB;
 
-CREATE TABLE followers(
+CREATE TABLE IF NOT EXISTS followers(
     user_id int not null,
     FOREIGN KEY (user_id)
     REFERENCES users(id)
@@ -29,7 +21,7 @@ CREATE TABLE followers(
     primary key(user_id, follower_id)
 ) ENGINE=INNODB;
 
-CREATE TABLE publications(
+CREATE TABLE IF NOT EXISTS publications(
     id int auto_increment primary key,
     title varchar(50) not null,
     content varchar(300) not null,
@@ -41,4 +33,4 @@ CREATE TABLE publications(
 
     likes int default 0,
     created_at timestamp default current_timestamp
-) ENGINE=INNODB;
\ No newline at end of file
+) ENGINE=INNODB;
@@ -0,0 +1,18 @@
+CREATE TABLE tags(
+    id int auto_increment primary key,
+    name varchar(50) not null unique
+) ENGINE=INNODB;
+
+CREATE TABLE publication_tags(
+    publication_id int not null,
+    FOREIGN KEY (publication_id)
+    REFERENCES publications(id)
+    ON DELETE CASCADE,
+
+    tag_id int not null,
+    FOREIGN KEY (tag_id)
+    REFERENCES tags(id)
+    ON DELETE CASCADE,
+
+    primary key(publication_id, tag_id)
+) ENGINE=INNODB;
@@ -0,0 +1,18 @@
+CREATE TABLE publication_mentions(
+    publication_id int not null,
+    FOREIGN KEY (publication_id)
+    REFERENCES publications(id)
+    ON DELETE CASCADE,
+
+    user_id int not null,
+    FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    start_offset int not null,
+    end_offset int not null,
+
+    primary key(publication_id, start_offset)
+) ENGINE=INNODB;
+
+CREATE INDEX publication_mentions_user_id ON publication_mentions(user_id);
@@ -0,0 +1,26 @@
+CREATE TABLE notifications(
+    id int auto_increment primary key,
+
+    user_id int not null,
+    FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    actor_id int not null,
+    FOREIGN KEY (actor_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    kind varchar(20) not null,
+
+    publication_id int null,
+    FOREIGN KEY (publication_id)
+    REFERENCES publications(id)
+    ON DELETE CASCADE,
+
+    dedup_key varchar(100) not null unique,
+    read_at timestamp null default null,
+    created_at timestamp default current_timestamp
+) ENGINE=INNODB;
+
+CREATE INDEX notifications_user_id ON notifications(user_id, read_at);
@@ -0,0 +1,42 @@
+CREATE TABLE conversations(
+    id int auto_increment primary key,
+    last_message_id int null,
+    created_at timestamp default current_timestamp
+) ENGINE=INNODB;
+
+CREATE TABLE conversation_participants(
+    conversation_id int not null,
+    FOREIGN KEY (conversation_id)
+    REFERENCES conversations(id)
+    ON DELETE CASCADE,
+
+    user_id int not null,
+    FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    last_read_message_id int not null default 0,
+
+    primary key(conversation_id, user_id)
+) ENGINE=INNODB;
+
+CREATE INDEX conversation_participants_user_id ON conversation_participants(user_id);
+
+CREATE TABLE messages(
+    id int auto_increment primary key,
+
+    conversation_id int not null,
+    FOREIGN KEY (conversation_id)
+    REFERENCES conversations(id)
+    ON DELETE CASCADE,
+
+    sender_id int not null,
+    FOREIGN KEY (sender_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    content varchar(1000) not null,
+    created_at timestamp default current_timestamp
+) ENGINE=INNODB;
+
+CREATE INDEX messages_conversation_id ON messages(conversation_id, id);
@@ -0,0 +1,31 @@
+CREATE TABLE user_blocks(
+    blocker_id int not null,
+    FOREIGN KEY (blocker_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    blocked_id int not null,
+    FOREIGN KEY (blocked_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    created_at timestamp default current_timestamp,
+
+    primary key(blocker_id, blocked_id)
+) ENGINE=INNODB;
+
+CREATE TABLE user_mutes(
+    muter_id int not null,
+    FOREIGN KEY (muter_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    muted_id int not null,
+    FOREIGN KEY (muted_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    created_at timestamp default current_timestamp,
+
+    primary key(muter_id, muted_id)
+) ENGINE=INNODB;
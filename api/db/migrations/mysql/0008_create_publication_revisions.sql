@@ -0,0 +1,18 @@
+ALTER TABLE publications ADD COLUMN updated_at timestamp null default null;
+
+ALTER TABLE publications ADD COLUMN deleted_at timestamp null default null;
+
+CREATE TABLE publication_revisions(
+    id int auto_increment primary key,
+
+    publication_id int not null,
+    FOREIGN KEY (publication_id)
+    REFERENCES publications(id)
+    ON DELETE CASCADE,
+
+    title varchar(50) not null,
+    content varchar(300) not null,
+    created_at timestamp default current_timestamp
+) ENGINE=INNODB;
+
+CREATE INDEX publication_revisions_publication_id ON publication_revisions(publication_id);
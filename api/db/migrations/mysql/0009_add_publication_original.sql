@@ -0,0 +1,5 @@
+ALTER TABLE publications ADD COLUMN original_id int null;
+
+ALTER TABLE publications ADD FOREIGN KEY (original_id) REFERENCES publications(id) ON DELETE SET NULL;
+
+CREATE INDEX publications_original_id ON publications(original_id, author_id);
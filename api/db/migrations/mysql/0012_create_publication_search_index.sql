@@ -0,0 +1 @@
+ALTER TABLE publications ADD FULLTEXT INDEX publications_search (title, content);
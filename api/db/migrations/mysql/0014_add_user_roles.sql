@@ -0,0 +1,3 @@
+ALTER TABLE users ADD COLUMN role varchar(20) not null default 'user';
+
+ALTER TABLE users ADD COLUMN suspended_at timestamp null default null;
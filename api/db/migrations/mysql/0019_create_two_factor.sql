@@ -0,0 +1,27 @@
+CREATE TABLE two_factor(
+    user_id int not null primary key,
+    FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    secret varchar(64) not null,
+    enabled boolean not null default false,
+    last_counter bigint not null default 0,
+    failures int not null default 0,
+    failed_at timestamp null default null,
+    created_at timestamp default current_timestamp
+) ENGINE=INNODB;
+
+CREATE TABLE recovery_codes(
+    id int auto_increment primary key,
+
+    user_id int not null,
+    FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    code_hash varchar(64) not null,
+    used_at timestamp null default null
+) ENGINE=INNODB;
+
+CREATE INDEX recovery_codes_user_id ON recovery_codes(user_id, code_hash);
@@ -0,0 +1,19 @@
+ALTER TABLE conversations ADD COLUMN user_low int null;
+
+ALTER TABLE conversations ADD COLUMN user_high int null;
+
+UPDATE conversations c
+INNER JOIN (
+    select conversation_id, min(user_id) as low, max(user_id) as high
+    from conversation_participants group by conversation_id
+) p on p.conversation_id = c.id
+SET c.user_low = p.low, c.user_high = p.high;
+
+UPDATE conversations c
+INNER JOIN (
+    select user_low, user_high, min(id) as first_id
+    from conversations where user_low is not null group by user_low, user_high
+) f on f.user_low = c.user_low and f.user_high = c.user_high and f.first_id <> c.id
+SET c.user_low = null, c.user_high = null;
+
+CREATE UNIQUE INDEX conversations_pair ON conversations(user_low, user_high);
@@ -0,0 +1,16 @@
+CREATE TABLE tags(
+    id serial primary key,
+    name varchar(50) not null unique
+);
+
+CREATE TABLE publication_tags(
+    publication_id int not null
+    REFERENCES publications(id)
+    ON DELETE CASCADE,
+
+    tag_id int not null
+    REFERENCES tags(id)
+    ON DELETE CASCADE,
+
+    primary key(publication_id, tag_id)
+);
@@ -0,0 +1,16 @@
+CREATE TABLE publication_mentions(
+    publication_id int not null
+    REFERENCES publications(id)
+    ON DELETE CASCADE,
+
+    user_id int not null
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    start_offset int not null,
+    end_offset int not null,
+
+    primary key(publication_id, start_offset)
+);
+
+CREATE INDEX publication_mentions_user_id ON publication_mentions(user_id);
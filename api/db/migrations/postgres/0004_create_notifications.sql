@@ -0,0 +1,23 @@
+CREATE TABLE notifications(
+    id serial primary key,
+
+    user_id int not null
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    actor_id int not null
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    kind varchar(20) not null,
+
+    publication_id int null
+    REFERENCES publications(id)
+    ON DELETE CASCADE,
+
+    dedup_key varchar(100) not null unique,
+    read_at timestamp null default null,
+    created_at timestamp default current_timestamp
+);
+
+CREATE INDEX notifications_user_id ON notifications(user_id, read_at);
@@ -0,0 +1,27 @@
+CREATE TABLE user_blocks(
+    blocker_id int not null
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    blocked_id int not null
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    created_at timestamp default current_timestamp,
+
+    primary key(blocker_id, blocked_id)
+);
+
+CREATE TABLE user_mutes(
+    muter_id int not null
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    muted_id int not null
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    created_at timestamp default current_timestamp,
+
+    primary key(muter_id, muted_id)
+);
@@ -0,0 +1,15 @@
+ALTER TABLE users ADD COLUMN is_private boolean not null default false;
+
+CREATE TABLE follow_requests(
+    user_id int not null
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    follower_id int not null
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    created_at timestamp default current_timestamp,
+
+    primary key(user_id, follower_id)
+);
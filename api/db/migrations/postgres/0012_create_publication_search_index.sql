@@ -0,0 +1 @@
+CREATE INDEX publications_search ON publications USING gin (to_tsvector('simple', title || ' ' || content));
@@ -0,0 +1,23 @@
+CREATE TABLE timelines(
+    user_id int not null
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    publication_id int not null
+    REFERENCES publications(id)
+    ON DELETE CASCADE,
+
+    author_id int not null,
+
+    primary key(user_id, publication_id)
+);
+
+CREATE INDEX timelines_author_id ON timelines(user_id, author_id);
+
+INSERT INTO timelines (user_id, publication_id, author_id)
+SELECT author_id, id, author_id FROM publications;
+
+INSERT INTO timelines (user_id, publication_id, author_id)
+SELECT f.follower_id, p.id, p.author_id
+FROM publications p INNER JOIN followers f ON f.user_id = p.author_id;
+
@@ -0,0 +1,59 @@
+ALTER TABLE publications ADD COLUMN hidden_at timestamp null default null;
+
+CREATE TABLE reports(
+    id serial primary key,
+
+    reporter_id int not null
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    user_id int not null
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    publication_id int null
+    REFERENCES publications(id)
+    ON DELETE CASCADE,
+
+    reason varchar(20) not null,
+    details varchar(500) not null default '',
+    status varchar(20) not null default 'open',
+
+    resolved_by int null
+    REFERENCES users(id)
+    ON DELETE SET NULL,
+
+    resolved_at timestamp null default null,
+    created_at timestamp default current_timestamp
+);
+
+CREATE INDEX reports_status ON reports(status, id);
+
+CREATE INDEX reports_user_id ON reports(user_id, publication_id);
+
+CREATE TABLE moderation_actions(
+    id serial primary key,
+
+    moderator_id int null
+    REFERENCES users(id)
+    ON DELETE SET NULL,
+
+    action varchar(20) not null,
+
+    report_id int null
+    REFERENCES reports(id)
+    ON DELETE SET NULL,
+
+    user_id int not null
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    publication_id int null
+    REFERENCES publications(id)
+    ON DELETE SET NULL,
+
+    note varchar(500) not null default '',
+    created_at timestamp default current_timestamp
+);
+
+CREATE INDEX moderation_actions_user_id ON moderation_actions(user_id, action);
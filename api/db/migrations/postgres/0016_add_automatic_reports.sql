@@ -0,0 +1 @@
+ALTER TABLE reports ADD COLUMN automatic boolean not null default false;
@@ -0,0 +1,16 @@
+CREATE TABLE audit_events(
+    id serial primary key,
+
+    actor_id int null,
+    action varchar(50) not null,
+    target_type varchar(20) not null default '',
+    target_id int null,
+    detail varchar(100) not null default '',
+    ip varchar(45) not null default '',
+    user_agent varchar(255) not null default '',
+    created_at timestamp default current_timestamp
+);
+
+CREATE INDEX audit_events_actor_id ON audit_events(actor_id, id);
+
+CREATE INDEX audit_events_target_id ON audit_events(target_id, target_type);
@@ -0,0 +1,14 @@
+CREATE TABLE sessions(
+    id serial primary key,
+
+    user_id int not null
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    ip varchar(45) not null default '',
+    user_agent varchar(255) not null default '',
+    created_at timestamp default current_timestamp,
+    last_seen_at timestamp default current_timestamp
+);
+
+CREATE INDEX sessions_user_id ON sessions(user_id);
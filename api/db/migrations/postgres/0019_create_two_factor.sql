@@ -0,0 +1,25 @@
+CREATE TABLE two_factor(
+    user_id int not null primary key
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    secret varchar(64) not null,
+    enabled boolean not null default false,
+    last_counter bigint not null default 0,
+    failures int not null default 0,
+    failed_at timestamp null default null,
+    created_at timestamp default current_timestamp
+);
+
+CREATE TABLE recovery_codes(
+    id serial primary key,
+
+    user_id int not null
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    code_hash varchar(64) not null,
+    used_at timestamp null default null
+);
+
+CREATE INDEX recovery_codes_user_id ON recovery_codes(user_id, code_hash);
@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS users(
+    id integer primary key autoincrement,
+    name varchar(50) not null,
+    nickname varchar(50) not null unique,
+    email varchar(50) not null unique,
+    password varchar(100) not null,
+    created_at timestamp default current_timestamp
+);
+
+CREATE TABLE IF NOT EXISTS followers(
+    user_id integer not null
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    follower_id integer not null
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    primary key(user_id, follower_id)
+);
+
+CREATE TABLE IF NOT EXISTS publications(
+    id integer primary key autoincrement,
+    title varchar(50) not null,
+    content varchar(300) not null,
+
+    author_id integer not null
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    likes integer default 0,
+    created_at timestamp default current_timestamp
+);
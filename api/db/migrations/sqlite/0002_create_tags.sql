@@ -0,0 +1,16 @@
+CREATE TABLE tags(
+    id integer primary key autoincrement,
+    name varchar(50) not null unique
+);
+
+CREATE TABLE publication_tags(
+    publication_id integer not null
+    REFERENCES publications(id)
+    ON DELETE CASCADE,
+
+    tag_id integer not null
+    REFERENCES tags(id)
+    ON DELETE CASCADE,
+
+    primary key(publication_id, tag_id)
+);
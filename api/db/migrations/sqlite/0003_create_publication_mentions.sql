@@ -0,0 +1,16 @@
+CREATE TABLE publication_mentions(
+    publication_id integer not null
+    REFERENCES publications(id)
+    ON DELETE CASCADE,
+
+    user_id integer not null
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    start_offset integer not null,
+    end_offset integer not null,
+
+    primary key(publication_id, start_offset)
+);
+
+CREATE INDEX publication_mentions_user_id ON publication_mentions(user_id);
@@ -0,0 +1,38 @@
+CREATE TABLE conversations(
+    id integer primary key autoincrement,
+    last_message_id integer null,
+    created_at timestamp default current_timestamp
+);
+
+CREATE TABLE conversation_participants(
+    conversation_id integer not null
+    REFERENCES conversations(id)
+    ON DELETE CASCADE,
+
+    user_id integer not null
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    last_read_message_id integer not null default 0,
+
+    primary key(conversation_id, user_id)
+);
+
+CREATE INDEX conversation_participants_user_id ON conversation_participants(user_id);
+
+CREATE TABLE messages(
+    id integer primary key autoincrement,
+
+    conversation_id integer not null
+    REFERENCES conversations(id)
+    ON DELETE CASCADE,
+
+    sender_id integer not null
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    content varchar(1000) not null,
+    created_at timestamp default current_timestamp
+);
+
+CREATE INDEX messages_conversation_id ON messages(conversation_id, id);
@@ -0,0 +1,3 @@
+ALTER TABLE publications ADD COLUMN original_id integer null REFERENCES publications(id) ON DELETE SET NULL;
+
+CREATE INDEX publications_original_id ON publications(original_id, author_id);
@@ -0,0 +1,17 @@
+CREATE TABLE publication_attachments(
+    id integer primary key autoincrement,
+
+    publication_id integer not null
+    REFERENCES publications(id)
+    ON DELETE CASCADE,
+
+    storage_key varchar(200) not null,
+    thumbnail_key varchar(200) not null,
+    content_type varchar(50) not null,
+    size integer not null,
+    width integer not null,
+    height integer not null,
+    created_at timestamp default current_timestamp
+);
+
+CREATE INDEX publication_attachments_publication_id ON publication_attachments(publication_id);
@@ -0,0 +1,5 @@
+ALTER TABLE users ADD COLUMN bio varchar(160) not null default '';
+ALTER TABLE users ADD COLUMN location varchar(50) not null default '';
+ALTER TABLE users ADD COLUMN website varchar(100) not null default '';
+ALTER TABLE users ADD COLUMN avatar_key varchar(255);
+ALTER TABLE users ADD COLUMN banner_key varchar(255);
@@ -0,0 +1,16 @@
+ALTER TABLE conversations ADD COLUMN user_low integer null;
+
+ALTER TABLE conversations ADD COLUMN user_high integer null;
+
+UPDATE conversations SET
+    user_low = (select min(p.user_id) from conversation_participants p where p.conversation_id = conversations.id),
+    user_high = (select max(p.user_id) from conversation_participants p where p.conversation_id = conversations.id);
+
+UPDATE conversations SET user_low = null, user_high = null
+WHERE exists (
+    select 1 from conversations older
+    where older.user_low = conversations.user_low and older.user_high = conversations.user_high
+    and older.id < conversations.id
+);
+
+CREATE UNIQUE INDEX conversations_pair ON conversations(user_low, user_high);
@@ -0,0 +1 @@
+ALTER TABLE two_factor ADD COLUMN challenge varchar(64) null;
@@ -0,0 +1,66 @@
+package events
+
+import (
+	"context"
+	"fmt"
+)
+
+// Types of the events pushed to clients.
+const (
+	// TypePublication: someone the user follows published; Data is the publication.
+	TypePublication = "publication"
+	// TypeLike: a publication of the user was liked.
+	TypeLike = "like"
+	// TypeFollow: the user gained a follower.
+	TypeFollow = "follow"
+	// TypeMessage: the user received a direct message; Data is the message.
+	TypeMessage = "message"
+	// TypeWarning: the moderators warned the user; Data is the warning.
+	TypeWarning = "warning"
+	// TypeSessionRevoked: the session of the stream was revoked, which ends
+	// the stream. It is published on the SessionTopic.
+	TypeSessionRevoked = "session_revoked"
+)
+
+type Event struct {
+	Type string `json:"type"`
+	Data any    `json:"data"`
+	// Actor is the user whose action caused the event, 0 for the staff. The
+	// stream skips the events of the users the subscriber blocked or muted,
+	// or who blocked them.
+	Actor uint64 `json:"-"`
+}
+
+// Like is the Data of a TypeLike event.
+type Like struct {
+	PublicationID uint64 `json:"publicationId"`
+	UserID        uint64 `json:"userId"`
+}
+
+// Follow is the Data of a TypeFollow event.
+type Follow struct {
+	FollowerID uint64 `json:"followerId"`
+}
+
+// Broker delivers events published on a topic to its current subscribers.
+// The in-process MemoryBroker can be replaced by one backed by Redis or NATS
+// when the API runs on several instances.
+type Broker interface {
+	Publish(topic string, event Event) error
+	// Subscribe returns the events published on topic until ctx is done,
+	// when the channel is closed.
+	Subscribe(ctx context.Context, topic string) (<-chan Event, error)
+}
+
+// Default is the broker used by the services and the stream endpoint.
+var Default Broker = NewMemoryBroker(16)
+
+// UserTopic is the topic of the events addressed to a user.
+func UserTopic(userId uint64) string {
+	return fmt.Sprintf("users.%d", userId)
+}
+
+// SessionTopic is the topic of the events about a session of a user.
+func SessionTopic(sessionId uint64) string {
+	return fmt.Sprintf("sessions.%d", sessionId)
+}
@@ -0,0 +1,60 @@
+package events
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryBroker is a Broker for a single API instance. Subscribers that do not
+// keep up miss events instead of blocking the publisher.
+type MemoryBroker struct {
+	mutex       sync.RWMutex
+	subscribers map[string]map[chan Event]struct{}
+	buffer      int
+}
+
+// NewMemoryBroker returns a broker queuing up to buffer events per subscriber.
+func NewMemoryBroker(buffer int) *MemoryBroker {
+	return &MemoryBroker{
+		subscribers: map[string]map[chan Event]struct{}{},
+		buffer:      buffer,
+	}
+}
+
+func (broker *MemoryBroker) Publish(topic string, event Event) error {
+	broker.mutex.RLock()
+	defer broker.mutex.RUnlock()
+
+	for subscriber := range broker.subscribers[topic] {
+		select {
+		case subscriber <- event:
+		default:
+		}
+	}
+	return nil
+}
+
+func (broker *MemoryBroker) Subscribe(ctx context.Context, topic string) (<-chan Event, error) {
+	subscriber := make(chan Event, broker.buffer)
+
+	broker.mutex.Lock()
+	if broker.subscribers[topic] == nil {
+		broker.subscribers[topic] = map[chan Event]struct{}{}
+	}
+	broker.subscribers[topic][subscriber] = struct{}{}
+	broker.mutex.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		broker.mutex.Lock()
+		delete(broker.subscribers[topic], subscriber)
+		if len(broker.subscribers[topic]) == 0 {
+			delete(broker.subscribers, topic)
+		}
+		broker.mutex.Unlock()
+
+		close(subscriber)
+	}()
+	return subscriber, nil
+}
@@ -3,11 +3,19 @@ package main
 import (
 	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
+	"os"
 
 	"github.com/wesleywcr/dev-book/api/config"
+	"github.com/wesleywcr/dev-book/api/db"
 	_ "github.com/wesleywcr/dev-book/api/docs" // Import generated Swagger docs
+	"github.com/wesleywcr/dev-book/api/models"
+	"github.com/wesleywcr/dev-book/api/policy"
+	"github.com/wesleywcr/dev-book/api/repositories"
 	"github.com/wesleywcr/dev-book/api/router"
+	"github.com/wesleywcr/dev-book/api/search"
+	"github.com/wesleywcr/dev-book/api/storage"
 )
 
 // @securityDefinitions.apikey Bearer
@@ -15,10 +23,51 @@ import (
 // @name Authorization
 // @description Type "Bearer" followed by a space and JWT token.
 func main() {
-	config.Loading()
+	if error := config.Loading(os.Args[1:]); error != nil {
+		log.Fatal(error)
+	}
+	settings := config.Settings
 
-	r := router.InitRouter()
+	var level slog.Level
+	if error := level.UnmarshalText([]byte(settings.Log.Level)); error != nil {
+		log.Fatal(error)
+	}
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level})))
 
-	fmt.Printf("Server ON %d\n", config.Port)
-	log.Fatal(http.ListenAndServe(fmt.Sprintf(":%d", config.Port), r))
+	if error := db.Open(); error != nil {
+		log.Fatal(error)
+	}
+	if error := db.Migrate(); error != nil {
+		log.Fatal(error)
+	}
+	if error := storage.Open(); error != nil {
+		log.Fatal(error)
+	}
+	if error := search.Open(publicationsToIndex); error != nil {
+		log.Fatal(error)
+	}
+	if error := policy.Open(); error != nil {
+		log.Fatal(error)
+	}
+
+	server := &http.Server{
+		Addr:         fmt.Sprintf(":%d", settings.Server.Port),
+		Handler:      router.InitRouter(),
+		ReadTimeout:  settings.Server.ReadTimeout,
+		WriteTimeout: settings.Server.WriteTimeout,
+		IdleTimeout:  settings.Server.IdleTimeout,
+	}
+
+	fmt.Printf("Server ON %d\n", settings.Server.Port)
+	log.Fatal(server.ListenAndServe())
+}
+
+// publicationsToIndex loads the publications the in-process search index
+// starts with.
+func publicationsToIndex() ([]models.Publication, error) {
+	db, error := db.ConnectDB()
+	if error != nil {
+		return nil, error
+	}
+	return repositories.NewPublicationSearch(db).SearchAll()
 }
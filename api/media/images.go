@@ -0,0 +1,120 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif" // Register the GIF decoder
+	"image/jpeg"
+	_ "image/png" // Register the PNG decoder
+	"net/http"
+
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // Register the WebP decoder
+)
+
+// maxPixels bounds the decoded size of an image, so a small file cannot
+// expand into gigabytes of memory.
+const maxPixels = 40_000_000
+
+// extensions maps the accepted content types to the extension of their files.
+var extensions = map[string]string{
+	"image/jpeg": "jpg",
+	"image/png":  "png",
+	"image/gif":  "gif",
+	"image/webp": "webp",
+}
+
+// Image describes an uploaded picture checked by Inspect.
+type Image struct {
+	ContentType string
+	Extension   string
+	Width       int
+	Height      int
+}
+
+// Inspect sniffs the content, ignoring the type the client declared, and
+// accepts only JPEG, PNG, GIF and WebP images of bounded dimensions.
+func Inspect(content []byte) (Image, error) {
+	contentType := http.DetectContentType(content)
+	extension, accepted := extensions[contentType]
+	if !accepted {
+		return Image{}, errors.New("Formato de imagem não suportado, envie JPEG, PNG, GIF ou WebP")
+	}
+
+	config, _, error := image.DecodeConfig(bytes.NewReader(content))
+	if error != nil {
+		return Image{}, errors.New("A imagem está corrompida ou não pôde ser lida")
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxPixels {
+		return Image{}, errors.New("As dimensões da imagem excedem o limite permitido")
+	}
+
+	return Image{
+		ContentType: contentType,
+		Extension:   extension,
+		Width:       config.Width,
+		Height:      config.Height,
+	}, nil
+}
+
+// Thumbnail scales the image down to fit a maxSide square, keeping its
+// proportions, and encodes it as JPEG. Transparent areas become white.
+func Thumbnail(content []byte, maxSide int) ([]byte, error) {
+	source, _, error := image.Decode(bytes.NewReader(content))
+	if error != nil {
+		return nil, error
+	}
+
+	bounds := source.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > maxSide || height > maxSide {
+		if width >= height {
+			width, height = maxSide, max(1, height*maxSide/width)
+		} else {
+			width, height = max(1, width*maxSide/height), maxSide
+		}
+	}
+	return encode(source, bounds, width, height)
+}
+
+func encode(source image.Image, area image.Rectangle, width, height int) ([]byte, error) {
+	target := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(target, target.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	xdraw.CatmullRom.Scale(target, target.Bounds(), source, area, xdraw.Over, nil)
+
+	var output bytes.Buffer
+	if error := jpeg.Encode(&output, target, &jpeg.Options{Quality: 85}); error != nil {
+		return nil, error
+	}
+	return output.Bytes(), nil
+}
+
+// Cover crops the center of the image to the proportions of width x height
+// and scales it down to that size, encoding it as JPEG. Images smaller than
+// the target are cropped but not enlarged.
+func Cover(content []byte, width, height int) ([]byte, error) {
+	source, _, error := image.Decode(bytes.NewReader(content))
+	if error != nil {
+		return nil, error
+	}
+
+	bounds := source.Bounds()
+	area := bounds
+	if bounds.Dx()*height > bounds.Dy()*width {
+		cropped := max(1, bounds.Dy()*width/height)
+		area.Min.X += (bounds.Dx() - cropped) / 2
+		area.Max.X = area.Min.X + cropped
+	} else {
+		cropped := max(1, bounds.Dx()*height/width)
+		area.Min.Y += (bounds.Dy() - cropped) / 2
+		area.Max.Y = area.Min.Y + cropped
+	}
+
+	if area.Dx() < width {
+		width, height = area.Dx(), area.Dy()
+	}
+	return encode(source, area, width, height)
+}
@@ -0,0 +1,187 @@
+package media
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+var (
+	red   = color.RGBA{255, 0, 0, 255}
+	green = color.RGBA{0, 255, 0, 255}
+	blue  = color.RGBA{0, 0, 255, 255}
+)
+
+// striped returns a PNG of width x height split in three vertical stripes,
+// red, green and blue from left to right.
+func striped(t *testing.T, width, height int) []byte {
+	t.Helper()
+	picture := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		stripe := []color.RGBA{red, green, blue}[x*3/width]
+		for y := 0; y < height; y++ {
+			picture.Set(x, y, stripe)
+		}
+	}
+	return encodePNG(t, picture)
+}
+
+func encodePNG(t *testing.T, picture image.Image) []byte {
+	t.Helper()
+	var output bytes.Buffer
+	if error := png.Encode(&output, picture); error != nil {
+		t.Fatal(error)
+	}
+	return output.Bytes()
+}
+
+func decodeJPEG(t *testing.T, content []byte) image.Image {
+	t.Helper()
+	picture, error := jpeg.Decode(bytes.NewReader(content))
+	if error != nil {
+		t.Fatalf("output is not a JPEG: %v", error)
+	}
+	return picture
+}
+
+// near tells whether the color is close to expected, as JPEG is lossy.
+func near(actual color.Color, expected color.RGBA) bool {
+	r, g, b, _ := actual.RGBA()
+	distance := func(a uint32, b uint8) int {
+		return max(int(a>>8), int(b)) - min(int(a>>8), int(b))
+	}
+	return distance(r, expected.R) < 40 && distance(g, expected.G) < 40 && distance(b, expected.B) < 40
+}
+
+func TestInspect(t *testing.T) {
+	var gifContent bytes.Buffer
+	if error := gif.Encode(&gifContent, image.NewPaletted(image.Rect(0, 0, 3, 2), []color.Color{red}), nil); error != nil {
+		t.Fatal(error)
+	}
+	pngContent := striped(t, 30, 20)
+
+	tests := []struct {
+		name        string
+		content     []byte
+		contentType string
+		extension   string
+		width       int
+		height      int
+		fails       bool
+	}{
+		{name: "PNG", content: pngContent, contentType: "image/png", extension: "png", width: 30, height: 20},
+		{name: "GIF", content: gifContent.Bytes(), contentType: "image/gif", extension: "gif", width: 3, height: 2},
+		{name: "text", content: []byte("not an image"), fails: true},
+		{name: "truncated PNG", content: pngContent[:20], fails: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			inspected, error := Inspect(test.content)
+			if test.fails {
+				if error == nil {
+					t.Fatalf("Inspect accepted %s", test.name)
+				}
+				return
+			}
+			if error != nil {
+				t.Fatal(error)
+			}
+			expected := Image{test.contentType, test.extension, test.width, test.height}
+			if inspected != expected {
+				t.Errorf("Inspect = %+v, want %+v", inspected, expected)
+			}
+		})
+	}
+}
+
+func TestThumbnail(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		maxSide       int
+		expectedW     int
+		expectedH     int
+	}{
+		{name: "landscape", width: 400, height: 200, maxSide: 100, expectedW: 100, expectedH: 50},
+		{name: "portrait", width: 200, height: 400, maxSide: 100, expectedW: 50, expectedH: 100},
+		{name: "thin", width: 1000, height: 5, maxSide: 100, expectedW: 100, expectedH: 1},
+		{name: "smaller than the box", width: 60, height: 30, maxSide: 100, expectedW: 60, expectedH: 30},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			thumbnail, error := Thumbnail(striped(t, test.width, test.height), test.maxSide)
+			if error != nil {
+				t.Fatal(error)
+			}
+			bounds := decodeJPEG(t, thumbnail).Bounds()
+			if bounds.Dx() != test.expectedW || bounds.Dy() != test.expectedH {
+				t.Errorf("thumbnail is %dx%d, want %dx%d", bounds.Dx(), bounds.Dy(), test.expectedW, test.expectedH)
+			}
+		})
+	}
+}
+
+func TestThumbnailFillsTransparencyWithWhite(t *testing.T) {
+	thumbnail, error := Thumbnail(encodePNG(t, image.NewNRGBA(image.Rect(0, 0, 20, 20))), 10)
+	if error != nil {
+		t.Fatal(error)
+	}
+	if pixel := decodeJPEG(t, thumbnail).At(5, 5); !near(pixel, color.RGBA{255, 255, 255, 255}) {
+		t.Errorf("transparent pixel became %v, want white", pixel)
+	}
+}
+
+func TestCover(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		coverW        int
+		coverH        int
+		expectedW     int
+		expectedH     int
+		// left and right are the colors expected at the edges of the cover.
+		left, right color.RGBA
+	}{
+		// a square out of a wide image keeps its green center stripe
+		{name: "crops the sides", width: 600, height: 200, coverW: 100, coverH: 100, expectedW: 100, expectedH: 100, left: green, right: green},
+		// a banner out of a tall image keeps the three stripes
+		{name: "crops top and bottom", width: 300, height: 600, coverW: 150, coverH: 50, expectedW: 150, expectedH: 50, left: red, right: blue},
+		{name: "does not enlarge", width: 90, height: 30, coverW: 300, coverH: 100, expectedW: 90, expectedH: 30, left: red, right: blue},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cover, error := Cover(striped(t, test.width, test.height), test.coverW, test.coverH)
+			if error != nil {
+				t.Fatal(error)
+			}
+			picture := decodeJPEG(t, cover)
+			bounds := picture.Bounds()
+			if bounds.Dx() != test.expectedW || bounds.Dy() != test.expectedH {
+				t.Fatalf("cover is %dx%d, want %dx%d", bounds.Dx(), bounds.Dy(), test.expectedW, test.expectedH)
+			}
+			middle := bounds.Dy() / 2
+			if pixel := picture.At(2, middle); !near(pixel, test.left) {
+				t.Errorf("left edge is %v, want %v", pixel, test.left)
+			}
+			if pixel := picture.At(bounds.Dx()-3, middle); !near(pixel, test.right) {
+				t.Errorf("right edge is %v, want %v", pixel, test.right)
+			}
+		})
+	}
+}
+
+func TestRejectsUndecodableImages(t *testing.T) {
+	if _, error := Thumbnail([]byte("not an image"), 100); error == nil {
+		t.Error("Thumbnail accepted a text")
+	}
+	if _, error := Cover([]byte("not an image"), 100, 100); error == nil {
+		t.Error("Cover accepted a text")
+	}
+}
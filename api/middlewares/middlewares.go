@@ -1,16 +1,21 @@
 package middlewares
 
 import (
-	"log"
+	"errors"
+	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/wesleywcr/dev-book/api/auth"
+	"github.com/wesleywcr/dev-book/api/db"
+	"github.com/wesleywcr/dev-book/api/models"
+	"github.com/wesleywcr/dev-book/api/repositories"
 	"github.com/wesleywcr/dev-book/api/response"
 )
 
 func Logger(nextFunction http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		log.Printf("\n %s %s %s", r.Method, r.RequestURI, r.Host)
+		slog.Info("request", "method", r.Method, "uri", r.RequestURI, "host", r.Host)
 		nextFunction(w, r)
 	}
 }
@@ -23,3 +28,70 @@ func Authenticate(nextFunction http.HandlerFunc) http.HandlerFunc {
 		nextFunction(w, r)
 	}
 }
+
+// touchInterval is how often the last use of a session is recorded.
+const touchInterval = time.Minute
+
+// Active rejects the tokens of revoked sessions and of suspended accounts,
+// which keep being valid until they expire, and records the use of the
+// session. The role the user has now is passed on to Authorize.
+func Active(nextFunction http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userId, error := auth.ExtractUserId(r)
+		if error != nil {
+			response.Error(w, http.StatusUnauthorized, error)
+			return
+		}
+		sessionId, error := auth.ExtractSessionId(r)
+		if error != nil {
+			response.Error(w, http.StatusUnauthorized, error)
+			return
+		}
+		if sessionId == 0 {
+			response.Error(w, http.StatusUnauthorized, errors.New("Sessão inválida, faça login novamente"))
+			return
+		}
+
+		db, error := db.ConnectDB()
+		if error != nil {
+			response.Error(w, http.StatusInternalServerError, error)
+			return
+		}
+		sessions := repositories.NewRepositoryOfSessions(db)
+		open, suspended, role, error := sessions.Check(sessionId, userId)
+		if error != nil {
+			response.Error(w, http.StatusInternalServerError, error)
+			return
+		}
+		if !open {
+			response.Error(w, http.StatusUnauthorized, errors.New("Esta sessão foi encerrada, faça login novamente"))
+			return
+		}
+		if suspended {
+			response.Error(w, http.StatusForbidden, errors.New("Esta conta está suspensa"))
+			return
+		}
+
+		if error = sessions.Touch(sessionId, touchInterval); error != nil {
+			slog.Warn("session not touched", "session", sessionId, "error", error)
+		}
+		nextFunction(w, auth.WithRole(r, role))
+	}
+}
+
+// Authorize lets through only the users whose current role grants the
+// required one. It runs after Active, which reads that role.
+func Authorize(required models.Role, nextFunction http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		role, error := auth.ExtractRole(r)
+		if error != nil {
+			response.Error(w, http.StatusUnauthorized, error)
+			return
+		}
+		if !role.AtLeast(required) {
+			response.Error(w, http.StatusForbidden, errors.New("Você não tem permissão para acessar este recurso"))
+			return
+		}
+		nextFunction(w, r)
+	}
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Attachment is an image attached to a publication. Its files live in the
+// storage under Key and ThumbnailKey; URL and ThumbnailURL point to them.
+type Attachment struct {
+	ID            uint64    `json:"id"`
+	PublicationID uint64    `json:"publicationId"`
+	URL           string    `json:"url"`
+	ThumbnailURL  string    `json:"thumbnailUrl"`
+	ContentType   string    `json:"contentType"`
+	Size          int       `json:"size"`
+	Width         int       `json:"width"`
+	Height        int       `json:"height"`
+	Created_at    time.Time `json:"created_at"`
+	Key           string    `json:"-"`
+	ThumbnailKey  string    `json:"-"`
+}
+
+// Upload is a file received from a client, before it is checked.
+type Upload struct {
+	Filename string
+	Content  []byte
+}
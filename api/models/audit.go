@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+	"unicode/utf8"
+)
+
+// maxUserAgentLength is the size of audit_events.user_agent, in characters.
+const maxUserAgentLength = 255
+
+// AuditAction is a security-relevant action recorded in the audit log.
+type AuditAction string
+
+const (
+	AuditLogin          AuditAction = "login"
+	AuditLoginFailed    AuditAction = "login_failed"
+	AuditPasswordChange AuditAction = "password_changed"
+	AuditAccountDeleted AuditAction = "account_deleted"
+	// AuditSessionRevoked: Detail is the ID of the session.
+	AuditSessionRevoked AuditAction = "session_revoked"
+	// AuditOtherSessionsRevoked: Detail is how many sessions were revoked.
+	AuditOtherSessionsRevoked AuditAction = "other_sessions_revoked"
+	AuditTwoFactorEnabled     AuditAction = "two_factor_enabled"
+	AuditTwoFactorDisabled    AuditAction = "two_factor_disabled"
+	// AuditRecoveryCodeUsed: a recovery code replaced a TOTP code to log in.
+	AuditRecoveryCodeUsed AuditAction = "recovery_code_used"
+	// The actions below are taken by the staff on someone else's account or
+	// publication.
+	AuditUserSuspended      AuditAction = "user_suspended"
+	AuditUserUnsuspended    AuditAction = "user_unsuspended"
+	AuditRoleChanged        AuditAction = "role_changed"
+	AuditPublicationRemoved AuditAction = "publication_removed"
+)
+
+// Targets of the audit events.
+const (
+	AuditTargetUser        = "user"
+	AuditTargetPublication = "publication"
+)
+
+// AuditEvent records who did what to which user or publication, from where.
+// ActorID is 0 when the actor is unknown, as in a failed login.
+type AuditEvent struct {
+	ID         uint64      `json:"id"`
+	ActorID    uint64      `json:"actorId,omitempty"`
+	Action     AuditAction `json:"action"`
+	TargetType string      `json:"targetType,omitempty"`
+	TargetID   uint64      `json:"targetId,omitempty"`
+	// Detail completes the action, as the new role of a role_changed.
+	Detail     string    `json:"detail,omitempty"`
+	IP         string    `json:"ip,omitempty"`
+	UserAgent  string    `json:"userAgent,omitempty"`
+	Created_at time.Time `json:"created_at"`
+}
+
+// Prepare shortens the user agent to fit its column.
+func (event *AuditEvent) Prepare() {
+	if utf8.RuneCountInString(event.UserAgent) > maxUserAgentLength {
+		event.UserAgent = string([]rune(event.UserAgent)[:maxUserAgentLength])
+	}
+}
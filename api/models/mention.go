@@ -0,0 +1,10 @@
+package models
+
+// Mention references a user in the content of a publication. Start and End
+// are the character (not byte) offsets of "@nickname" in the content.
+type Mention struct {
+	UserID   uint64 `json:"userId"`
+	Nickname string `json:"nickname"`
+	Start    int    `json:"start"`
+	End      int    `json:"end"`
+}
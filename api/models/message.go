@@ -0,0 +1,59 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxMessageLength is the size of messages.content, in characters.
+const maxMessageLength = 1000
+
+// previewLength is how many characters of the last message a conversation shows.
+const previewLength = 100
+
+type Message struct {
+	ID             uint64    `json:"id,omitempty"`
+	ConversationID uint64    `json:"conversationId,omitempty"`
+	SenderID       uint64    `json:"senderId,omitempty"`
+	Content        string    `json:"content,omitempty"`
+	Created_at     time.Time `json:"created_at,omitempty"`
+}
+
+func (message *Message) Prepare() error {
+	message.Content = strings.TrimSpace(message.Content)
+
+	if message.Content == "" {
+		return errors.New("O conteúdo da mensagem é obrigatório e não pode estar em branco")
+	}
+	if utf8.RuneCountInString(message.Content) > maxMessageLength {
+		return errors.New("A mensagem não pode ter mais de 1000 caracteres")
+	}
+	return nil
+}
+
+// Preview shortens the content to be listed along with its conversation.
+func (message Message) Preview() Message {
+	runes := []rune(message.Content)
+	if len(runes) > previewLength {
+		message.Content = string(runes[:previewLength]) + "…"
+	}
+	return message
+}
+
+// Conversation is a private exchange of messages between two users, as seen
+// by one of them.
+type Conversation struct {
+	ID          uint64      `json:"id"`
+	Participant UserSummary `json:"participant"`
+	LastMessage *Message    `json:"lastMessage,omitempty"`
+	// Unread counts the messages received after the last read one.
+	Unread     uint64    `json:"unread"`
+	Created_at time.Time `json:"created_at"`
+}
+
+// NewConversation is the body of a request to start a conversation.
+type NewConversation struct {
+	UserID uint64 `json:"userId"`
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+type NotificationKind string
+
+const (
+	NotificationFollow  NotificationKind = "follow"
+	NotificationLike    NotificationKind = "like"
+	NotificationMention NotificationKind = "mention"
+	// NotificationFollowRequest: someone asked to follow the private account.
+	NotificationFollowRequest NotificationKind = "follow_request"
+	// NotificationFollowAccepted: a private account approved the request.
+	NotificationFollowAccepted NotificationKind = "follow_accepted"
+	NotificationRepost         NotificationKind = "repost"
+	NotificationQuote          NotificationKind = "quote"
+)
+
+// NotificationEvent is a single action of Actor that UserID should learn about.
+type NotificationEvent struct {
+	UserID        uint64
+	ActorID       uint64
+	Kind          NotificationKind
+	PublicationID uint64
+}
+
+// NotificationDedupWindow is how long the same event notifies only once, so
+// liking or following again after undoing it does not notify twice in a row
+// but does after a while.
+const NotificationDedupWindow = 24 * time.Hour
+
+// DedupKey identifies the event within the NotificationDedupWindow around
+// at, so the same actor liking, following or mentioning twice in that window
+// notifies only once.
+func (event NotificationEvent) DedupKey(at time.Time) string {
+	return fmt.Sprintf(
+		"%d:%s:%d:%d:%d",
+		event.UserID, event.Kind, event.ActorID, event.PublicationID, at.Unix()/int64(NotificationDedupWindow/time.Second),
+	)
+}
+
+// Notification aggregates the events of one kind about the same publication,
+// or about the user for follows, e.g. "ana e mais 4 pessoas curtiram sua publicação".
+type Notification struct {
+	Kind          NotificationKind `json:"kind"`
+	PublicationID uint64           `json:"publicationId,omitempty"`
+	// Actors holds the most recent actors, newest first.
+	Actors      []UserSummary `json:"actors"`
+	ActorsCount uint64        `json:"actorsCount"`
+	Message     string        `json:"message"`
+	Read        bool          `json:"read"`
+	Created_at  time.Time     `json:"created_at"`
+}
+
+type NotificationPage struct {
+	Notifications []Notification `json:"notifications"`
+	Unread        uint64         `json:"unread"`
+}
+
+// Describe fills Message from the kind and the actors.
+func (notification *Notification) Describe() {
+	if len(notification.Actors) == 0 {
+		return
+	}
+
+	who := notification.Actors[0].Nickname
+	switch {
+	case notification.ActorsCount == 2 && len(notification.Actors) > 1:
+		who = fmt.Sprintf("%s e %s", who, notification.Actors[1].Nickname)
+	case notification.ActorsCount == 2:
+		who = fmt.Sprintf("%s e mais 1 pessoa", who)
+	case notification.ActorsCount > 2:
+		who = fmt.Sprintf("%s e mais %d pessoas", who, notification.ActorsCount-1)
+	}
+	plural := notification.ActorsCount > 1
+
+	switch notification.Kind {
+	case NotificationFollow:
+		notification.Message = who + pick(plural, " começaram a seguir você", " começou a seguir você")
+	case NotificationLike:
+		notification.Message = who + pick(plural, " curtiram sua publicação", " curtiu sua publicação")
+	case NotificationMention:
+		notification.Message = who + pick(plural, " mencionaram você em uma publicação", " mencionou você em uma publicação")
+	case NotificationFollowRequest:
+		notification.Message = who + pick(plural, " pediram para seguir você", " pediu para seguir você")
+	case NotificationFollowAccepted:
+		notification.Message = who + pick(plural, " aceitaram sua solicitação para seguir", " aceitou sua solicitação para seguir")
+	case NotificationRepost:
+		notification.Message = who + pick(plural, " repostaram sua publicação", " repostou sua publicação")
+	case NotificationQuote:
+		notification.Message = who + pick(plural, " citaram sua publicação", " citou sua publicação")
+	}
+}
+
+func pick(condition bool, whenTrue, whenFalse string) string {
+	if condition {
+		return whenTrue
+	}
+	return whenFalse
+}
@@ -0,0 +1,11 @@
+package models
+
+// Page selects a window of a listing, as in ?page=2&limit=20.
+type Page struct {
+	Number uint64
+	Limit  uint64
+}
+
+func (page Page) Offset() uint64 {
+	return (page.Number - 1) * page.Limit
+}
@@ -2,18 +2,54 @@ package models
 
 import (
 	"errors"
+	"regexp"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
+// hashtagPattern matches "#tag" when not glued to a previous word, so that
+// "a#b" or "&#39;" are not hashtags.
+var hashtagPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_&])#([\p{L}\p{N}_]{1,50})`)
+
+// mentionPattern matches "@nickname" when not glued to a previous word, so
+// that e-mail addresses are not mentions.
+var mentionPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_@])@([\p{L}\p{N}_.\-]{1,50})`)
+
 type Publication struct {
-	ID              uint64    `json:"id,omitempty"`
-	Title           string    `json:"title,omitempty"`
-	Content         string    `json:"content,omitempty"`
-	AuthorID        uint64    `json:"authorId,omitempty"`
-	AuthorNickaname string    `json:"authorNickname,omitempty"`
-	Likes           uint64    `json:"likes"`
-	Created_at      time.Time `json:"created_at,omitempty"`
+	ID              uint64       `json:"id,omitempty"`
+	Title           string       `json:"title,omitempty"`
+	Content         string       `json:"content,omitempty"`
+	AuthorID        uint64       `json:"authorId,omitempty"`
+	AuthorNickaname string       `json:"authorNickname,omitempty"`
+	Likes           uint64       `json:"likes"`
+	Created_at      time.Time    `json:"created_at,omitempty"`
+	Updated_at      *time.Time   `json:"updated_at,omitempty"`
+	Mentions        []Mention    `json:"mentions,omitempty"`
+	Attachments     []Attachment `json:"attachments,omitempty"`
+	// OriginalID is the publication reposted or quoted by this one.
+	OriginalID uint64       `json:"originalId,omitempty"`
+	Original   *Publication `json:"original,omitempty"`
+	Reposts    uint64       `json:"reposts"`
+	// RepostedBy is set when the publication reached a feed through a repost.
+	RepostedBy *UserSummary `json:"repostedBy,omitempty"`
+}
+
+// IsRepost tells a plain repost, which has no content of its own, from a
+// quote of the original publication.
+func (publication Publication) IsRepost() bool {
+	return publication.OriginalID != 0 && publication.Content == ""
+}
+
+// PublicationRevision is a previous version of an edited publication;
+// Created_at is when it was replaced.
+type PublicationRevision struct {
+	ID            uint64    `json:"id"`
+	PublicationID uint64    `json:"publicationId"`
+	Title         string    `json:"title"`
+	Content       string    `json:"content"`
+	Created_at    time.Time `json:"created_at"`
 }
 
 func (publication *Publication) Prepare() error {
@@ -39,3 +75,42 @@ func (publication *Publication) format() {
 	publication.Title = strings.TrimSpace(publication.Title)
 	publication.Content = strings.TrimSpace(publication.Content)
 }
+
+// Hashtags returns the distinct normalized hashtags found in the content.
+// Tags made only of digits, like "#1", are ignored.
+func (publication Publication) Hashtags() []string {
+	var hashtags []string
+	seen := map[string]bool{}
+
+	for _, match := range hashtagPattern.FindAllStringSubmatch(publication.Content, -1) {
+		tag := NormalizeTag(match[1])
+		if seen[tag] || strings.IndexFunc(tag, unicode.IsLetter) < 0 {
+			continue
+		}
+		seen[tag] = true
+		hashtags = append(hashtags, tag)
+	}
+	return hashtags
+}
+
+// MentionCandidates returns every "@nickname" of the content with its offsets.
+// UserID is left empty: resolving nicknames to users is up to the caller.
+func (publication Publication) MentionCandidates() []Mention {
+	var mentions []Mention
+	content := publication.Content
+
+	for _, match := range mentionPattern.FindAllStringSubmatchIndex(content, -1) {
+		nickname := strings.TrimRight(content[match[2]:match[3]], ".-")
+		if nickname == "" {
+			continue
+		}
+
+		start := utf8.RuneCountInString(content[:match[2]-1])
+		mentions = append(mentions, Mention{
+			Nickname: nickname,
+			Start:    start,
+			End:      start + 1 + utf8.RuneCountInString(nickname),
+		})
+	}
+	return mentions
+}
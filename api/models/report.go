@@ -0,0 +1,166 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxReportDetails is the size of reports.details and moderation_actions.note,
+// in characters.
+const maxReportDetails = 500
+
+// ReportReason is the category of abuse a report points out.
+type ReportReason string
+
+const (
+	ReasonSpam           ReportReason = "spam"
+	ReasonHarassment     ReportReason = "harassment"
+	ReasonHate           ReportReason = "hate"
+	ReasonViolence       ReportReason = "violence"
+	ReasonNudity         ReportReason = "nudity"
+	ReasonMisinformation ReportReason = "misinformation"
+	// ReasonOther requires the reporter to describe the problem in Details.
+	ReasonOther ReportReason = "other"
+)
+
+var reportReasons = map[ReportReason]bool{
+	ReasonSpam:           true,
+	ReasonHarassment:     true,
+	ReasonHate:           true,
+	ReasonViolence:       true,
+	ReasonNudity:         true,
+	ReasonMisinformation: true,
+	ReasonOther:          true,
+}
+
+// Valid reports whether the reason is one of the known categories.
+func (reason ReportReason) Valid() bool {
+	return reportReasons[reason]
+}
+
+// ReportStatus tells where a report is in the moderation queue.
+type ReportStatus string
+
+const (
+	ReportOpen ReportStatus = "open"
+	// ReportResolved: a moderator acted on the reported content or user.
+	ReportResolved ReportStatus = "resolved"
+	// ReportDismissed: a moderator found nothing wrong.
+	ReportDismissed ReportStatus = "dismissed"
+)
+
+// Report flags a publication, or a user when PublicationID is 0, for the
+// moderators. User is the reported user or the author of the publication.
+// Automatic reports are filed by the content policy and have no Reporter.
+type Report struct {
+	ID            uint64       `json:"id"`
+	Reporter      *UserSummary `json:"reporter,omitempty"`
+	Automatic     bool         `json:"automatic"`
+	User          UserSummary  `json:"user"`
+	PublicationID uint64       `json:"publicationId,omitempty"`
+	Reason        ReportReason `json:"reason"`
+	Details       string       `json:"details,omitempty"`
+	Status        ReportStatus `json:"status"`
+	ResolvedBy    uint64       `json:"resolvedBy,omitempty"`
+	ResolvedAt    *time.Time   `json:"resolvedAt,omitempty"`
+	Created_at    time.Time    `json:"created_at"`
+}
+
+// NewReport is the body of a request to report a publication or a user.
+type NewReport struct {
+	Reason  ReportReason `json:"reason"`
+	Details string       `json:"details"`
+}
+
+// Prepare checks the reason and trims the details of a new report.
+func (report *Report) Prepare() error {
+	report.Details = strings.TrimSpace(report.Details)
+
+	if !report.Reason.Valid() {
+		return errors.New("O motivo deve ser spam, harassment, hate, violence, nudity, misinformation ou other")
+	}
+	if report.Reason == ReasonOther && report.Details == "" {
+		return errors.New("Descreva o problema quando o motivo for other")
+	}
+	if utf8.RuneCountInString(report.Details) > maxReportDetails {
+		return errors.New("Os detalhes da denúncia não podem ter mais de 500 caracteres")
+	}
+	return nil
+}
+
+// ReportFilter selects the reports listed in the moderation queue. Empty
+// fields match every report.
+type ReportFilter struct {
+	Status ReportStatus
+	Reason ReportReason
+	// Target is "publication" or "user".
+	Target string
+	UserID uint64
+	// Automatic, when not nil, selects only the automatic reports or only
+	// those of users.
+	Automatic *bool
+}
+
+// ModerationKind is what a moderator did about a report.
+type ModerationKind string
+
+const (
+	// ModerationHide hides the reported publication from everyone.
+	ModerationHide ModerationKind = "hide"
+	// ModerationWarn warns the reported user, who is told about it.
+	ModerationWarn ModerationKind = "warn"
+	// ModerationSuspend suspends the account of the reported user.
+	ModerationSuspend ModerationKind = "suspend"
+	// ModerationDismiss closes the report without acting.
+	ModerationDismiss ModerationKind = "dismiss"
+)
+
+var moderationKinds = map[ModerationKind]bool{
+	ModerationHide:    true,
+	ModerationWarn:    true,
+	ModerationSuspend: true,
+	ModerationDismiss: true,
+}
+
+// ModerationAction is an entry of the audit log of the moderators.
+type ModerationAction struct {
+	ID            uint64         `json:"id"`
+	ModeratorID   uint64         `json:"moderatorId"`
+	Kind          ModerationKind `json:"action"`
+	ReportID      uint64         `json:"reportId,omitempty"`
+	UserID        uint64         `json:"userId"`
+	PublicationID uint64         `json:"publicationId,omitempty"`
+	Note          string         `json:"note,omitempty"`
+	Created_at    time.Time      `json:"created_at"`
+}
+
+// ModerationDecision is the body of a request of a moderator acting on a report.
+type ModerationDecision struct {
+	Action ModerationKind `json:"action"`
+	Note   string         `json:"note"`
+}
+
+// Prepare checks the kind and trims the note of an action requested by a
+// moderator.
+func (action *ModerationAction) Prepare() error {
+	action.Note = strings.TrimSpace(action.Note)
+
+	if !moderationKinds[action.Kind] {
+		return errors.New("A ação deve ser hide, warn, suspend ou dismiss")
+	}
+	if utf8.RuneCountInString(action.Note) > maxReportDetails {
+		return errors.New("A observação não pode ter mais de 500 caracteres")
+	}
+	return nil
+}
+
+// Warning is a warning of the moderators as seen by the warned user, who
+// does not learn which moderator sent it.
+type Warning struct {
+	ID            uint64    `json:"id"`
+	PublicationID uint64    `json:"publicationId,omitempty"`
+	Note          string    `json:"note,omitempty"`
+	Created_at    time.Time `json:"created_at"`
+}
@@ -0,0 +1,33 @@
+package models
+
+// Role grants a user access to the moderation and administration endpoints.
+type Role string
+
+const (
+	RoleUser      Role = "user"
+	RoleModerator Role = "moderator"
+	RoleAdmin     Role = "admin"
+)
+
+// roleLevels orders the roles: each one can do everything the lower ones can.
+var roleLevels = map[Role]int{
+	RoleUser:      1,
+	RoleModerator: 2,
+	RoleAdmin:     3,
+}
+
+// Valid reports whether the role is one of the known roles.
+func (role Role) Valid() bool {
+	_, known := roleLevels[role]
+	return known
+}
+
+// AtLeast reports whether the role grants everything required grants.
+func (role Role) AtLeast(required Role) bool {
+	return roleLevels[role] >= roleLevels[required]
+}
+
+// RoleAssignment is the body of a request changing the role of a user.
+type RoleAssignment struct {
+	Role Role `json:"role"`
+}
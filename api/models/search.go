@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// SearchQuery describes a full-text search over publications. Only Terms is
+// required; the other fields narrow the results.
+type SearchQuery struct {
+	Terms    string
+	AuthorID uint64
+	Tag      string
+	// From and Until bound the creation date of the publications, From
+	// included and Until excluded.
+	From  time.Time
+	Until time.Time
+	Page  Page
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+	"unicode/utf8"
+)
+
+// Session is a login of a user on a device. The tokens it issued stop working
+// when it is revoked.
+type Session struct {
+	ID         uint64    `json:"id"`
+	UserID     uint64    `json:"-"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"userAgent"`
+	Created_at time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"lastSeenAt"`
+	// Current marks the session of the token used to list the sessions.
+	Current bool `json:"current"`
+}
+
+// Prepare shortens the user agent to fit its column.
+func (session *Session) Prepare() {
+	if utf8.RuneCountInString(session.UserAgent) > maxUserAgentLength {
+		session.UserAgent = string([]rune(session.UserAgent)[:maxUserAgentLength])
+	}
+}
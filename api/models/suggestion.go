@@ -0,0 +1,51 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Suggestion is an account recommended to a user, with the signals behind it.
+type Suggestion struct {
+	User UserSummary `json:"user"`
+	// FollowedBy lists some of the users followed by the viewer who follow
+	// the account; FollowedByCount counts all of them.
+	FollowedBy      []UserSummary `json:"followedBy,omitempty"`
+	FollowedByCount int           `json:"followedByCount"`
+	// MutualFollowers counts the users following both the viewer and the account.
+	MutualFollowers int `json:"mutualFollowers"`
+	// SharedTags are hashtags used by both the viewer and the account.
+	SharedTags []string `json:"sharedTags,omitempty"`
+	Reason     string   `json:"reason"`
+}
+
+// Explain fills Reason from the strongest signal of the suggestion, e.g.
+// "Seguido por ana e bob".
+func (suggestion *Suggestion) Explain() {
+	switch {
+	case suggestion.FollowedByCount > 0 && len(suggestion.FollowedBy) > 0:
+		who := suggestion.FollowedBy[0].Nickname
+		switch {
+		case suggestion.FollowedByCount == 2 && len(suggestion.FollowedBy) > 1:
+			who = fmt.Sprintf("%s e %s", who, suggestion.FollowedBy[1].Nickname)
+		case suggestion.FollowedByCount > 2 && len(suggestion.FollowedBy) > 1:
+			who = fmt.Sprintf("%s, %s e mais %d", who, suggestion.FollowedBy[1].Nickname, suggestion.FollowedByCount-2)
+		case suggestion.FollowedByCount > 1:
+			who = fmt.Sprintf("%s e mais %d", who, suggestion.FollowedByCount-1)
+		}
+		suggestion.Reason = "Seguido por " + who
+	case suggestion.MutualFollowers > 0:
+		suggestion.Reason = fmt.Sprintf("%d %s em comum", suggestion.MutualFollowers,
+			pick(suggestion.MutualFollowers > 1, "seguidores", "seguidor"))
+	case len(suggestion.SharedTags) > 0:
+		tags := make([]string, len(suggestion.SharedTags))
+		for i, tag := range suggestion.SharedTags {
+			tags[i] = "#" + tag
+		}
+		if len(tags) > 1 {
+			suggestion.Reason = "Também publica sobre " + strings.Join(tags[:len(tags)-1], ", ") + " e " + tags[len(tags)-1]
+		} else {
+			suggestion.Reason = "Também publica sobre " + tags[0]
+		}
+	}
+}
@@ -0,0 +1,13 @@
+package models
+
+import "strings"
+
+type Tag struct {
+	Name string `json:"name"`
+	Uses uint64 `json:"uses"`
+}
+
+// NormalizeTag turns "#Golang" or "golang" into the stored form "golang".
+func NormalizeTag(name string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(name), "#"))
+}
@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+// TwoFactor is the TOTP enrollment of a user. It is pending until the user
+// confirms it with a first code.
+type TwoFactor struct {
+	UserID  uint64
+	Secret  string
+	Enabled bool
+	// LastStep is the time step of the last code accepted, which cannot be
+	// used again.
+	LastStep int64
+	// Failures counts the wrong codes entered since the last right one.
+	Failures int
+	FailedAt *time.Time
+	// Challenge is the ID of the challenge of the last two-factor login
+	// started, empty once it was completed.
+	Challenge string
+}
+
+// TwoFactorStatus tells whether two-factor authentication is on for the
+// authenticated user.
+type TwoFactorStatus struct {
+	Enabled bool `json:"enabled"`
+	// RecoveryCodes is how many recovery codes were not used yet.
+	RecoveryCodes int `json:"recoveryCodes"`
+}
+
+// TwoFactorEnrollment is the secret to register in an authenticator app,
+// also as an otpauth URI to be shown as a QR code.
+type TwoFactorEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauthUri"`
+}
+
+// TwoFactorCode is the body of a request proving the possession of the
+// authenticator app, with a TOTP code or, where allowed, a recovery code.
+type TwoFactorCode struct {
+	Code string `json:"code"`
+}
+
+// RecoveryCodes are shown once, when two-factor authentication is enabled.
+// Each one can replace a TOTP code a single time.
+type RecoveryCodes struct {
+	Codes []string `json:"recoveryCodes"`
+}
+
+// TwoFactorChallenge is the answer to the password step of the login of a
+// user with two-factor authentication: ChallengeToken is exchanged for the
+// real token with a code before ExpiresIn seconds.
+type TwoFactorChallenge struct {
+	ChallengeToken string `json:"challengeToken"`
+	ExpiresIn      int    `json:"expiresIn"`
+}
+
+// TwoFactorLogin is the body of the second step of the login.
+type TwoFactorLogin struct {
+	ChallengeToken string `json:"challengeToken"`
+	Code           string `json:"code"`
+}
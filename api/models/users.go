@@ -2,20 +2,77 @@ package models
 
 import (
 	"errors"
+	"fmt"
+	"net/url"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/badoux/checkmail"
 	"github.com/wesleywcr/dev-book/api/security"
 )
 
 type User struct {
-	ID         uint64    `json:"id,omitempty"`
-	Name       string    `json:"name,omitempty"`
-	Nickname   string    `json:"nickname,omitempty"`
-	Email      string    `json:"email,omitempty"`
-	Password   string    `json:"password,omitempty"`
-	Created_at time.Time `json:"created_at,omitempty"`
+	ID       uint64 `json:"id,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Nickname string `json:"nickname,omitempty"`
+	Email    string `json:"email,omitempty"`
+	Password string `json:"password,omitempty"`
+	// IsPrivate accounts only show their publications and followers to
+	// the followers they approved.
+	IsPrivate bool   `json:"isPrivate"`
+	Bio       string `json:"bio"`
+	Location  string `json:"location"`
+	Website   string `json:"website"`
+	// AvatarURL and BannerURL point to the uploaded images; they are
+	// changed through their own endpoints, never by an update.
+	AvatarURL string      `json:"avatarUrl,omitempty"`
+	BannerURL string      `json:"bannerUrl,omitempty"`
+	AvatarKey string      `json:"-"`
+	BannerKey string      `json:"-"`
+	Counts    *UserCounts `json:"counts,omitempty"`
+	// Role and SuspendedAt are managed by administrators, never by an
+	// update of the user.
+	Role        Role       `json:"role,omitempty"`
+	SuspendedAt *time.Time `json:"suspendedAt,omitempty"`
+	Created_at  time.Time  `json:"created_at,omitempty"`
+}
+
+// UserCounts summarizes the activity of a user on their profile.
+type UserCounts struct {
+	Followers    int `json:"followers"`
+	Following    int `json:"following"`
+	Publications int `json:"publications"`
+}
+
+// FollowRequest is a pending request to follow a private account.
+type FollowRequest struct {
+	Follower   UserSummary `json:"follower"`
+	Created_at time.Time   `json:"created_at"`
+}
+
+// Relationship describes how the viewer relates to another user.
+type Relationship struct {
+	UserID uint64 `json:"userId"`
+	// Following and FollowedBy tell whether the viewer follows the user and
+	// the user follows the viewer.
+	Following  bool `json:"following"`
+	FollowedBy bool `json:"followedBy"`
+	// Blocked and Muted tell whether the viewer blocked or muted the user;
+	// BlockedBy whether the user blocked the viewer.
+	Blocked   bool `json:"blocked"`
+	BlockedBy bool `json:"blockedBy"`
+	Muted     bool `json:"muted"`
+	// Requested and RequestedBy tell whether a request to follow is pending
+	// from the viewer to the user or from the user to the viewer.
+	Requested   bool `json:"requested"`
+	RequestedBy bool `json:"requestedBy"`
+}
+
+// UserSummary identifies a user wherever the full profile is not needed.
+type UserSummary struct {
+	ID       uint64 `json:"id"`
+	Nickname string `json:"nickname"`
 }
 
 func (user *User) validate(step string) error {
@@ -37,6 +94,27 @@ func (user *User) validate(step string) error {
 		return errors.New("Senha é um campo obrigatório")
 	}
 
+	for _, field := range []struct {
+		name  string
+		value string
+		limit int
+	}{
+		{"A bio", user.Bio, 160},
+		{"A localização", user.Location, 50},
+		{"O site", user.Website, 100},
+	} {
+		if utf8.RuneCountInString(strings.TrimSpace(field.value)) > field.limit {
+			return fmt.Errorf("%s deve ter no máximo %d caracteres", field.name, field.limit)
+		}
+	}
+
+	if website := strings.TrimSpace(user.Website); website != "" {
+		address, error := url.Parse(website)
+		if error != nil || (address.Scheme != "http" && address.Scheme != "https") || address.Host == "" {
+			return errors.New("O site deve ser um endereço http ou https válido")
+		}
+	}
+
 	return nil
 }
 
@@ -55,6 +133,9 @@ func (user *User) formatted(step string) error {
 	user.Name = strings.TrimSpace(user.Name)
 	user.Nickname = strings.TrimSpace(user.Nickname)
 	user.Email = strings.TrimSpace(user.Email)
+	user.Bio = strings.TrimSpace(user.Bio)
+	user.Location = strings.TrimSpace(user.Location)
+	user.Website = strings.TrimSpace(user.Website)
 
 	if step == "register" {
 		passwordWithHash, error := security.Hash(user.Password)
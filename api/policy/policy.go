@@ -0,0 +1,164 @@
+// Package policy checks the content of publications before they are stored,
+// rejecting them or flagging them to the moderators.
+package policy
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/wesleywcr/dev-book/api/config"
+	"github.com/wesleywcr/dev-book/api/models"
+)
+
+// Action is what a rule decides about a submission.
+type Action int
+
+const (
+	Allow Action = iota
+	// Flag accepts the publication but reports it to the moderators.
+	Flag
+	// Reject refuses to store the publication.
+	Reject
+)
+
+// Verdict is the decision of a rule with its reason, in words the author
+// or the moderators can act on.
+type Verdict struct {
+	Action Action
+	Reason string
+	// Category is the reason of the report filed for a flag.
+	Category models.ReportReason
+}
+
+var allow = Verdict{Action: Allow}
+
+// Submission is a publication being created, when its ID is 0, or edited,
+// along with the publications its author made since Lookback ago, deleted
+// ones included so deleting does not reset the limits.
+type Submission struct {
+	Publication models.Publication
+	Recent      []models.Publication
+	Now         time.Time
+}
+
+// Rule is one check of the pipeline.
+type Rule interface {
+	Check(submission Submission) Verdict
+}
+
+// Rejection is returned when a rule refuses a publication; it lists the
+// reasons of every rule that did.
+type Rejection struct {
+	Reasons []string
+}
+
+func (rejection *Rejection) Error() string {
+	return "Publicação recusada: " + strings.Join(rejection.Reasons, "; ")
+}
+
+// Pipeline runs a sequence of rules over each submission.
+type Pipeline struct {
+	rules    []Rule
+	lookback time.Duration
+}
+
+// Default is the pipeline built by Open from the configuration.
+var Default Pipeline
+
+// Open builds Default from the policy settings.
+func Open() error {
+	pipeline, error := New(config.Settings.Policy)
+	if error != nil {
+		return error
+	}
+	Default = pipeline
+	return nil
+}
+
+// New builds the pipeline enabled by settings, leaving out the rules whose
+// limit is 0 or word list is empty.
+func New(settings config.Policy) (Pipeline, error) {
+	var pipeline Pipeline
+
+	for _, list := range []struct {
+		words  []string
+		action Action
+	}{
+		{settings.BlockedWords, Reject},
+		{settings.FlaggedWords, Flag},
+	} {
+		if strings.TrimSpace(strings.Join(list.words, "")) == "" {
+			continue
+		}
+		rule, error := NewWordList(list.words, list.action)
+		if error != nil {
+			return Pipeline{}, error
+		}
+		pipeline.rules = append(pipeline.rules, rule)
+	}
+
+	if settings.MaxLinks > 0 {
+		pipeline.rules = append(pipeline.rules, LinkLimit{Max: settings.MaxLinks})
+	}
+	if settings.DuplicateWindow > 0 {
+		pipeline.rules = append(pipeline.rules, DuplicateContent{Window: settings.DuplicateWindow})
+		pipeline.lookback = max(pipeline.lookback, settings.DuplicateWindow)
+	}
+	if settings.RateLimit > 0 {
+		pipeline.rules = append(pipeline.rules, RateLimit{Limit: settings.RateLimit, Window: settings.RateWindow})
+		pipeline.lookback = max(pipeline.lookback, settings.RateWindow)
+	}
+	return pipeline, nil
+}
+
+// Lookback is how far back the rules look into the history of the author;
+// 0 means no rule needs it.
+func (pipeline Pipeline) Lookback() time.Duration {
+	return pipeline.lookback
+}
+
+// Evaluate runs every rule over the submission and returns the flags raised,
+// or a *Rejection when any rule refused it.
+func (pipeline Pipeline) Evaluate(submission Submission) ([]Verdict, error) {
+	var flags []Verdict
+	var rejection Rejection
+
+	for _, rule := range pipeline.rules {
+		verdict := rule.Check(submission)
+		switch verdict.Action {
+		case Reject:
+			rejection.Reasons = append(rejection.Reasons, verdict.Reason)
+		case Flag:
+			flags = append(flags, verdict)
+		}
+	}
+
+	if len(rejection.Reasons) > 0 {
+		return nil, &rejection
+	}
+	return flags, nil
+}
+
+// IsRejection reports whether error is a *Rejection.
+func IsRejection(error error) bool {
+	var rejection *Rejection
+	return errors.As(error, &rejection)
+}
+
+// text is what the content rules read: the title and the content.
+func text(publication models.Publication) string {
+	return publication.Title + "\n" + publication.Content
+}
+
+// shortDuration formats a duration as "1h" or "10m" instead of "1h0m0s".
+func shortDuration(duration time.Duration) string {
+	formatted := duration.String()
+	if strings.HasSuffix(formatted, "m0s") {
+		formatted = strings.TrimSuffix(formatted, "0s")
+	}
+	if strings.HasSuffix(formatted, "h0m") {
+		formatted = strings.TrimSuffix(formatted, "0m")
+	}
+	return formatted
+}
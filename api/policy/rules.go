@@ -0,0 +1,145 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/wesleywcr/dev-book/api/models"
+)
+
+// WordList matches a list of words or phrases as whole words, ignoring case
+// and how the words of a phrase are spaced.
+type WordList struct {
+	pattern *regexp.Regexp
+	action  Action
+}
+
+func NewWordList(words []string, action Action) (WordList, error) {
+	var quoted []string
+	for _, word := range words {
+		parts := strings.Fields(word)
+		if len(parts) == 0 {
+			continue
+		}
+		for j := range parts {
+			parts[j] = regexp.QuoteMeta(parts[j])
+		}
+		quoted = append(quoted, strings.Join(parts, `\s+`))
+	}
+	if len(quoted) == 0 {
+		return WordList{}, errors.New("A lista de palavras está vazia")
+	}
+	pattern, error := regexp.Compile(`(?i)(?:^|[^\p{L}\p{N}_])(` + strings.Join(quoted, "|") + `)(?:$|[^\p{L}\p{N}_])`)
+	if error != nil {
+		return WordList{}, error
+	}
+	return WordList{pattern, action}, nil
+}
+
+func (rule WordList) Check(submission Submission) Verdict {
+	match := rule.pattern.FindStringSubmatch(text(submission.Publication))
+	if match == nil {
+		return allow
+	}
+
+	word := strings.ToLower(strings.Join(strings.Fields(match[1]), " "))
+	if rule.action == Reject {
+		return Verdict{Action: Reject, Reason: fmt.Sprintf("Contém o termo não permitido %q", word)}
+	}
+	return Verdict{
+		Action:   Flag,
+		Reason:   fmt.Sprintf("Contém o termo sinalizado %q", word),
+		Category: models.ReasonOther,
+	}
+}
+
+// linkPattern matches the start of each link written as a URL or as "www.".
+var linkPattern = regexp.MustCompile(`(?i)(?:\bhttps?://|\bwww\.)`)
+
+// LinkLimit rejects the publications holding more than Max links.
+type LinkLimit struct {
+	Max int
+}
+
+func (rule LinkLimit) Check(submission Submission) Verdict {
+	links := len(linkPattern.FindAllStringIndex(text(submission.Publication), -1))
+	if links <= rule.Max {
+		return allow
+	}
+	return Verdict{Action: Reject, Reason: fmt.Sprintf("Contém %d links, o máximo é %d", links, rule.Max)}
+}
+
+// DuplicateContent rejects a publication whose content its author already
+// published within Window, ignoring case and spacing.
+type DuplicateContent struct {
+	Window time.Duration
+}
+
+func (rule DuplicateContent) Check(submission Submission) Verdict {
+	content := normalize(submission.Publication.Content)
+	if content == "" {
+		return allow
+	}
+
+	since := submission.Now.Add(-rule.Window)
+	for _, recent := range submission.Recent {
+		if recent.ID == submission.Publication.ID || recent.Created_at.Before(since) {
+			continue
+		}
+		if normalize(recent.Content) == content {
+			return Verdict{
+				Action: Reject,
+				Reason: fmt.Sprintf("Você já publicou este conteúdo nas últimas %s", shortDuration(rule.Window)),
+			}
+		}
+	}
+	return allow
+}
+
+func normalize(content string) string {
+	return strings.Join(strings.Fields(strings.ToLower(content)), " ")
+}
+
+// RateLimit rejects new publications once their author made Limit of them
+// within Window, and flags bursts of half the limit within a tenth of it.
+type RateLimit struct {
+	Limit  int
+	Window time.Duration
+}
+
+func (rule RateLimit) Check(submission Submission) Verdict {
+	if submission.Publication.ID != 0 {
+		return allow
+	}
+
+	burstWindow := rule.Window / 10
+	burstLimit := rule.Limit / 2
+	var total, burst int
+	for _, recent := range submission.Recent {
+		age := submission.Now.Sub(recent.Created_at)
+		if age <= rule.Window {
+			total++
+		}
+		if age <= burstWindow {
+			burst++
+		}
+	}
+
+	switch {
+	case total >= rule.Limit:
+		return Verdict{
+			Action: Reject,
+			Reason: fmt.Sprintf("O limite é de %d publicações a cada %s, tente novamente mais tarde", rule.Limit, shortDuration(rule.Window)),
+		}
+	case burstLimit > 0 && burst >= burstLimit:
+		return Verdict{
+			Action:   Flag,
+			Reason:   fmt.Sprintf("Publicou %d vezes em %s", burst+1, shortDuration(burstWindow)),
+			Category: models.ReasonSpam,
+		}
+	}
+	return allow
+}
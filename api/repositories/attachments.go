@@ -0,0 +1,100 @@
+package repositories
+
+import (
+	"database/sql"
+
+	"github.com/wesleywcr/dev-book/api/db"
+	"github.com/wesleywcr/dev-book/api/models"
+)
+
+type Attachments struct {
+	db *db.DB
+}
+
+func NewRepositoryOfAttachments(db *db.DB) *Attachments {
+	return &Attachments{db}
+}
+
+func (repository Attachments) Create(attachment models.Attachment) (uint64, error) {
+	return repository.db.Insert(`
+	insert into publication_attachments
+	(publication_id, storage_key, thumbnail_key, content_type, size, width, height)
+	values (?, ?, ?, ?, ?, ?, ?)`,
+		attachment.PublicationID, attachment.Key, attachment.ThumbnailKey,
+		attachment.ContentType, attachment.Size, attachment.Width, attachment.Height,
+	)
+}
+
+// SearchPerId returns the attachment, or an empty one when it does not exist.
+func (repository Attachments) SearchPerId(attachmentId uint64) (models.Attachment, error) {
+	rows, error := repository.db.Query(`
+	select id, publication_id, storage_key, thumbnail_key, content_type, size, width, height, created_at
+	from publication_attachments where id = ?`,
+		attachmentId)
+	if error != nil {
+		return models.Attachment{}, error
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		return scanAttachment(rows)
+	}
+	return models.Attachment{}, nil
+}
+
+func (repository Attachments) Count(publicationId uint64) (int, error) {
+	var count int
+	error := repository.db.QueryRow(
+		"select count(*) from publication_attachments where publication_id = ?", publicationId,
+	).Scan(&count)
+	return count, error
+}
+
+// SearchByPublications returns the attachments of each publication, in upload order.
+func (repository Attachments) SearchByPublications(publicationIds []uint64) (map[uint64][]models.Attachment, error) {
+	attachments := map[uint64][]models.Attachment{}
+	if len(publicationIds) == 0 {
+		return attachments, nil
+	}
+
+	rows, error := repository.db.Query(`
+	select id, publication_id, storage_key, thumbnail_key, content_type, size, width, height, created_at
+	from publication_attachments
+	where publication_id in (`+placeholders(len(publicationIds))+`)
+	order by publication_id, id`,
+		uint64Args(publicationIds)...)
+	if error != nil {
+		return nil, error
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		attachment, error := scanAttachment(rows)
+		if error != nil {
+			return nil, error
+		}
+		attachments[attachment.PublicationID] = append(attachments[attachment.PublicationID], attachment)
+	}
+	return attachments, nil
+}
+
+func (repository Attachments) Delete(attachmentId uint64) error {
+	_, error := repository.db.Exec("delete from publication_attachments where id = ?", attachmentId)
+	return error
+}
+
+func scanAttachment(rows *sql.Rows) (models.Attachment, error) {
+	var attachment models.Attachment
+	error := rows.Scan(
+		&attachment.ID,
+		&attachment.PublicationID,
+		&attachment.Key,
+		&attachment.ThumbnailKey,
+		&attachment.ContentType,
+		&attachment.Size,
+		&attachment.Width,
+		&attachment.Height,
+		&attachment.Created_at,
+	)
+	return attachment, error
+}
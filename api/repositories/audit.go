@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"database/sql"
+
+	"github.com/wesleywcr/dev-book/api/db"
+	"github.com/wesleywcr/dev-book/api/models"
+)
+
+// AuditEvents is the audit log. Its rows keep the IDs of the users and
+// publications they name, without foreign keys, so they outlive them.
+type AuditEvents struct {
+	db *db.DB
+}
+
+func NewRepositoryOfAuditEvents(db *db.DB) *AuditEvents {
+	return &AuditEvents{db}
+}
+
+// Create appends an event to the audit log.
+func (repository AuditEvents) Create(event models.AuditEvent) error {
+	_, error := repository.db.Exec(`
+	insert into audit_events (actor_id, action, target_type, target_id, detail, ip, user_agent)
+	values (?, ?, ?, ?, ?, ?, ?)`,
+		nullID(event.ActorID), event.Action, event.TargetType, nullID(event.TargetID),
+		event.Detail, event.IP, event.UserAgent,
+	)
+	return error
+}
+
+// SearchByUser returns the events userId took part in, as the actor or the
+// target, newest first.
+func (repository AuditEvents) SearchByUser(userId uint64, page models.Page) ([]models.AuditEvent, error) {
+	rows, error := repository.db.Query(`
+	select id, actor_id, action, target_type, target_id, detail, ip, user_agent, created_at
+	from audit_events
+	where actor_id = ? or (target_type = ? and target_id = ?)
+	order by id desc
+	limit ? offset ?`,
+		userId, models.AuditTargetUser, userId, page.Limit, page.Offset())
+	if error != nil {
+		return nil, error
+	}
+	defer rows.Close()
+
+	var events []models.AuditEvent
+
+	for rows.Next() {
+		var event models.AuditEvent
+		var actorId, targetId sql.NullInt64
+		if error = rows.Scan(
+			&event.ID,
+			&actorId,
+			&event.Action,
+			&event.TargetType,
+			&targetId,
+			&event.Detail,
+			&event.IP,
+			&event.UserAgent,
+			&event.Created_at,
+		); error != nil {
+			return nil, error
+		}
+
+		event.ActorID = uint64(actorId.Int64)
+		event.TargetID = uint64(targetId.Int64)
+		events = append(events, event)
+	}
+	return events, nil
+}
@@ -0,0 +1,243 @@
+package repositories
+
+import (
+	"database/sql"
+
+	"github.com/wesleywcr/dev-book/api/db"
+	"github.com/wesleywcr/dev-book/api/models"
+)
+
+type Conversations struct {
+	db *db.DB
+}
+
+func NewRepositoryOfConversations(db *db.DB) *Conversations {
+	return &Conversations{db}
+}
+
+// SearchBetween returns the ID of the conversation between two users, or 0
+// when they never talked.
+func (repository Conversations) SearchBetween(userId, otherId uint64) (uint64, error) {
+	var conversationId uint64
+	error := repository.db.QueryRow(`
+	select a.conversation_id
+	from conversation_participants a
+	inner join conversation_participants b on b.conversation_id = a.conversation_id
+	where a.user_id = ? and b.user_id = ?
+	order by a.conversation_id limit 1`,
+		userId, otherId,
+	).Scan(&conversationId)
+	if error == sql.ErrNoRows {
+		return 0, nil
+	}
+	return conversationId, error
+}
+
+// Create starts a conversation between userId and otherId. A pair of users
+// has a single conversation, so when a concurrent request created it first,
+// that one is returned.
+func (repository Conversations) Create(userId, otherId uint64) (uint64, error) {
+	var conversationId uint64
+	error := repository.db.Transaction(func(tx *db.Tx) error {
+		var error error
+		conversationId, error = tx.Insert(
+			"insert into conversations (last_message_id, user_low, user_high) values (null, ?, ?)",
+			min(userId, otherId), max(userId, otherId),
+		)
+		if error != nil {
+			return error
+		}
+
+		for _, participant := range []uint64{userId, otherId} {
+			if _, error = tx.Exec(
+				"insert into conversation_participants (conversation_id, user_id) values (?, ?)",
+				conversationId, participant,
+			); error != nil {
+				return error
+			}
+		}
+		return nil
+	})
+	if error != nil {
+		if existingId, _ := repository.SearchBetween(userId, otherId); existingId != 0 {
+			return existingId, nil
+		}
+		return 0, error
+	}
+	return conversationId, nil
+}
+
+// SearchParticipants returns the IDs of the users taking part in the conversation.
+func (repository Conversations) SearchParticipants(conversationId uint64) ([]uint64, error) {
+	rows, error := repository.db.Query(
+		"select user_id from conversation_participants where conversation_id = ? order by user_id", conversationId,
+	)
+	if error != nil {
+		return nil, error
+	}
+	defer rows.Close()
+
+	var participants []uint64
+
+	for rows.Next() {
+		var userId uint64
+		if error = rows.Scan(&userId); error != nil {
+			return nil, error
+		}
+		participants = append(participants, userId)
+	}
+	return participants, nil
+}
+
+// Search returns the conversations of userId with the other participant, the
+// last message and the unread count, the most recently active first.
+func (repository Conversations) Search(userId uint64, page models.Page) ([]models.Conversation, error) {
+	return repository.search(`
+	where me.user_id = ?
+	order by coalesce(c.last_message_id, 0) desc, c.id desc
+	limit ? offset ?`,
+		userId, page.Limit, page.Offset())
+}
+
+// SearchPerId returns the conversation as seen by userId, or an empty one when
+// they do not take part in it.
+func (repository Conversations) SearchPerId(conversationId, userId uint64) (models.Conversation, error) {
+	conversations, error := repository.search("where me.user_id = ? and c.id = ?", userId, conversationId)
+	if error != nil || len(conversations) == 0 {
+		return models.Conversation{}, error
+	}
+	return conversations[0], nil
+}
+
+// search lists conversations from the point of view of the participant "me",
+// filtered and ordered by the given clauses.
+func (repository Conversations) search(clauses string, args ...any) ([]models.Conversation, error) {
+	rows, error := repository.db.Query(`
+	select c.id, c.created_at, u.id, u.nickname,
+	lm.id, lm.sender_id, lm.content, lm.created_at,
+	(select count(*) from messages m
+		where m.conversation_id = c.id and m.id > me.last_read_message_id and m.sender_id <> me.user_id)
+	from conversation_participants me
+	inner join conversations c on c.id = me.conversation_id
+	inner join conversation_participants other on other.conversation_id = c.id and other.user_id <> me.user_id
+	inner join users u on u.id = other.user_id
+	left join messages lm on lm.id = c.last_message_id
+	`+clauses, args...)
+	if error != nil {
+		return nil, error
+	}
+	defer rows.Close()
+
+	var conversations []models.Conversation
+
+	for rows.Next() {
+		var conversation models.Conversation
+		var messageId, senderId sql.NullInt64
+		var content sql.NullString
+		var sentAt sql.NullTime
+		if error = rows.Scan(
+			&conversation.ID,
+			&conversation.Created_at,
+			&conversation.Participant.ID,
+			&conversation.Participant.Nickname,
+			&messageId,
+			&senderId,
+			&content,
+			&sentAt,
+			&conversation.Unread,
+		); error != nil {
+			return nil, error
+		}
+
+		if messageId.Valid {
+			conversation.LastMessage = &models.Message{
+				ID:             uint64(messageId.Int64),
+				ConversationID: conversation.ID,
+				SenderID:       uint64(senderId.Int64),
+				Content:        content.String,
+				Created_at:     sentAt.Time,
+			}
+		}
+		conversations = append(conversations, conversation)
+	}
+	return conversations, nil
+}
+
+// CreateMessage stores the message and makes it the last one of its
+// conversation, already read by its sender, in a transaction. The last
+// message only moves forward, so concurrent sends cannot set an older one.
+func (repository Conversations) CreateMessage(message models.Message) (models.Message, error) {
+	error := repository.db.Transaction(func(tx *db.Tx) error {
+		ID, error := tx.Insert(
+			"insert into messages (conversation_id, sender_id, content) values (?, ?, ?)",
+			message.ConversationID, message.SenderID, message.Content,
+		)
+		if error != nil {
+			return error
+		}
+
+		if _, error = tx.Exec(`
+		update conversations set last_message_id = ?
+		where id = ? and (last_message_id is null or last_message_id < ?)`,
+			ID, message.ConversationID, ID,
+		); error != nil {
+			return error
+		}
+		if _, error = tx.Exec(`
+		update conversation_participants set last_read_message_id = ?
+		where conversation_id = ? and user_id = ? and last_read_message_id < ?`,
+			ID, message.ConversationID, message.SenderID, ID,
+		); error != nil {
+			return error
+		}
+
+		message.ID = ID
+		return tx.QueryRow("select created_at from messages where id = ?", ID).Scan(&message.Created_at)
+	})
+	if error != nil {
+		return models.Message{}, error
+	}
+	return message, nil
+}
+
+// SearchMessages returns the messages of the conversation, newest first.
+func (repository Conversations) SearchMessages(conversationId uint64, page models.Page) ([]models.Message, error) {
+	rows, error := repository.db.Query(`
+	select id, conversation_id, sender_id, content, created_at
+	from messages where conversation_id = ?
+	order by id desc
+	limit ? offset ?`,
+		conversationId, page.Limit, page.Offset())
+	if error != nil {
+		return nil, error
+	}
+	defer rows.Close()
+
+	var messages []models.Message
+
+	for rows.Next() {
+		var message models.Message
+		if error = rows.Scan(
+			&message.ID,
+			&message.ConversationID,
+			&message.SenderID,
+			&message.Content,
+			&message.Created_at,
+		); error != nil {
+			return nil, error
+		}
+		messages = append(messages, message)
+	}
+	return messages, nil
+}
+
+// MarkRead records that userId read the conversation up to messageId. The
+// marker never moves backwards.
+func (repository Conversations) MarkRead(conversationId, userId, messageId uint64) error {
+	_, error := repository.db.Exec(`
+	update conversation_participants set last_read_message_id = ?
+	where conversation_id = ? and user_id = ? and last_read_message_id < ?`,
+		messageId, conversationId, userId, messageId,
+	)
+	return error
+}
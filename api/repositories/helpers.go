@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"database/sql"
+	"strings"
+)
+
+// placeholders returns "?, ?, ?" with n placeholders, for "in (...)" clauses.
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+func uint64Args(values []uint64) []any {
+	args := make([]any, len(values))
+	for i, value := range values {
+		args[i] = value
+	}
+	return args
+}
+
+func stringArgs(values []string) []any {
+	args := make([]any, len(values))
+	for i, value := range values {
+		args[i] = value
+	}
+	return args
+}
+
+// nullString stores an empty string as null.
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+// nullID stores an ID of 0 as null.
+func nullID(value uint64) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(value), Valid: value != 0}
+}
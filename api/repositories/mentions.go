@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"github.com/wesleywcr/dev-book/api/db"
+	"github.com/wesleywcr/dev-book/api/models"
+)
+
+type Mentions struct {
+	db *db.DB
+}
+
+func NewRepositoryOfMentions(db *db.DB) *Mentions {
+	return &Mentions{db}
+}
+
+// Replace makes mentions the exact set of mentions of the publication.
+func (repository Mentions) Replace(publicationId uint64, mentions []models.Mention) error {
+	if _, error := repository.db.Exec("delete from publication_mentions where publication_id = ?", publicationId); error != nil {
+		return error
+	}
+
+	for _, mention := range mentions {
+		if _, error := repository.db.Exec(
+			"insert into publication_mentions (publication_id, user_id, start_offset, end_offset) values (?, ?, ?, ?)",
+			publicationId, mention.UserID, mention.Start, mention.End,
+		); error != nil {
+			return error
+		}
+	}
+	return nil
+}
+
+// SearchByPublications returns the mentions of each publication, in content order.
+func (repository Mentions) SearchByPublications(publicationIds []uint64) (map[uint64][]models.Mention, error) {
+	mentions := map[uint64][]models.Mention{}
+	if len(publicationIds) == 0 {
+		return mentions, nil
+	}
+
+	rows, error := repository.db.Query(`
+	select m.publication_id, m.user_id, u.nickname, m.start_offset, m.end_offset
+	from publication_mentions m inner join users u on u.id = m.user_id
+	where m.publication_id in (`+placeholders(len(publicationIds))+`)
+	order by m.publication_id, m.start_offset`,
+		uint64Args(publicationIds)...)
+	if error != nil {
+		return nil, error
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var publicationId uint64
+		var mention models.Mention
+		if error = rows.Scan(
+			&publicationId,
+			&mention.UserID,
+			&mention.Nickname,
+			&mention.Start,
+			&mention.End,
+		); error != nil {
+			return nil, error
+		}
+		mentions[publicationId] = append(mentions[publicationId], mention)
+	}
+	return mentions, nil
+}
@@ -0,0 +1,146 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/wesleywcr/dev-book/api/db"
+	"github.com/wesleywcr/dev-book/api/models"
+)
+
+// actorsPerNotification is how many actors are listed in an aggregated notification.
+const actorsPerNotification = 3
+
+type Notifications struct {
+	db *db.DB
+}
+
+func NewRepositoryOfNotifications(db *db.DB) *Notifications {
+	return &Notifications{db}
+}
+
+// Create stores the event, ignoring it when the same event was already stored
+// within the models.NotificationDedupWindow.
+func (repository Notifications) Create(event models.NotificationEvent) error {
+	var publicationId sql.NullInt64
+	if event.PublicationID != 0 {
+		publicationId = sql.NullInt64{Int64: int64(event.PublicationID), Valid: true}
+	}
+
+	_, error := repository.db.Exec(
+		repository.db.Dialect.InsertIgnore("notifications (user_id, actor_id, kind, publication_id, dedup_key) values (?, ?, ?, ?, ?)"),
+		event.UserID, event.ActorID, event.Kind, publicationId, event.DedupKey(time.Now()),
+	)
+	return error
+}
+
+// Search returns the notifications of userId aggregated by kind, publication
+// and read state, the most recently updated first.
+func (repository Notifications) Search(userId uint64, page models.Page) ([]models.Notification, error) {
+	rows, error := repository.db.Query(`
+	select kind, publication_id, case when read_at is null then 0 else 1 end, count(distinct actor_id)
+	from notifications where user_id = ?
+	group by kind, publication_id, case when read_at is null then 0 else 1 end
+	order by max(id) desc
+	limit ? offset ?`,
+		userId, page.Limit, page.Offset())
+	if error != nil {
+		return nil, error
+	}
+	defer rows.Close()
+
+	var notifications []models.Notification
+
+	for rows.Next() {
+		var notification models.Notification
+		var publicationId sql.NullInt64
+		if error = rows.Scan(
+			&notification.Kind,
+			&publicationId,
+			&notification.Read,
+			&notification.ActorsCount,
+		); error != nil {
+			return nil, error
+		}
+		notification.PublicationID = uint64(publicationId.Int64)
+		notifications = append(notifications, notification)
+	}
+	rows.Close()
+
+	for i := range notifications {
+		if error = repository.searchActors(userId, &notifications[i]); error != nil {
+			return nil, error
+		}
+		notifications[i].Describe()
+	}
+	return notifications, nil
+}
+
+// searchActors fills the most recent actors of an aggregated notification,
+// each once, and its Created_at, the time of its latest event.
+func (repository Notifications) searchActors(userId uint64, notification *models.Notification) error {
+	filter := "n.user_id = ? and n.kind = ?"
+	args := []any{userId, notification.Kind}
+
+	if notification.PublicationID == 0 {
+		filter += " and n.publication_id is null"
+	} else {
+		filter += " and n.publication_id = ?"
+		args = append(args, notification.PublicationID)
+	}
+	if notification.Read {
+		filter += " and n.read_at is not null"
+	} else {
+		filter += " and n.read_at is null"
+	}
+
+	// the latest event of each actor
+	query := `
+	select u.id, u.nickname, n.created_at
+	from notifications n inner join users u on u.id = n.actor_id
+	where n.id in (select max(n.id) from notifications n where ` + filter + ` group by n.actor_id)
+	order by n.id desc limit ?`
+	args = append(args, actorsPerNotification)
+
+	rows, error := repository.db.Query(query, args...)
+	if error != nil {
+		return error
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var actor models.UserSummary
+		var createdAt time.Time
+		if error = rows.Scan(&actor.ID, &actor.Nickname, &createdAt); error != nil {
+			return error
+		}
+		if notification.Created_at.IsZero() {
+			notification.Created_at = createdAt
+		}
+		notification.Actors = append(notification.Actors, actor)
+	}
+	return nil
+}
+
+func (repository Notifications) CountUnread(userId uint64) (uint64, error) {
+	var unread uint64
+	error := repository.db.QueryRow(
+		"select count(*) from notifications where user_id = ? and read_at is null", userId,
+	).Scan(&unread)
+	return unread, error
+}
+
+func (repository Notifications) MarkRead(userId uint64) error {
+	statement, error := repository.db.Prepare(
+		"update notifications set read_at = current_timestamp where user_id = ? and read_at is null",
+	)
+	if error != nil {
+		return error
+	}
+	defer statement.Close()
+
+	if _, error = statement.Exec(userId); error != nil {
+		return error
+	}
+	return nil
+}
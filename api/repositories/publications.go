@@ -2,44 +2,56 @@ package repositories
 
 import (
 	"database/sql"
+	"time"
 
+	"github.com/wesleywcr/dev-book/api/db"
 	"github.com/wesleywcr/dev-book/api/models"
 )
 
+// publicationColumns are the columns read by scanPublication, from the
+// publications p joined with their authors u.
+const publicationColumns = `p.id, p.title, p.content, p.author_id, p.likes, p.created_at, p.updated_at,
+	p.original_id, (select count(*) from publications r where r.original_id = p.id and r.deleted_at is null),
+	u.nickname`
+
+// visibleTo restricts the publications p of the authors u to those the viewer
+// can see: their own, and those of the public accounts and of the private
+// ones they follow, unless either of them blocked the other. Its arguments
+// are visibleArgs(viewerId).
+const visibleTo = `(u.id = ? or (
+	(u.is_private = ? or exists (select 1 from followers f where f.user_id = u.id and f.follower_id = ?))
+	and not exists (select 1 from user_blocks b
+		where (b.blocker_id = u.id and b.blocked_id = ?) or (b.blocker_id = ? and b.blocked_id = u.id))))`
+
+func visibleArgs(viewerId uint64) []any {
+	return []any{viewerId, false, viewerId, viewerId, viewerId}
+}
+
 type Publications struct {
-	db *sql.DB
+	db *db.DB
 }
 
-func NewRepositoryOfPublications(db *sql.DB) *Publications {
+func NewRepositoryOfPublications(db *db.DB) *Publications {
 	return &Publications{db}
 }
 
 func (repository Publications) Create(publications models.Publication) (uint64, error) {
-	statement, error := repository.db.Prepare(
-		"insert into publications (title, content, author_id) values (?, ?, ?)",
-	)
-	if error != nil {
-		return 0, error
-	}
-
-	result, error := statement.Exec(publications.Title, publications.Content, publications.AuthorID)
-	if error != nil {
-		return 0, error
+	var originalId sql.NullInt64
+	if publications.OriginalID != 0 {
+		originalId = sql.NullInt64{Int64: int64(publications.OriginalID), Valid: true}
 	}
-	lastIdInsert, error := result.LastInsertId()
 
-	if error != nil {
-		return 0, error
-	}
-
-	return uint64(lastIdInsert), nil
+	return repository.db.Insert(
+		"insert into publications (title, content, author_id, original_id) values (?, ?, ?, ?)",
+		publications.Title, publications.Content, publications.AuthorID, originalId,
+	)
 }
 
 func (repository Publications) SearchPublicationsById(publicationId uint64) (models.Publication, error) {
 	row, error := repository.db.Query(`
-	select p.*, u.nickname from 
+	select `+publicationColumns+` from
 	publications p inner join users u
-	on u.id = p.author_id where p.id = ?
+	on u.id = p.author_id where p.id = ? and p.deleted_at is null
 	`, publicationId)
 	if error != nil {
 		return models.Publication{}, error
@@ -47,32 +59,80 @@ func (repository Publications) SearchPublicationsById(publicationId uint64) (mod
 
 	defer row.Close()
 
-	var publication models.Publication
-
 	if row.Next() {
-		if error = row.Scan(
-			&publication.ID,
-			&publication.Title,
-			&publication.Content,
-			&publication.AuthorID,
-			&publication.Likes,
-			&publication.Created_at,
-			&publication.AuthorNickaname,
+		return scanPublication(row)
+	}
+	return models.Publication{}, nil
+}
+
+// Update keeps the current title and content as a revision and replaces them,
+// in a transaction so concurrent edits each keep the version they replaced.
+func (repository Publications) Update(publicationId uint64, publication models.Publication) error {
+	return repository.db.Transaction(func(tx *db.Tx) error {
+		// locks the publication until the edit is committed
+		if _, error := tx.Exec(
+			"update publications set updated_at = updated_at where id = ? and deleted_at is null", publicationId,
 		); error != nil {
-			return models.Publication{}, error
+			return error
 		}
+
+		if _, error := tx.Exec(`
+		insert into publication_revisions (publication_id, title, content)
+		select id, title, content from publications where id = ? and deleted_at is null`,
+			publicationId,
+		); error != nil {
+			return error
+		}
+
+		_, error := tx.Exec(
+			"update publications set title = ?, content = ?, updated_at = current_timestamp where id = ? and deleted_at is null",
+			publication.Title, publication.Content, publicationId,
+		)
+		return error
+	})
+}
+
+// Delete hides the publication; it can be restored until it is purged.
+func (repository Publications) Delete(publicationId uint64) error {
+	statement, error := repository.db.Prepare(
+		"update publications set deleted_at = current_timestamp where id = ? and deleted_at is null",
+	)
+	if error != nil {
+		return error
 	}
-	return publication, nil
+	defer statement.Close()
+	if _, error := statement.Exec(publicationId); error != nil {
+		return error
+	}
+	return nil
+}
+
+// SearchDeleted returns the author of a deleted publication and when it was
+// deleted; the ID is 0 when there is no such deleted publication. Publications
+// hidden by the moderators are not returned, so their authors cannot restore
+// them.
+func (repository Publications) SearchDeleted(publicationId uint64) (models.Publication, time.Time, error) {
+	var publication models.Publication
+	var deletedAt time.Time
+	error := repository.db.QueryRow(
+		"select id, author_id, deleted_at from publications where id = ? and deleted_at is not null and hidden_at is null",
+		publicationId,
+	).Scan(&publication.ID, &publication.AuthorID, &deletedAt)
+	if error == sql.ErrNoRows {
+		return models.Publication{}, time.Time{}, nil
+	}
+	return publication, deletedAt, error
 }
 
-func (repository Publications) SearchPublications(userID uint64) ([]models.Publication, error) {
+// SearchRecent returns the publications authorId made since since, deleted
+// ones included and plain reposts left out, newest first.
+func (repository Publications) SearchRecent(authorId uint64, since time.Time) ([]models.Publication, error) {
 	rows, error := repository.db.Query(`
-	select distinct p.*, u.nickname from publications p
-	inner join users u on u.id = p.author_id
-	inner join followers s on p.author_id = s.user_id
-	where u.id = ? or s.follower_id = ?
-	order by 1 desc`,
-		userID, userID)
+	select id, content, created_at from publications
+	where author_id = ? and created_at >= ?
+	and not (original_id is not null and content = '')
+	order by id desc`,
+		authorId, since)
 	if error != nil {
 		return nil, error
 	}
@@ -80,18 +140,9 @@ func (repository Publications) SearchPublications(userID uint64) ([]models.Publi
 
 	var publications []models.Publication
 
-	if rows.Next() {
-		var publication models.Publication
-
-		if error = rows.Scan(
-			&publication.ID,
-			&publication.Title,
-			&publication.Content,
-			&publication.AuthorID,
-			&publication.Likes,
-			&publication.Created_at,
-			&publication.AuthorNickaname,
-		); error != nil {
+	for rows.Next() {
+		publication := models.Publication{AuthorID: authorId}
+		if error = rows.Scan(&publication.ID, &publication.Content, &publication.Created_at); error != nil {
 			return nil, error
 		}
 		publications = append(publications, publication)
@@ -99,53 +150,128 @@ func (repository Publications) SearchPublications(userID uint64) ([]models.Publi
 	return publications, nil
 }
 
-func (repository Publications) Update(publicationId uint64, publication models.Publication) error {
-	statement, error := repository.db.Prepare("update publications set title = ?, content = ? where id = ?")
+// Hide removes the publication from every listing on behalf of the
+// moderators. Unlike Delete, its author cannot restore it.
+func (repository Publications) Hide(publicationId uint64) error {
+	_, error := repository.db.Exec(`
+	update publications set hidden_at = current_timestamp, deleted_at = coalesce(deleted_at, current_timestamp)
+	where id = ? and hidden_at is null`,
+		publicationId,
+	)
+	return error
+}
+
+func (repository Publications) Restore(publicationId uint64) error {
+	_, error := repository.db.Exec("update publications set deleted_at = null where id = ?", publicationId)
+	return error
+}
+
+// SearchRevisions returns the previous versions of the publication, newest first.
+func (repository Publications) SearchRevisions(publicationId uint64) ([]models.PublicationRevision, error) {
+	rows, error := repository.db.Query(`
+	select id, publication_id, title, content, created_at
+	from publication_revisions where publication_id = ?
+	order by id desc`,
+		publicationId)
 	if error != nil {
-		return error
+		return nil, error
 	}
-	defer statement.Close()
+	defer rows.Close()
 
-	if _, error := statement.Exec(publication.Title, publication.Content, publicationId); error != nil {
-		return error
+	var revisions []models.PublicationRevision
+
+	for rows.Next() {
+		var revision models.PublicationRevision
+		if error = rows.Scan(
+			&revision.ID,
+			&revision.PublicationID,
+			&revision.Title,
+			&revision.Content,
+			&revision.Created_at,
+		); error != nil {
+			return nil, error
+		}
+		revisions = append(revisions, revision)
 	}
-	return nil
+	return revisions, nil
 }
 
-func (repository Publications) Delete(publicationId uint64) error {
-	statement, error := repository.db.Prepare("delete from publications where id = ? ")
+func (repository Publications) SearchPublicationByUserId(userId uint64) ([]models.Publication, error) {
+	rows, error := repository.db.Query(`
+		select `+publicationColumns+` from publications p
+		join users u on u.id = p.author_id
+		where p.author_id = ? and p.deleted_at is null
+		order by p.id desc`, userId)
 	if error != nil {
-		return error
+		return nil, error
 	}
-	defer statement.Close()
-	if _, error := statement.Exec(publicationId); error != nil {
-		return error
+	defer rows.Close()
+	var publications []models.Publication
+
+	for rows.Next() {
+		publication, error := scanPublication(rows)
+		if error != nil {
+			return nil, error
+		}
+		publications = append(publications, publication)
 	}
-	return nil
+	return publications, nil
 }
-func (repository Publications) SearchPublicationByUserId(userId uint64) ([]models.Publication, error) {
+
+// SearchPublicationsByIds returns the publications among publicationIds
+// that were not deleted, in no particular order.
+func (repository Publications) SearchPublicationsByIds(publicationIds []uint64) ([]models.Publication, error) {
+	if len(publicationIds) == 0 {
+		return nil, nil
+	}
+
 	rows, error := repository.db.Query(`
-		select p.*, u.nickname from publications p
-		join users u on u.id = p.author_id 
-		where p.author_id = ?`, userId)
+	select `+publicationColumns+` from publications p
+	inner join users u on u.id = p.author_id
+	where p.id in (`+placeholders(len(publicationIds))+`) and p.deleted_at is null`,
+		uint64Args(publicationIds)...)
 	if error != nil {
 		return nil, error
 	}
 	defer rows.Close()
+
 	var publications []models.Publication
 
-	if rows.Next() {
-		var publication models.Publication
+	for rows.Next() {
+		publication, error := scanPublication(rows)
+		if error != nil {
+			return nil, error
+		}
+		publications = append(publications, publication)
+	}
+	return publications, nil
+}
+
+// SearchVisiblePublicationsByIds returns the publications among
+// publicationIds that were not deleted and that viewerId can see and did not
+// mute, in no particular order.
+func (repository Publications) SearchVisiblePublicationsByIds(viewerId uint64, publicationIds []uint64) ([]models.Publication, error) {
+	if len(publicationIds) == 0 {
+		return nil, nil
+	}
 
-		if error = rows.Scan(
-			&publication.ID,
-			&publication.Title,
-			&publication.Content,
-			&publication.AuthorID,
-			&publication.Likes,
-			&publication.Created_at,
-			&publication.AuthorNickaname,
-		); error != nil {
+	args := append(uint64Args(publicationIds), visibleArgs(viewerId)...)
+	rows, error := repository.db.Query(`
+	select `+publicationColumns+` from publications p
+	inner join users u on u.id = p.author_id
+	where p.id in (`+placeholders(len(publicationIds))+`) and p.deleted_at is null and `+visibleTo+`
+	and p.author_id not in (select muted_id from user_mutes where muter_id = ?)`,
+		append(args, viewerId)...)
+	if error != nil {
+		return nil, error
+	}
+	defer rows.Close()
+
+	var publications []models.Publication
+
+	for rows.Next() {
+		publication, error := scanPublication(rows)
+		if error != nil {
 			return nil, error
 		}
 		publications = append(publications, publication)
@@ -153,8 +279,29 @@ func (repository Publications) SearchPublicationByUserId(userId uint64) ([]model
 	return publications, nil
 }
 
+// SearchRepost returns the ID of the plain repost of originalId by userId, or
+// 0 when they did not repost it.
+func (repository Publications) SearchRepost(userId, originalId uint64) (uint64, error) {
+	var repostId uint64
+	error := repository.db.QueryRow(`
+	select id from publications
+	where author_id = ? and original_id = ? and content = '' and deleted_at is null`,
+		userId, originalId,
+	).Scan(&repostId)
+	if error == sql.ErrNoRows {
+		return 0, nil
+	}
+	return repostId, error
+}
+
+// DeleteRepost removes a plain repost for good.
+func (repository Publications) DeleteRepost(repostId uint64) error {
+	_, error := repository.db.Exec("delete from publications where id = ? and content = ''", repostId)
+	return error
+}
+
 func (repository Publications) Like(publicationId uint64) error {
-	statement, error := repository.db.Prepare(`update publications set likes = likes + 1 where id = ?`)
+	statement, error := repository.db.Prepare(`update publications set likes = likes + 1 where id = ? and deleted_at is null`)
 	if error != nil {
 		return error
 	}
@@ -170,9 +317,9 @@ func (repository Publications) Deslike(publicationId uint64) error {
 	update publications set likes = 
 	CASE 
 		WHEN likes > 0 THEN likes - 1
-		ELSE 0 
-	END 
-	where id = ?
+		ELSE 0
+	END
+	where id = ? and deleted_at is null
 `)
 	if error != nil {
 		return error
@@ -183,3 +330,104 @@ func (repository Publications) Deslike(publicationId uint64) error {
 	}
 	return nil
 }
+
+// SearchPublicationsByTag returns the publications using tag that viewerId
+// can see, newest first.
+func (repository Publications) SearchPublicationsByTag(viewerId uint64, tag string, page models.Page) ([]models.Publication, error) {
+	args := append([]any{tag}, visibleArgs(viewerId)...)
+	rows, error := repository.db.Query(`
+	select `+publicationColumns+`
+	from publications p
+	inner join users u on u.id = p.author_id
+	inner join publication_tags pt on pt.publication_id = p.id
+	inner join tags t on t.id = pt.tag_id
+	where t.name = ? and p.deleted_at is null and `+visibleTo+`
+	order by p.id desc
+	limit ? offset ?`,
+		append(args, page.Limit, page.Offset())...)
+	if error != nil {
+		return nil, error
+	}
+	defer rows.Close()
+
+	var publications []models.Publication
+
+	for rows.Next() {
+		publication, error := scanPublication(rows)
+		if error != nil {
+			return nil, error
+		}
+		publications = append(publications, publication)
+	}
+	return publications, nil
+}
+
+// SearchPublicationsMentioning returns the publications mentioning userId
+// that they can see, newest first.
+func (repository Publications) SearchPublicationsMentioning(userId uint64, page models.Page) ([]models.Publication, error) {
+	args := append([]any{userId}, visibleArgs(userId)...)
+	rows, error := repository.db.Query(`
+	select distinct `+publicationColumns+`
+	from publications p
+	inner join users u on u.id = p.author_id
+	inner join publication_mentions m on m.publication_id = p.id
+	where m.user_id = ? and p.deleted_at is null and `+visibleTo+`
+	order by p.id desc
+	limit ? offset ?`,
+		append(args, page.Limit, page.Offset())...)
+	if error != nil {
+		return nil, error
+	}
+	defer rows.Close()
+
+	var publications []models.Publication
+
+	for rows.Next() {
+		publication, error := scanPublication(rows)
+		if error != nil {
+			return nil, error
+		}
+		publications = append(publications, publication)
+	}
+	return publications, nil
+}
+
+func scanPublications(rows *sql.Rows) ([]models.Publication, error) {
+	var publications []models.Publication
+
+	for rows.Next() {
+		publication, error := scanPublication(rows)
+		if error != nil {
+			return nil, error
+		}
+		publications = append(publications, publication)
+	}
+	return publications, nil
+}
+
+func scanPublication(rows *sql.Rows) (models.Publication, error) {
+	var publication models.Publication
+	var updatedAt sql.NullTime
+	var originalId sql.NullInt64
+
+	if error := rows.Scan(
+		&publication.ID,
+		&publication.Title,
+		&publication.Content,
+		&publication.AuthorID,
+		&publication.Likes,
+		&publication.Created_at,
+		&updatedAt,
+		&originalId,
+		&publication.Reposts,
+		&publication.AuthorNickaname,
+	); error != nil {
+		return models.Publication{}, error
+	}
+
+	if updatedAt.Valid {
+		publication.Updated_at = &updatedAt.Time
+	}
+	publication.OriginalID = uint64(originalId.Int64)
+	return publication, nil
+}
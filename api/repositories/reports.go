@@ -0,0 +1,245 @@
+package repositories
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/wesleywcr/dev-book/api/db"
+	"github.com/wesleywcr/dev-book/api/models"
+)
+
+type Reports struct {
+	db *db.DB
+}
+
+func NewRepositoryOfReports(db *db.DB) *Reports {
+	return &Reports{db}
+}
+
+// Create stores a new open report. Automatic reports are stored with the
+// reported user as reporterId, since reporter_id cannot be null.
+func (repository Reports) Create(reporterId uint64, report models.Report) (uint64, error) {
+	return repository.db.Insert(`
+	insert into reports (reporter_id, user_id, publication_id, reason, details, automatic)
+	values (?, ?, ?, ?, ?, ?)`,
+		reporterId, report.User.ID, nullID(report.PublicationID), report.Reason, report.Details, report.Automatic,
+	)
+}
+
+// SearchOpen returns the ID of the open report of reporterId about the same
+// publication, or user when publicationId is 0, or 0 when there is none.
+func (repository Reports) SearchOpen(reporterId, userId, publicationId uint64) (uint64, error) {
+	var reportId uint64
+	error := repository.db.QueryRow(`
+	select id from reports
+	where reporter_id = ? and user_id = ? and coalesce(publication_id, 0) = ? and status = 'open'
+	order by id limit 1`,
+		reporterId, userId, publicationId,
+	).Scan(&reportId)
+	if error == sql.ErrNoRows {
+		return 0, nil
+	}
+	return reportId, error
+}
+
+// SearchPerId returns the report, or an empty one when it does not exist.
+func (repository Reports) SearchPerId(reportId uint64) (models.Report, error) {
+	reports, error := repository.search("where r.id = ?", reportId)
+	if error != nil || len(reports) == 0 {
+		return models.Report{}, error
+	}
+	return reports[0], nil
+}
+
+// Search returns the reports matching the filter, oldest first, so the queue
+// is worked in the order it was filled.
+func (repository Reports) Search(filter models.ReportFilter, page models.Page) ([]models.Report, error) {
+	var clauses []string
+	var args []any
+	if filter.Status != "" {
+		clauses = append(clauses, "r.status = ?")
+		args = append(args, filter.Status)
+	}
+	if filter.Reason != "" {
+		clauses = append(clauses, "r.reason = ?")
+		args = append(args, filter.Reason)
+	}
+	switch filter.Target {
+	case "publication":
+		clauses = append(clauses, "r.publication_id is not null")
+	case "user":
+		clauses = append(clauses, "r.publication_id is null")
+	}
+	if filter.UserID != 0 {
+		clauses = append(clauses, "r.user_id = ?")
+		args = append(args, filter.UserID)
+	}
+	if filter.Automatic != nil {
+		clauses = append(clauses, "r.automatic = ?")
+		args = append(args, *filter.Automatic)
+	}
+
+	where := ""
+	if len(clauses) > 0 {
+		where = "where " + strings.Join(clauses, " and ")
+	}
+	args = append(args, page.Limit, page.Offset())
+	return repository.search(where+" order by r.id limit ? offset ?", args...)
+}
+
+func (repository Reports) search(clauses string, args ...any) ([]models.Report, error) {
+	rows, error := repository.db.Query(`
+	select r.id, reporter.id, reporter.nickname, reported.id, reported.nickname,
+	r.publication_id, r.reason, r.details, r.status, r.resolved_by, r.resolved_at, r.created_at, r.automatic
+	from reports r
+	inner join users reporter on reporter.id = r.reporter_id
+	inner join users reported on reported.id = r.user_id
+	`+clauses, args...)
+	if error != nil {
+		return nil, error
+	}
+	defer rows.Close()
+
+	var reports []models.Report
+
+	for rows.Next() {
+		var report models.Report
+		var reporter models.UserSummary
+		var publicationId, resolvedBy sql.NullInt64
+		var resolvedAt sql.NullTime
+		if error = rows.Scan(
+			&report.ID,
+			&reporter.ID,
+			&reporter.Nickname,
+			&report.User.ID,
+			&report.User.Nickname,
+			&publicationId,
+			&report.Reason,
+			&report.Details,
+			&report.Status,
+			&resolvedBy,
+			&resolvedAt,
+			&report.Created_at,
+			&report.Automatic,
+		); error != nil {
+			return nil, error
+		}
+
+		if !report.Automatic {
+			report.Reporter = &reporter
+		}
+		report.PublicationID = uint64(publicationId.Int64)
+		report.ResolvedBy = uint64(resolvedBy.Int64)
+		if resolvedAt.Valid {
+			report.ResolvedAt = &resolvedAt.Time
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// Resolve closes the open reports about the same publication, or user when
+// publicationId is 0, as the report the moderator acted on.
+func (repository Reports) Resolve(userId, publicationId uint64, status models.ReportStatus, moderatorId uint64) error {
+	_, error := repository.db.Exec(`
+	update reports set status = ?, resolved_by = ?, resolved_at = current_timestamp
+	where user_id = ? and coalesce(publication_id, 0) = ? and status = 'open'`,
+		status, moderatorId, userId, publicationId,
+	)
+	return error
+}
+
+// CreateAction appends an action to the audit log of the moderators.
+func (repository Reports) CreateAction(action models.ModerationAction) (models.ModerationAction, error) {
+	ID, error := repository.db.Insert(`
+	insert into moderation_actions (moderator_id, action, report_id, user_id, publication_id, note)
+	values (?, ?, ?, ?, ?, ?)`,
+		nullID(action.ModeratorID), action.Kind, nullID(action.ReportID),
+		action.UserID, nullID(action.PublicationID), action.Note,
+	)
+	if error != nil {
+		return models.ModerationAction{}, error
+	}
+
+	if error = repository.db.QueryRow(
+		"select created_at from moderation_actions where id = ?", ID,
+	).Scan(&action.Created_at); error != nil {
+		return models.ModerationAction{}, error
+	}
+	action.ID = ID
+	return action, nil
+}
+
+// SearchActions returns the audit log, newest first, only about userId when
+// it is not 0.
+func (repository Reports) SearchActions(userId uint64, page models.Page) ([]models.ModerationAction, error) {
+	where := ""
+	args := []any{page.Limit, page.Offset()}
+	if userId != 0 {
+		where = "where user_id = ?"
+		args = append([]any{userId}, args...)
+	}
+
+	rows, error := repository.db.Query(`
+	select id, moderator_id, action, report_id, user_id, publication_id, note, created_at
+	from moderation_actions `+where+`
+	order by id desc
+	limit ? offset ?`, args...)
+	if error != nil {
+		return nil, error
+	}
+	defer rows.Close()
+
+	var actions []models.ModerationAction
+
+	for rows.Next() {
+		var action models.ModerationAction
+		var moderatorId, reportId, publicationId sql.NullInt64
+		if error = rows.Scan(
+			&action.ID,
+			&moderatorId,
+			&action.Kind,
+			&reportId,
+			&action.UserID,
+			&publicationId,
+			&action.Note,
+			&action.Created_at,
+		); error != nil {
+			return nil, error
+		}
+
+		action.ModeratorID = uint64(moderatorId.Int64)
+		action.ReportID = uint64(reportId.Int64)
+		action.PublicationID = uint64(publicationId.Int64)
+		actions = append(actions, action)
+	}
+	return actions, nil
+}
+
+// SearchWarnings returns the warnings userId received, newest first.
+func (repository Reports) SearchWarnings(userId uint64, page models.Page) ([]models.Warning, error) {
+	rows, error := repository.db.Query(`
+	select id, publication_id, note, created_at
+	from moderation_actions
+	where user_id = ? and action = ?
+	order by id desc
+	limit ? offset ?`,
+		userId, models.ModerationWarn, page.Limit, page.Offset())
+	if error != nil {
+		return nil, error
+	}
+	defer rows.Close()
+
+	var warnings []models.Warning
+
+	for rows.Next() {
+		var warning models.Warning
+		var publicationId sql.NullInt64
+		if error = rows.Scan(&warning.ID, &publicationId, &warning.Note, &warning.Created_at); error != nil {
+			return nil, error
+		}
+		warning.PublicationID = uint64(publicationId.Int64)
+		warnings = append(warnings, warning)
+	}
+	return warnings, nil
+}
@@ -0,0 +1,118 @@
+package repositories
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/wesleywcr/dev-book/api/db"
+	"github.com/wesleywcr/dev-book/api/models"
+)
+
+// PublicationSearch searches publications with the full-text indexes of
+// MySQL and Postgres. The database keeps those indexes up to date itself.
+type PublicationSearch struct {
+	db *db.DB
+}
+
+func NewPublicationSearch(db *db.DB) *PublicationSearch {
+	return &PublicationSearch{db}
+}
+
+// Index does nothing: the database indexes publications as they are saved.
+func (repository PublicationSearch) Index(publication models.Publication) error {
+	return nil
+}
+
+// Remove does nothing: deleted publications are filtered out by the queries.
+func (repository PublicationSearch) Remove(publicationId uint64) error {
+	return nil
+}
+
+// Search returns the IDs of the publications matching the query, the most
+// relevant first.
+func (repository PublicationSearch) Search(query models.SearchQuery) ([]uint64, error) {
+	var match string
+	switch repository.db.Dialect {
+	case db.MySQL:
+		match = "match(p.title, p.content) against (? in natural language mode)"
+	case db.Postgres:
+		match = "ts_rank(to_tsvector('simple', p.title || ' ' || p.content), plainto_tsquery('simple', ?))"
+	default:
+		return nil, errors.New("A busca no banco de dados não é suportada com SQLite")
+	}
+
+	var clauses strings.Builder
+	args := []any{query.Terms}
+	clauses.WriteString(" where p.deleted_at is null and p.content <> ''")
+
+	if repository.db.Dialect == db.Postgres {
+		clauses.WriteString(" and to_tsvector('simple', p.title || ' ' || p.content) @@ plainto_tsquery('simple', ?)")
+	} else {
+		clauses.WriteString(" and " + match + " > 0")
+	}
+	args = append(args, query.Terms)
+
+	if query.AuthorID != 0 {
+		clauses.WriteString(" and p.author_id = ?")
+		args = append(args, query.AuthorID)
+	}
+	if query.Tag != "" {
+		clauses.WriteString(` and exists (select 1 from publication_tags pt inner join tags t on t.id = pt.tag_id
+		where pt.publication_id = p.id and t.name = ?)`)
+		args = append(args, query.Tag)
+	}
+	if !query.From.IsZero() {
+		clauses.WriteString(" and p.created_at >= ?")
+		args = append(args, query.From)
+	}
+	if !query.Until.IsZero() {
+		clauses.WriteString(" and p.created_at < ?")
+		args = append(args, query.Until)
+	}
+	args = append(args, query.Page.Limit, query.Page.Offset())
+
+	rows, error := repository.db.Query(`
+	select p.id, `+match+` as relevance from publications p`+clauses.String()+`
+	order by relevance desc, p.id desc
+	limit ? offset ?`, args...)
+	if error != nil {
+		return nil, error
+	}
+	defer rows.Close()
+
+	var ids []uint64
+
+	for rows.Next() {
+		var publicationId uint64
+		var relevance float64
+		if error = rows.Scan(&publicationId, &relevance); error != nil {
+			return nil, error
+		}
+		ids = append(ids, publicationId)
+	}
+	return ids, nil
+}
+
+// SearchAll returns every publication that was not deleted, to fill an
+// index kept outside the database.
+func (repository PublicationSearch) SearchAll() ([]models.Publication, error) {
+	rows, error := repository.db.Query(`
+	select ` + publicationColumns + ` from publications p
+	inner join users u on u.id = p.author_id
+	where p.deleted_at is null`)
+	if error != nil {
+		return nil, error
+	}
+	defer rows.Close()
+
+	var publications []models.Publication
+
+	for rows.Next() {
+		publication, error := scanPublication(rows)
+		if error != nil {
+			return nil, error
+		}
+		publications = append(publications, publication)
+	}
+	return publications, nil
+}
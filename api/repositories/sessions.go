@@ -0,0 +1,114 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/wesleywcr/dev-book/api/db"
+	"github.com/wesleywcr/dev-book/api/models"
+)
+
+type Sessions struct {
+	db *db.DB
+}
+
+func NewRepositoryOfSessions(db *db.DB) *Sessions {
+	return &Sessions{db}
+}
+
+// Create opens a session for the user on a device.
+func (repository Sessions) Create(session models.Session) (uint64, error) {
+	return repository.db.Insert(
+		"insert into sessions (user_id, ip, user_agent) values (?, ?, ?)",
+		session.UserID, session.IP, session.UserAgent,
+	)
+}
+
+// Search returns the sessions of userId opened since since, the most
+// recently used first.
+func (repository Sessions) Search(userId uint64, since time.Time) ([]models.Session, error) {
+	rows, error := repository.db.Query(`
+	select id, user_id, ip, user_agent, created_at, last_seen_at
+	from sessions
+	where user_id = ? and created_at >= ?
+	order by last_seen_at desc, id desc`,
+		userId, since)
+	if error != nil {
+		return nil, error
+	}
+	defer rows.Close()
+
+	var sessions []models.Session
+
+	for rows.Next() {
+		var session models.Session
+		if error = rows.Scan(
+			&session.ID,
+			&session.UserID,
+			&session.IP,
+			&session.UserAgent,
+			&session.Created_at,
+			&session.LastSeenAt,
+		); error != nil {
+			return nil, error
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, nil
+}
+
+// Check tells whether the session of userId is still open, whether the
+// account of userId is suspended and the role it has now, which may no
+// longer be the one its token carries.
+func (repository Sessions) Check(sessionId, userId uint64) (open, suspended bool, role models.Role, error error) {
+	var suspendedAt sql.NullTime
+	error = repository.db.QueryRow(`
+	select u.suspended_at, u.role from sessions s
+	inner join users u on u.id = s.user_id
+	where s.id = ? and s.user_id = ?`,
+		sessionId, userId,
+	).Scan(&suspendedAt, &role)
+	if error == sql.ErrNoRows {
+		return false, false, "", nil
+	}
+	if error != nil {
+		return false, false, "", error
+	}
+	return true, suspendedAt.Valid, role, nil
+}
+
+// Touch records that the session was used, at most once per interval so
+// each request does not write to the database.
+func (repository Sessions) Touch(sessionId uint64, interval time.Duration) error {
+	_, error := repository.db.Exec(
+		"update sessions set last_seen_at = current_timestamp where id = ? and last_seen_at < ?",
+		sessionId, time.Now().Add(-interval).UTC(),
+	)
+	return error
+}
+
+// Delete revokes a session of userId, reporting whether it existed.
+func (repository Sessions) Delete(sessionId, userId uint64) (bool, error) {
+	result, error := repository.db.Exec("delete from sessions where id = ? and user_id = ?", sessionId, userId)
+	if error != nil {
+		return false, error
+	}
+	deleted, error := result.RowsAffected()
+	return deleted > 0, error
+}
+
+// DeleteOthers revokes every session of userId but keepId, returning how many.
+func (repository Sessions) DeleteOthers(userId, keepId uint64) (int64, error) {
+	result, error := repository.db.Exec("delete from sessions where user_id = ? and id <> ?", userId, keepId)
+	if error != nil {
+		return 0, error
+	}
+	return result.RowsAffected()
+}
+
+// DeleteExpired removes the sessions of userId opened before before, whose
+// tokens expired.
+func (repository Sessions) DeleteExpired(userId uint64, before time.Time) error {
+	_, error := repository.db.Exec("delete from sessions where user_id = ? and created_at < ?", userId, before)
+	return error
+}
@@ -0,0 +1,159 @@
+package repositories
+
+import (
+	"strings"
+
+	"github.com/wesleywcr/dev-book/api/db"
+	"github.com/wesleywcr/dev-book/api/models"
+)
+
+// Suggestions reads the signals of the follower graph used to recommend
+// accounts. Every query leaves out the user, the accounts they follow or
+// asked to follow, the users they blocked, muted or were blocked by, and the
+// suspended accounts.
+type Suggestions struct {
+	db *db.DB
+}
+
+func NewRepositoryOfSuggestions(db *db.DB) *Suggestions {
+	return &Suggestions{db}
+}
+
+// suggestable keeps the candidates in column that can be suggested to the
+// user bound by suggestableArgs.
+func suggestable(column string) string {
+	return strings.ReplaceAll(`column <> ?
+	and column not in (select user_id from followers where follower_id = ?)
+	and column not in (select user_id from follow_requests where follower_id = ?)
+	and column not in (select blocked_id from user_blocks where blocker_id = ?)
+	and column not in (select blocker_id from user_blocks where blocked_id = ?)
+	and column not in (select muted_id from user_mutes where muter_id = ?)
+	and column not in (select id from users where suspended_at is not null)`, "column", column)
+}
+
+func suggestableArgs(userId uint64) []any {
+	return []any{userId, userId, userId, userId, userId, userId}
+}
+
+// SearchFollowedByFollowing returns, for each candidate, the users followed by
+// userId who follow them, in nickname order.
+func (repository Suggestions) SearchFollowedByFollowing(userId uint64) (map[uint64][]models.UserSummary, error) {
+	rows, error := repository.db.Query(`
+	select f2.user_id, u.id, u.nickname
+	from followers f1
+	inner join followers f2 on f2.follower_id = f1.user_id
+	inner join users u on u.id = f1.user_id
+	where f1.follower_id = ? and `+suggestable("f2.user_id")+`
+	order by u.nickname`,
+		append([]any{userId}, suggestableArgs(userId)...)...,
+	)
+	if error != nil {
+		return nil, error
+	}
+	defer rows.Close()
+
+	followedBy := map[uint64][]models.UserSummary{}
+
+	for rows.Next() {
+		var candidateId uint64
+		var follower models.UserSummary
+		if error = rows.Scan(&candidateId, &follower.ID, &follower.Nickname); error != nil {
+			return nil, error
+		}
+		followedBy[candidateId] = append(followedBy[candidateId], follower)
+	}
+	return followedBy, nil
+}
+
+// SearchMutualFollowers returns, for each candidate, how many followers of
+// userId follow them too.
+func (repository Suggestions) SearchMutualFollowers(userId uint64) (map[uint64]int, error) {
+	rows, error := repository.db.Query(`
+	select f2.user_id, count(*)
+	from followers f1
+	inner join followers f2 on f2.follower_id = f1.follower_id
+	where f1.user_id = ? and `+suggestable("f2.user_id")+`
+	group by f2.user_id`,
+		append([]any{userId}, suggestableArgs(userId)...)...,
+	)
+	if error != nil {
+		return nil, error
+	}
+	defer rows.Close()
+
+	mutuals := map[uint64]int{}
+
+	for rows.Next() {
+		var candidateId uint64
+		var count int
+		if error = rows.Scan(&candidateId, &count); error != nil {
+			return nil, error
+		}
+		mutuals[candidateId] = count
+	}
+	return mutuals, nil
+}
+
+// SearchSharedTags returns, for each candidate, the hashtags both they and
+// userId used in publications that were not deleted. Only public accounts
+// are matched, so the hashtags of private ones are not revealed to who does
+// not follow them.
+func (repository Suggestions) SearchSharedTags(userId uint64) (map[uint64][]string, error) {
+	rows, error := repository.db.Query(`
+	select distinct p2.author_id, t.name
+	from publications p1
+	inner join publication_tags pt1 on pt1.publication_id = p1.id
+	inner join publication_tags pt2 on pt2.tag_id = pt1.tag_id
+	inner join publications p2 on p2.id = pt2.publication_id
+	inner join users u on u.id = p2.author_id
+	inner join tags t on t.id = pt1.tag_id
+	where p1.author_id = ? and p1.deleted_at is null and p2.deleted_at is null
+	and u.is_private = ? and u.suspended_at is null
+	and `+suggestable("p2.author_id")+`
+	order by t.name`,
+		append([]any{userId, false}, suggestableArgs(userId)...)...,
+	)
+	if error != nil {
+		return nil, error
+	}
+	defer rows.Close()
+
+	tags := map[uint64][]string{}
+
+	for rows.Next() {
+		var candidateId uint64
+		var tag string
+		if error = rows.Scan(&candidateId, &tag); error != nil {
+			return nil, error
+		}
+		tags[candidateId] = append(tags[candidateId], tag)
+	}
+	return tags, nil
+}
+
+// SearchSummaries returns the ID and nickname of each of the users.
+func (repository Suggestions) SearchSummaries(userIds []uint64) (map[uint64]models.UserSummary, error) {
+	if len(userIds) == 0 {
+		return map[uint64]models.UserSummary{}, nil
+	}
+
+	rows, error := repository.db.Query(
+		"select id, nickname from users where id in ("+placeholders(len(userIds))+")",
+		uint64Args(userIds)...,
+	)
+	if error != nil {
+		return nil, error
+	}
+	defer rows.Close()
+
+	summaries := map[uint64]models.UserSummary{}
+
+	for rows.Next() {
+		var summary models.UserSummary
+		if error = rows.Scan(&summary.ID, &summary.Nickname); error != nil {
+			return nil, error
+		}
+		summaries[summary.ID] = summary
+	}
+	return summaries, nil
+}
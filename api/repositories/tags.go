@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/wesleywcr/dev-book/api/db"
+	"github.com/wesleywcr/dev-book/api/models"
+)
+
+type Tags struct {
+	db *db.DB
+}
+
+func NewRepositoryOfTags(db *db.DB) *Tags {
+	return &Tags{db}
+}
+
+// Replace makes names the exact set of tags of the publication.
+func (repository Tags) Replace(publicationId uint64, names []string) error {
+	if _, error := repository.db.Exec("delete from publication_tags where publication_id = ?", publicationId); error != nil {
+		return error
+	}
+
+	for _, name := range names {
+		if _, error := repository.db.Exec(repository.db.Dialect.InsertIgnore("tags (name) values (?)"), name); error != nil {
+			return error
+		}
+
+		var tagId uint64
+		if error := repository.db.QueryRow("select id from tags where name = ?", name).Scan(&tagId); error != nil {
+			return error
+		}
+
+		if _, error := repository.db.Exec(
+			repository.db.Dialect.InsertIgnore("publication_tags (publication_id, tag_id) values (?, ?)"),
+			publicationId, tagId,
+		); error != nil {
+			return error
+		}
+	}
+	return nil
+}
+
+// Trending returns the tags used by the most publications created since since.
+// Only the publications of public accounts count, as everyone sees the list.
+func (repository Tags) Trending(since time.Time, limit uint64) ([]models.Tag, error) {
+	rows, error := repository.db.Query(`
+	select t.name, count(*) as uses from tags t
+	inner join publication_tags pt on pt.tag_id = t.id
+	inner join publications p on p.id = pt.publication_id
+	inner join users u on u.id = p.author_id
+	where p.created_at >= ? and p.deleted_at is null and u.is_private = ?
+	group by t.id, t.name
+	order by uses desc, t.name
+	limit ?`,
+		since, false, limit)
+	if error != nil {
+		return nil, error
+	}
+	defer rows.Close()
+
+	var tags []models.Tag
+
+	for rows.Next() {
+		var tag models.Tag
+		if error = rows.Scan(&tag.Name, &tag.Uses); error != nil {
+			return nil, error
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
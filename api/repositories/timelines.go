@@ -0,0 +1,191 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/wesleywcr/dev-book/api/db"
+	"github.com/wesleywcr/dev-book/api/models"
+)
+
+// Timelines stores the materialized home timeline of each user: the IDs of
+// the publications written by them and by the users they follow.
+type Timelines struct {
+	db *db.DB
+}
+
+func NewRepositoryOfTimelines(db *db.DB) *Timelines {
+	return &Timelines{db}
+}
+
+// Add places the publication in the timeline of its author and, when
+// toFollowers is set, in the timelines of everyone following the author.
+func (repository Timelines) Add(publicationId, authorId uint64, toFollowers bool) error {
+	if _, error := repository.db.Exec(
+		repository.db.Dialect.InsertIgnore("timelines (user_id, publication_id, author_id) values (?, ?, ?)"),
+		authorId, publicationId, authorId,
+	); error != nil {
+		return error
+	}
+	if !toFollowers {
+		return nil
+	}
+
+	_, error := repository.db.Exec(
+		repository.db.Dialect.InsertIgnore(`timelines (user_id, publication_id, author_id)
+		select follower_id, ?, user_id from followers where user_id = ?`),
+		publicationId, authorId,
+	)
+	return error
+}
+
+// Backfill copies the limit latest publications of authorId into the
+// timeline of userId.
+func (repository Timelines) Backfill(userId, authorId uint64, limit int) error {
+	_, error := repository.db.Exec(
+		repository.db.Dialect.InsertIgnore(`timelines (user_id, publication_id, author_id)
+		select ?, id, author_id from publications
+		where author_id = ? and deleted_at is null
+		order by id desc limit ?`),
+		userId, authorId, limit,
+	)
+	return error
+}
+
+// RemoveAuthor takes every publication of authorId out of the timeline of userId.
+func (repository Timelines) RemoveAuthor(userId, authorId uint64) error {
+	_, error := repository.db.Exec(
+		"delete from timelines where user_id = ? and author_id = ?", userId, authorId,
+	)
+	return error
+}
+
+// SearchPopularFollowed returns the users followed by userId that have more
+// than limit followers, whose publications are not copied into timelines.
+func (repository Timelines) SearchPopularFollowed(userId uint64, limit int) ([]uint64, error) {
+	rows, error := repository.db.Query(`
+	select f.user_id from followers f
+	where f.follower_id = ?
+	and (select count(*) from followers c where c.user_id = f.user_id) > ?`,
+		userId, limit,
+	)
+	if error != nil {
+		return nil, error
+	}
+	defer rows.Close()
+
+	var ids []uint64
+
+	for rows.Next() {
+		var authorId uint64
+		if error = rows.Scan(&authorId); error != nil {
+			return nil, error
+		}
+		ids = append(ids, authorId)
+	}
+	return ids, nil
+}
+
+// Search returns the timeline of userId merged with the publications of the
+// authors given, newest first, leaving out deleted publications and muted users.
+func (repository Timelines) Search(userId uint64, authorIds []uint64, page models.Page) ([]models.Publication, error) {
+	return repository.search(userId, authorIds, time.Time{}, page.Limit, page.Offset())
+}
+
+// SearchSince returns up to limit publications of the timeline of userId,
+// merged like in Search, created since the given time.
+func (repository Timelines) SearchSince(userId uint64, authorIds []uint64, since time.Time, limit uint64) ([]models.Publication, error) {
+	return repository.search(userId, authorIds, since, limit, 0)
+}
+
+// search lists the timeline of userId, only from since on unless it is zero.
+func (repository Timelines) search(userId uint64, authorIds []uint64, since time.Time, limit, offset uint64) ([]models.Publication, error) {
+	args := []any{userId}
+	merged := ""
+	if len(authorIds) > 0 {
+		merged = " or p.author_id in (" + placeholders(len(authorIds)) + ")"
+		args = append(args, uint64Args(authorIds)...)
+	}
+	args = append(args, userId)
+
+	recent := ""
+	if !since.IsZero() {
+		recent = "and p.created_at >= ?"
+		args = append(args, since)
+	}
+	args = append(args, limit, offset)
+
+	rows, error := repository.db.Query(`
+	select `+publicationColumns+` from publications p
+	inner join users u on u.id = p.author_id
+	where (p.id in (select publication_id from timelines where user_id = ?)`+merged+`)
+	and p.deleted_at is null
+	and p.author_id not in (select muted_id from user_mutes where muter_id = ?)
+	`+recent+`
+	order by p.id desc
+	limit ? offset ?`, args...)
+	if error != nil {
+		return nil, error
+	}
+	defer rows.Close()
+
+	return scanPublications(rows)
+}
+
+// SearchSecondDegree returns up to limit publications created since the given
+// time by authors followed by the users userId follows, but not by userId.
+// Private accounts, blocked and muted users are left out.
+func (repository Timelines) SearchSecondDegree(userId uint64, since time.Time, limit uint64) ([]models.Publication, error) {
+	rows, error := repository.db.Query(`
+	select `+publicationColumns+` from publications p
+	inner join users u on u.id = p.author_id
+	where p.author_id in (
+		select f2.user_id from followers f1
+		inner join followers f2 on f2.follower_id = f1.user_id
+		where f1.follower_id = ?
+	)
+	and p.author_id <> ?
+	and p.author_id not in (select user_id from followers where follower_id = ?)
+	and p.author_id not in (select blocked_id from user_blocks where blocker_id = ?)
+	and p.author_id not in (select blocker_id from user_blocks where blocked_id = ?)
+	and p.author_id not in (select muted_id from user_mutes where muter_id = ?)
+	and u.is_private = ?
+	and p.deleted_at is null and p.content <> ''
+	and p.created_at >= ?
+	order by p.id desc
+	limit ?`,
+		userId, userId, userId, userId, userId, userId, false, since, limit,
+	)
+	if error != nil {
+		return nil, error
+	}
+	defer rows.Close()
+
+	return scanPublications(rows)
+}
+
+// SearchInteractions counts, for each user, the notifications userId caused
+// them since the given time: likes, follows, mentions, reposts and quotes.
+func (repository Timelines) SearchInteractions(userId uint64, since time.Time) (map[uint64]int, error) {
+	rows, error := repository.db.Query(`
+	select user_id, count(*) from notifications
+	where actor_id = ? and created_at >= ?
+	group by user_id`,
+		userId, since,
+	)
+	if error != nil {
+		return nil, error
+	}
+	defer rows.Close()
+
+	interactions := map[uint64]int{}
+
+	for rows.Next() {
+		var otherId uint64
+		var count int
+		if error = rows.Scan(&otherId, &count); error != nil {
+			return nil, error
+		}
+		interactions[otherId] = count
+	}
+	return interactions, nil
+}
@@ -0,0 +1,186 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/wesleywcr/dev-book/api/db"
+	"github.com/wesleywcr/dev-book/api/models"
+)
+
+type TwoFactor struct {
+	db *db.DB
+}
+
+func NewRepositoryOfTwoFactor(db *db.DB) *TwoFactor {
+	return &TwoFactor{db}
+}
+
+// Search returns the TOTP enrollment of userId, or an empty one when there
+// is none.
+func (repository TwoFactor) Search(userId uint64) (models.TwoFactor, error) {
+	var twoFactor models.TwoFactor
+	var failedAt sql.NullTime
+	var challenge sql.NullString
+	error := repository.db.QueryRow(`
+	select user_id, secret, enabled, last_counter, failures, failed_at, challenge
+	from two_factor where user_id = ?`, userId,
+	).Scan(
+		&twoFactor.UserID,
+		&twoFactor.Secret,
+		&twoFactor.Enabled,
+		&twoFactor.LastStep,
+		&twoFactor.Failures,
+		&failedAt,
+		&challenge,
+	)
+	if error == sql.ErrNoRows {
+		return models.TwoFactor{}, nil
+	}
+	if error != nil {
+		return models.TwoFactor{}, error
+	}
+	if failedAt.Valid {
+		twoFactor.FailedAt = &failedAt.Time
+	}
+	twoFactor.Challenge = challenge.String
+	return twoFactor, nil
+}
+
+// Create starts a pending enrollment of userId, replacing the previous one.
+func (repository TwoFactor) Create(userId uint64, secret string) error {
+	if error := repository.Delete(userId); error != nil {
+		return error
+	}
+	_, error := repository.db.Exec("insert into two_factor (user_id, secret) values (?, ?)", userId, secret)
+	return error
+}
+
+// Enable turns on the enrollment of userId, confirmed by a code of step,
+// replacing the recovery codes with those of hashes.
+func (repository TwoFactor) Enable(userId uint64, step int64, hashes []string) error {
+	return repository.db.Transaction(func(tx *db.Tx) error {
+		if _, error := tx.Exec(
+			"update two_factor set enabled = ?, last_counter = ?, failures = 0 where user_id = ?", true, step, userId,
+		); error != nil {
+			return error
+		}
+
+		if _, error := tx.Exec("delete from recovery_codes where user_id = ?", userId); error != nil {
+			return error
+		}
+		for _, hash := range hashes {
+			if _, error := tx.Exec(
+				"insert into recovery_codes (user_id, code_hash) values (?, ?)", userId, hash,
+			); error != nil {
+				return error
+			}
+		}
+		return nil
+	})
+}
+
+// StartChallenge records challengeId as the challenge of the two-factor login
+// of userId, replacing the one of a previous login.
+func (repository TwoFactor) StartChallenge(userId uint64, challengeId string) error {
+	_, error := repository.db.Exec("update two_factor set challenge = ? where user_id = ?", challengeId, userId)
+	return error
+}
+
+// UseChallenge completes the challenge of the two-factor login of userId,
+// reporting false when it is no longer challengeId, as when it was already
+// completed by a concurrent request.
+func (repository TwoFactor) UseChallenge(userId uint64, challengeId string) (bool, error) {
+	result, error := repository.db.Exec(
+		"update two_factor set challenge = null where user_id = ? and challenge = ?", userId, challengeId,
+	)
+	if error != nil {
+		return false, error
+	}
+	used, error := result.RowsAffected()
+	return used > 0, error
+}
+
+// RestoreChallenge puts back challengeId as the challenge of the two-factor
+// login of userId, unless a newer login started another one meanwhile.
+func (repository TwoFactor) RestoreChallenge(userId uint64, challengeId string) error {
+	_, error := repository.db.Exec(
+		"update two_factor set challenge = ? where user_id = ? and challenge is null", challengeId, userId,
+	)
+	return error
+}
+
+// UseStep records that a code of step was accepted, reporting false when a
+// code of that step or a later one already was, as by a concurrent request.
+func (repository TwoFactor) UseStep(userId uint64, step int64) (bool, error) {
+	result, error := repository.db.Exec(
+		"update two_factor set last_counter = ?, failures = 0 where user_id = ? and last_counter < ?",
+		step, userId, step,
+	)
+	if error != nil {
+		return false, error
+	}
+	used, error := result.RowsAffected()
+	return used > 0, error
+}
+
+// UseRecoveryCode spends the unused recovery code of userId with the hash,
+// reporting whether there was one.
+func (repository TwoFactor) UseRecoveryCode(userId uint64, hash string) (bool, error) {
+	result, error := repository.db.Exec(`
+	update recovery_codes set used_at = current_timestamp
+	where user_id = ? and code_hash = ? and used_at is null`,
+		userId, hash,
+	)
+	if error != nil {
+		return false, error
+	}
+	used, error := result.RowsAffected()
+	if error != nil || used == 0 {
+		return false, error
+	}
+
+	_, error = repository.db.Exec("update two_factor set failures = 0 where user_id = ?", userId)
+	return true, error
+}
+
+// CountRecoveryCodes returns how many recovery codes of userId are unused.
+func (repository TwoFactor) CountRecoveryCodes(userId uint64) (int, error) {
+	var count int
+	error := repository.db.QueryRow(
+		"select count(*) from recovery_codes where user_id = ? and used_at is null", userId,
+	).Scan(&count)
+	return count, error
+}
+
+// CountAttempt counts an attempt to enter a code for userId before it is
+// checked, reporting false when maxFailures attempts in a row already failed
+// since lockedSince. The check and the count are a single statement, so
+// concurrent attempts cannot all get past the limit. Accepted codes reset
+// the count.
+func (repository TwoFactor) CountAttempt(userId uint64, maxFailures int, lockedSince time.Time) (bool, error) {
+	result, error := repository.db.Exec(`
+	update two_factor set
+		failures = case when failures >= ? then 1 else failures + 1 end,
+		failed_at = current_timestamp
+	where user_id = ? and (failures < ? or failed_at is null or failed_at < ?)`,
+		maxFailures, userId, maxFailures, lockedSince,
+	)
+	if error != nil {
+		return false, error
+	}
+	counted, error := result.RowsAffected()
+	return counted > 0, error
+}
+
+// Delete turns off two-factor authentication for userId, forgetting the
+// secret and the recovery codes.
+func (repository TwoFactor) Delete(userId uint64) error {
+	return repository.db.Transaction(func(tx *db.Tx) error {
+		if _, error := tx.Exec("delete from recovery_codes where user_id = ?", userId); error != nil {
+			return error
+		}
+		_, error := tx.Exec("delete from two_factor where user_id = ?", userId)
+		return error
+	})
+}
@@ -3,45 +3,36 @@ package repositories
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 
+	"github.com/wesleywcr/dev-book/api/db"
 	"github.com/wesleywcr/dev-book/api/models"
 )
 
+// userColumns are the columns read by scanUser, from the users u.
+const userColumns = `u.id, u.name, u.nickname, u.email, u.is_private, u.bio, u.location, u.website,
+	u.avatar_key, u.banner_key, u.role, u.suspended_at, u.created_at`
+
 type Users struct {
-	db *sql.DB
+	db *db.DB
 }
 
-func NewRepositoryOfUsers(db *sql.DB) *Users {
+func NewRepositoryOfUsers(db *db.DB) *Users {
 	return &Users{db}
 }
 
 func (repository Users) Create(user models.User) (uint64, error) {
-	statement, error := repository.db.Prepare(
-		"insert into users (name, nickname, email, password) values(?, ?, ?, ?)",
+	return repository.db.Insert(
+		"insert into users (name, nickname, email, password, is_private, bio, location, website) values(?, ?, ?, ?, ?, ?, ?, ?)",
+		user.Name, user.Nickname, user.Email, user.Password, user.IsPrivate, user.Bio, user.Location, user.Website,
 	)
-	if error != nil {
-		return 0, nil
-	}
-
-	defer statement.Close()
-
-	result, error := statement.Exec(user.Name, user.Nickname, user.Email, user.Password)
-	if error != nil {
-		return 0, error
-	}
-
-	lastInsertId, error := result.LastInsertId()
-	if error != nil {
-		return 0, error
-	}
-	return uint64(lastInsertId), nil
 }
 
 func (repository Users) Search(nameOrNickname string) ([]models.User, error) {
 	nameOrNickname = fmt.Sprintf("%%%s%%", nameOrNickname) // %nameOrNickname%
 
 	rows, error := repository.db.Query(
-		"select id, name, nickname, email, created_at from users where name LIKE ? or nickname LIKE ?",
+		"select "+userColumns+" from users u where lower(u.name) LIKE ? or lower(u.nickname) LIKE ?",
 		nameOrNickname, nameOrNickname,
 	)
 	if error != nil {
@@ -52,14 +43,8 @@ func (repository Users) Search(nameOrNickname string) ([]models.User, error) {
 	var users []models.User
 
 	for rows.Next() {
-		var user models.User
-		if error = rows.Scan(
-			&user.ID,
-			&user.Name,
-			&user.Nickname,
-			&user.Email,
-			&user.Created_at,
-		); error != nil {
+		user, error := scanUser(rows)
+		if error != nil {
 			return nil, error
 		}
 		users = append(users, user)
@@ -70,7 +55,7 @@ func (repository Users) Search(nameOrNickname string) ([]models.User, error) {
 
 func (repository Users) SearchPerId(ID uint64) (models.User, error) {
 	rows, error := repository.db.Query(
-		"select id, name, nickname, email, created_at from users where id = ?", ID,
+		"select "+userColumns+" from users u where u.id = ?", ID,
 	)
 
 	if error != nil {
@@ -79,36 +64,116 @@ func (repository Users) SearchPerId(ID uint64) (models.User, error) {
 
 	defer rows.Close()
 
-	var user models.User
-
 	if rows.Next() {
-		if error = rows.Scan(
-			&user.ID,
-			&user.Name,
-			&user.Nickname,
-			&user.Email,
-			&user.Created_at,
-		); error != nil {
-			return models.User{}, error
-		}
+		return scanUser(rows)
 	}
-	return user, nil
+	return models.User{}, nil
 }
 
 func (repository Users) Update(ID uint64, user models.User) error {
 	statement, error := repository.db.Prepare(
-		"update users set name = ?, nickname = ?, email = ? where id = ?",
+		"update users set name = ?, nickname = ?, email = ?, is_private = ?, bio = ?, location = ?, website = ? where id = ?",
 	)
 	if error != nil {
 		return error
 	}
 	defer statement.Close()
-	if _, error := statement.Exec(user.Name, user.Nickname, user.Email, ID); error != nil {
+	if _, error := statement.Exec(
+		user.Name, user.Nickname, user.Email, user.IsPrivate, user.Bio, user.Location, user.Website, ID,
+	); error != nil {
 		return error
 	}
 	return nil
 }
 
+// UpdateAvatar replaces the storage key of the avatar of the user; an empty
+// key removes it.
+func (repository Users) UpdateAvatar(ID uint64, key string) error {
+	_, error := repository.db.Exec("update users set avatar_key = ? where id = ?", nullString(key), ID)
+	return error
+}
+
+// UpdateBanner replaces the storage key of the banner of the user; an empty
+// key removes it.
+func (repository Users) UpdateBanner(ID uint64, key string) error {
+	_, error := repository.db.Exec("update users set banner_key = ? where id = ?", nullString(key), ID)
+	return error
+}
+
+// SearchCounts returns how many followers, followed users and publications
+// the user has.
+func (repository Users) SearchCounts(ID uint64) (models.UserCounts, error) {
+	var counts models.UserCounts
+	error := repository.db.QueryRow(`
+	select
+	(select count(*) from followers where user_id = ?),
+	(select count(*) from followers where follower_id = ?),
+	(select count(*) from publications where author_id = ? and deleted_at is null)`,
+		ID, ID, ID,
+	).Scan(&counts.Followers, &counts.Following, &counts.Publications)
+	return counts, error
+}
+
+// UpdateRole gives the user a new role.
+func (repository Users) UpdateRole(ID uint64, role models.Role) error {
+	_, error := repository.db.Exec("update users set role = ? where id = ?", role, ID)
+	return error
+}
+
+// Suspend suspends the account of the user, or lifts the suspension when
+// suspended is false.
+func (repository Users) Suspend(ID uint64, suspended bool) error {
+	if suspended {
+		_, error := repository.db.Exec(
+			"update users set suspended_at = current_timestamp where id = ? and suspended_at is null", ID,
+		)
+		return error
+	}
+	_, error := repository.db.Exec("update users set suspended_at = null where id = ?", ID)
+	return error
+}
+
+// SearchAll returns the users, newest first, optionally only those with the
+// role or, when suspended is not nil, only the suspended or active ones.
+func (repository Users) SearchAll(role models.Role, suspended *bool, page models.Page) ([]models.User, error) {
+	var clauses []string
+	var args []any
+	if role != "" {
+		clauses = append(clauses, "u.role = ?")
+		args = append(args, role)
+	}
+	if suspended != nil && *suspended {
+		clauses = append(clauses, "u.suspended_at is not null")
+	} else if suspended != nil {
+		clauses = append(clauses, "u.suspended_at is null")
+	}
+	where := ""
+	if len(clauses) > 0 {
+		where = "where " + strings.Join(clauses, " and ")
+	}
+	args = append(args, page.Limit, page.Offset())
+
+	rows, error := repository.db.Query(`
+	select `+userColumns+` from users u `+where+`
+	order by u.id desc
+	limit ? offset ?`, args...)
+	if error != nil {
+		return nil, error
+	}
+	defer rows.Close()
+
+	var users []models.User
+
+	for rows.Next() {
+		user, error := scanUser(rows)
+		if error != nil {
+			return nil, error
+		}
+		users = append(users, user)
+	}
+	return users, nil
+}
+
 func (repository Users) Delete(ID uint64) error {
 	statement, error := repository.db.Prepare(
 		"delete from users where id = ?",
@@ -128,7 +193,7 @@ func (repository Users) Delete(ID uint64) error {
 func (repository Users) SearchEmail(email string) (models.User, error) {
 
 	row, error := repository.db.Query(
-		"select id, password from users where email = ?", email)
+		"select id, password, role, suspended_at from users where email = ?", email)
 	if error != nil {
 		return models.User{}, error
 	}
@@ -137,15 +202,19 @@ func (repository Users) SearchEmail(email string) (models.User, error) {
 	var user models.User
 
 	if row.Next() {
-		if error = row.Scan(&user.ID, &user.Password); error != nil {
+		var suspendedAt sql.NullTime
+		if error = row.Scan(&user.ID, &user.Password, &user.Role, &suspendedAt); error != nil {
 			return models.User{}, error
 		}
+		if suspendedAt.Valid {
+			user.SuspendedAt = &suspendedAt.Time
+		}
 	}
 	return user, error
 }
 func (repository Users) Follow(userId, followerId uint64) error {
 	statement, error := repository.db.Prepare(
-		"insert ignore into followers (user_id, follower_id) values (?, ?)",
+		repository.db.Dialect.InsertIgnore("followers (user_id, follower_id) values (?, ?)"),
 	)
 	if error != nil {
 		return error
@@ -172,9 +241,201 @@ func (repository Users) UnFollow(userId, followerId uint64) error {
 	return nil
 }
 
+// IsFollower reports whether followerId follows userId.
+func (repository Users) IsFollower(userId, followerId uint64) (bool, error) {
+	var follows uint64
+	error := repository.db.QueryRow(
+		"select count(*) from followers where user_id = ? and follower_id = ?", userId, followerId,
+	).Scan(&follows)
+	return follows > 0, error
+}
+
+// CreateFollowRequest stores a pending request of followerId to follow userId.
+func (repository Users) CreateFollowRequest(userId, followerId uint64) error {
+	_, error := repository.db.Exec(
+		repository.db.Dialect.InsertIgnore("follow_requests (user_id, follower_id) values (?, ?)"),
+		userId, followerId,
+	)
+	return error
+}
+
+// DeleteFollowRequest removes the request of followerId to follow userId,
+// reporting whether there was one.
+func (repository Users) DeleteFollowRequest(userId, followerId uint64) (bool, error) {
+	result, error := repository.db.Exec(
+		"delete from follow_requests where user_id = ? and follower_id = ?", userId, followerId,
+	)
+	if error != nil {
+		return false, error
+	}
+
+	deleted, error := result.RowsAffected()
+	return deleted > 0, error
+}
+
+// SearchFollowRequests returns the pending requests to follow userId, oldest first.
+func (repository Users) SearchFollowRequests(userId uint64, page models.Page) ([]models.FollowRequest, error) {
+	rows, error := repository.db.Query(`
+	select u.id, u.nickname, r.created_at
+	from follow_requests r inner join users u on u.id = r.follower_id
+	where r.user_id = ?
+	order by r.created_at, u.id
+	limit ? offset ?`,
+		userId, page.Limit, page.Offset())
+	if error != nil {
+		return nil, error
+	}
+	defer rows.Close()
+
+	var requests []models.FollowRequest
+
+	for rows.Next() {
+		var request models.FollowRequest
+		if error = rows.Scan(
+			&request.Follower.ID,
+			&request.Follower.Nickname,
+			&request.Created_at,
+		); error != nil {
+			return nil, error
+		}
+		requests = append(requests, request)
+	}
+	return requests, nil
+}
+
+// Block makes blockerId block blockedId, removing the follow relationships
+// and follow requests between them in both directions, in a transaction so
+// no relationship outlives the block.
+func (repository Users) Block(blockerId, blockedId uint64) error {
+	return repository.db.Transaction(func(tx *db.Tx) error {
+		if _, error := tx.Exec(
+			repository.db.Dialect.InsertIgnore("user_blocks (blocker_id, blocked_id) values (?, ?)"),
+			blockerId, blockedId,
+		); error != nil {
+			return error
+		}
+
+		for _, table := range []string{"followers", "follow_requests"} {
+			if _, error := tx.Exec(
+				"delete from "+table+" where (user_id = ? and follower_id = ?) or (user_id = ? and follower_id = ?)",
+				blockerId, blockedId, blockedId, blockerId,
+			); error != nil {
+				return error
+			}
+		}
+		return nil
+	})
+}
+
+func (repository Users) Unblock(blockerId, blockedId uint64) error {
+	_, error := repository.db.Exec(
+		"delete from user_blocks where blocker_id = ? and blocked_id = ?", blockerId, blockedId,
+	)
+	return error
+}
+
+// Blocked reports whether either user blocked the other.
+func (repository Users) Blocked(userId, otherId uint64) (bool, error) {
+	var blocks uint64
+	error := repository.db.QueryRow(
+		"select count(*) from user_blocks where (blocker_id = ? and blocked_id = ?) or (blocker_id = ? and blocked_id = ?)",
+		userId, otherId, otherId, userId,
+	).Scan(&blocks)
+	return blocks > 0, error
+}
+
+// Silenced tells whether userId should not hear from otherId: either of them
+// blocked the other, or userId muted otherId.
+func (repository Users) Silenced(userId, otherId uint64) (bool, error) {
+	var silenced uint64
+	error := repository.db.QueryRow(`
+	select
+	(select count(*) from user_blocks where (blocker_id = ? and blocked_id = ?) or (blocker_id = ? and blocked_id = ?)) +
+	(select count(*) from user_mutes where muter_id = ? and muted_id = ?)`,
+		userId, otherId, otherId, userId,
+		userId, otherId,
+	).Scan(&silenced)
+	return silenced > 0, error
+}
+
+func (repository Users) Mute(muterId, mutedId uint64) error {
+	_, error := repository.db.Exec(
+		repository.db.Dialect.InsertIgnore("user_mutes (muter_id, muted_id) values (?, ?)"),
+		muterId, mutedId,
+	)
+	return error
+}
+
+func (repository Users) Unmute(muterId, mutedId uint64) error {
+	_, error := repository.db.Exec(
+		"delete from user_mutes where muter_id = ? and muted_id = ?", muterId, mutedId,
+	)
+	return error
+}
+
+// SearchRelationship returns how viewerId relates to userId.
+func (repository Users) SearchRelationship(viewerId, userId uint64) (models.Relationship, error) {
+	relationship := models.Relationship{UserID: userId}
+	error := repository.db.QueryRow(`
+	select
+	(select count(*) from followers where user_id = ? and follower_id = ?),
+	(select count(*) from followers where user_id = ? and follower_id = ?),
+	(select count(*) from user_blocks where blocker_id = ? and blocked_id = ?),
+	(select count(*) from user_blocks where blocker_id = ? and blocked_id = ?),
+	(select count(*) from user_mutes where muter_id = ? and muted_id = ?),
+	(select count(*) from follow_requests where user_id = ? and follower_id = ?),
+	(select count(*) from follow_requests where user_id = ? and follower_id = ?)`,
+		userId, viewerId,
+		viewerId, userId,
+		viewerId, userId,
+		userId, viewerId,
+		viewerId, userId,
+		userId, viewerId,
+		viewerId, userId,
+	).Scan(
+		&relationship.Following,
+		&relationship.FollowedBy,
+		&relationship.Blocked,
+		&relationship.BlockedBy,
+		&relationship.Muted,
+		&relationship.Requested,
+		&relationship.RequestedBy,
+	)
+	return relationship, error
+}
+
+// SearchMutuals returns the users followed by viewerId who follow userId,
+// in nickname order.
+func (repository Users) SearchMutuals(viewerId, userId uint64, page models.Page) ([]models.User, error) {
+	rows, error := repository.db.Query(`
+	select `+userColumns+`
+	from users u
+	inner join followers mine on mine.user_id = u.id and mine.follower_id = ?
+	inner join followers theirs on theirs.follower_id = u.id and theirs.user_id = ?
+	order by u.nickname
+	limit ? offset ?`,
+		viewerId, userId, page.Limit, page.Offset(),
+	)
+	if error != nil {
+		return nil, error
+	}
+	defer rows.Close()
+
+	var users []models.User
+
+	for rows.Next() {
+		user, error := scanUser(rows)
+		if error != nil {
+			return nil, error
+		}
+		users = append(users, user)
+	}
+	return users, nil
+}
+
 func (repository Users) SearchFollowers(userId uint64) ([]models.User, error) {
 	rows, error := repository.db.Query(`
-	select u.id, u.name, u.nickname, u.email, u.created_at
+	select `+userColumns+`
 	from users u inner join followers s on u.id = s.follower_id where s.user_id = ?
 `, userId)
 	if error != nil {
@@ -184,14 +445,8 @@ func (repository Users) SearchFollowers(userId uint64) ([]models.User, error) {
 
 	var users []models.User
 	for rows.Next() {
-		var user models.User
-		if error = rows.Scan(
-			&user.ID,
-			&user.Name,
-			&user.Nickname,
-			&user.Email,
-			&user.Created_at,
-		); error != nil {
+		user, error := scanUser(rows)
+		if error != nil {
 			return nil, error
 		}
 		users = append(users, user)
@@ -200,7 +455,7 @@ func (repository Users) SearchFollowers(userId uint64) ([]models.User, error) {
 }
 func (repository Users) SearchFollowing(userId uint64) ([]models.User, error) {
 	rows, error := repository.db.Query(`
-	select u.id, u.name, u.nickname, u.email, u.created_at
+	select `+userColumns+`
 	from users u inner join followers s on u.id = s.user_id where s.follower_id = ?
 `, userId)
 
@@ -212,14 +467,8 @@ func (repository Users) SearchFollowing(userId uint64) ([]models.User, error) {
 	var users []models.User
 
 	for rows.Next() {
-		var user models.User
-		if error = rows.Scan(
-			&user.ID,
-			&user.Name,
-			&user.Nickname,
-			&user.Email,
-			&user.Created_at,
-		); error != nil {
+		user, error := scanUser(rows)
+		if error != nil {
 			return nil, error
 		}
 		users = append(users, user)
@@ -256,3 +505,70 @@ func (repository Users) UpdatePassword(userId uint64, password string) error {
 	}
 	return nil
 }
+
+// SearchNicknames returns the users owning the nicknames, compared case-insensitively.
+func (repository Users) SearchNicknames(nicknames []string) ([]models.User, error) {
+	if len(nicknames) == 0 {
+		return nil, nil
+	}
+
+	lowered := make([]string, len(nicknames))
+	for i, nickname := range nicknames {
+		lowered[i] = strings.ToLower(nickname)
+	}
+
+	rows, error := repository.db.Query(
+		"select id, name, nickname, email, created_at from users where lower(nickname) in ("+placeholders(len(lowered))+")",
+		stringArgs(lowered)...,
+	)
+	if error != nil {
+		return nil, error
+	}
+	defer rows.Close()
+
+	var users []models.User
+
+	for rows.Next() {
+		var user models.User
+		if error = rows.Scan(
+			&user.ID,
+			&user.Name,
+			&user.Nickname,
+			&user.Email,
+			&user.Created_at,
+		); error != nil {
+			return nil, error
+		}
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+func scanUser(rows *sql.Rows) (models.User, error) {
+	var user models.User
+	var avatarKey, bannerKey sql.NullString
+	var suspendedAt sql.NullTime
+	if error := rows.Scan(
+		&user.ID,
+		&user.Name,
+		&user.Nickname,
+		&user.Email,
+		&user.IsPrivate,
+		&user.Bio,
+		&user.Location,
+		&user.Website,
+		&avatarKey,
+		&bannerKey,
+		&user.Role,
+		&suspendedAt,
+		&user.Created_at,
+	); error != nil {
+		return models.User{}, error
+	}
+	if suspendedAt.Valid {
+		user.SuspendedAt = &suspendedAt.Time
+	}
+	user.AvatarKey = avatarKey.String
+	user.BannerKey = bannerKey.String
+	return user, nil
+}
@@ -0,0 +1,46 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/wesleywcr/dev-book/api/controllers"
+	"github.com/wesleywcr/dev-book/api/models"
+)
+
+var routesAdmin = []Route{
+	{
+		URI:                   "/admin/users",
+		Method:                http.MethodGet,
+		HandleFunction:        controllers.AdminListUsers,
+		RequiredAuthorization: true,
+		RequiredRole:          models.RoleAdmin,
+	},
+	{
+		URI:                   "/admin/users/{userId}/suspend",
+		Method:                http.MethodPost,
+		HandleFunction:        controllers.AdminSuspendUser,
+		RequiredAuthorization: true,
+		RequiredRole:          models.RoleAdmin,
+	},
+	{
+		URI:                   "/admin/users/{userId}/suspend",
+		Method:                http.MethodDelete,
+		HandleFunction:        controllers.AdminUnsuspendUser,
+		RequiredAuthorization: true,
+		RequiredRole:          models.RoleAdmin,
+	},
+	{
+		URI:                   "/admin/users/{userId}/role",
+		Method:                http.MethodPut,
+		HandleFunction:        controllers.AdminAssignRole,
+		RequiredAuthorization: true,
+		RequiredRole:          models.RoleAdmin,
+	},
+	{
+		URI:                   "/admin/publications/{publicationId}",
+		Method:                http.MethodDelete,
+		HandleFunction:        controllers.AdminDeletePublication,
+		RequiredAuthorization: true,
+		RequiredRole:          models.RoleAdmin,
+	},
+}
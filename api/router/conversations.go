@@ -0,0 +1,34 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/wesleywcr/dev-book/api/controllers"
+)
+
+var routesConversations = []Route{
+	{
+		URI:                   "/conversations",
+		Method:                http.MethodPost,
+		HandleFunction:        controllers.StartConversation,
+		RequiredAuthorization: true,
+	},
+	{
+		URI:                   "/conversations",
+		Method:                http.MethodGet,
+		HandleFunction:        controllers.ListConversations,
+		RequiredAuthorization: true,
+	},
+	{
+		URI:                   "/conversations/{conversationId}/messages",
+		Method:                http.MethodPost,
+		HandleFunction:        controllers.SendMessage,
+		RequiredAuthorization: true,
+	},
+	{
+		URI:                   "/conversations/{conversationId}/messages",
+		Method:                http.MethodGet,
+		HandleFunction:        controllers.ListMessages,
+		RequiredAuthorization: true,
+	},
+}
@@ -6,9 +6,17 @@ import (
 	"github.com/wesleywcr/dev-book/api/controllers"
 )
 
-var routeLogin = Route{
-	URI:                   "/login",
-	Method:                http.MethodPost,
-	HandleFunction:        controllers.Login,
-	RequiredAuthorization: false,
+var routesLogin = []Route{
+	{
+		URI:                   "/login",
+		Method:                http.MethodPost,
+		HandleFunction:        controllers.Login,
+		RequiredAuthorization: false,
+	},
+	{
+		URI:                   "/login/two-factor",
+		Method:                http.MethodPost,
+		HandleFunction:        controllers.LoginTwoFactor,
+		RequiredAuthorization: false,
+	},
 }
@@ -0,0 +1,50 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/wesleywcr/dev-book/api/controllers"
+	"github.com/wesleywcr/dev-book/api/models"
+)
+
+var routesModeration = []Route{
+	{
+		URI:                   "/publications/{publicationId}/report",
+		Method:                http.MethodPost,
+		HandleFunction:        controllers.ReportPublication,
+		RequiredAuthorization: true,
+	},
+	{
+		URI:                   "/users/{userId}/report",
+		Method:                http.MethodPost,
+		HandleFunction:        controllers.ReportUser,
+		RequiredAuthorization: true,
+	},
+	{
+		URI:                   "/users/me/warnings",
+		Method:                http.MethodGet,
+		HandleFunction:        controllers.ListWarnings,
+		RequiredAuthorization: true,
+	},
+	{
+		URI:                   "/moderation/reports",
+		Method:                http.MethodGet,
+		HandleFunction:        controllers.ListReports,
+		RequiredAuthorization: true,
+		RequiredRole:          models.RoleModerator,
+	},
+	{
+		URI:                   "/moderation/reports/{reportId}/actions",
+		Method:                http.MethodPost,
+		HandleFunction:        controllers.ActOnReport,
+		RequiredAuthorization: true,
+		RequiredRole:          models.RoleModerator,
+	},
+	{
+		URI:                   "/moderation/actions",
+		Method:                http.MethodGet,
+		HandleFunction:        controllers.ListModerationActions,
+		RequiredAuthorization: true,
+		RequiredRole:          models.RoleModerator,
+	},
+}
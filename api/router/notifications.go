@@ -0,0 +1,22 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/wesleywcr/dev-book/api/controllers"
+)
+
+var routesNotifications = []Route{
+	{
+		URI:                   "/notifications",
+		Method:                http.MethodGet,
+		HandleFunction:        controllers.ListNotifications,
+		RequiredAuthorization: true,
+	},
+	{
+		URI:                   "/notifications/read",
+		Method:                http.MethodPost,
+		HandleFunction:        controllers.ReadNotifications,
+		RequiredAuthorization: true,
+	},
+}
@@ -55,4 +55,46 @@ var routesPublications = []Route{
 		HandleFunction:        controllers.DeslikePublication,
 		RequiredAuthorization: true,
 	},
+	{
+		URI:                   "/users/me/mentions",
+		Method:                http.MethodGet,
+		HandleFunction:        controllers.SearchMentions,
+		RequiredAuthorization: true,
+	},
+	{
+		URI:                   "/publications/{publicationId}/revisions",
+		Method:                http.MethodGet,
+		HandleFunction:        controllers.ListPublicationRevisions,
+		RequiredAuthorization: true,
+	},
+	{
+		URI:                   "/publications/{publicationId}/restore",
+		Method:                http.MethodPost,
+		HandleFunction:        controllers.RestorePublication,
+		RequiredAuthorization: true,
+	},
+	{
+		URI:                   "/publications/{publicationId}/repost",
+		Method:                http.MethodPost,
+		HandleFunction:        controllers.RepostPublication,
+		RequiredAuthorization: true,
+	},
+	{
+		URI:                   "/publications/{publicationId}/repost",
+		Method:                http.MethodDelete,
+		HandleFunction:        controllers.UndoRepost,
+		RequiredAuthorization: true,
+	},
+	{
+		URI:                   "/publications/{publicationId}/attachments",
+		Method:                http.MethodPost,
+		HandleFunction:        controllers.UploadAttachments,
+		RequiredAuthorization: true,
+	},
+	{
+		URI:                   "/publications/{publicationId}/attachments/{attachmentId}",
+		Method:                http.MethodDelete,
+		HandleFunction:        controllers.DeleteAttachment,
+		RequiredAuthorization: true,
+	},
 }
@@ -1,9 +1,12 @@
 package router
 
 import (
+	"net/http"
+
 	"github.com/gorilla/mux"
 	httpSwagger "github.com/swaggo/http-swagger"
 	_ "github.com/wesleywcr/dev-book/api/docs" // Import generated Swagger docs
+	"github.com/wesleywcr/dev-book/api/storage"
 )
 
 func InitRouter() *mux.Router {
@@ -11,5 +14,9 @@ func InitRouter() *mux.Router {
 
 	r.PathPrefix("/swagger/").Handler(httpSwagger.WrapHandler)
 
+	if local, isLocal := storage.Default.(*storage.Local); isLocal {
+		r.PathPrefix(local.Route()).Handler(local).Methods(http.MethodGet, http.MethodHead)
+	}
+
 	return Config(r)
 }
@@ -5,6 +5,7 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/wesleywcr/dev-book/api/middlewares"
+	"github.com/wesleywcr/dev-book/api/models"
 )
 
 type Route struct {
@@ -12,16 +13,31 @@ type Route struct {
 	Method                string
 	HandleFunction        func(http.ResponseWriter, *http.Request)
 	RequiredAuthorization bool
+	// RequiredRole, when set, restricts the route to users with that role or
+	// a higher one.
+	RequiredRole models.Role
 }
 
 func Config(r *mux.Router) *mux.Router {
 	routes := routesUsers
-	routes = append(routes, routeLogin)
+	routes = append(routes, routesLogin...)
 	routes = append(routes, routesPublications...)
+	routes = append(routes, routesTags...)
+	routes = append(routes, routesNotifications...)
+	routes = append(routes, routesConversations...)
+	routes = append(routes, routesSearch...)
+	routes = append(routes, routesModeration...)
+	routes = append(routes, routesAdmin...)
+	routes = append(routes, routeStream)
 
 	for _, route := range routes {
+		handler := http.HandlerFunc(route.HandleFunction)
+		if route.RequiredRole != "" {
+			handler = middlewares.Authorize(route.RequiredRole, handler)
+		}
+
 		if route.RequiredAuthorization {
-			r.HandleFunc(route.URI, middlewares.Logger(middlewares.Authenticate(route.HandleFunction))).Methods(route.Method)
+			r.HandleFunc(route.URI, middlewares.Logger(middlewares.Authenticate(middlewares.Active(handler)))).Methods(route.Method)
 		} else {
 			r.HandleFunc(route.URI, middlewares.Logger(route.HandleFunction)).Methods(route.Method)
 		}
@@ -0,0 +1,16 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/wesleywcr/dev-book/api/controllers"
+)
+
+var routesSearch = []Route{
+	{
+		URI:                   "/search/publications",
+		Method:                http.MethodGet,
+		HandleFunction:        controllers.SearchPublications,
+		RequiredAuthorization: true,
+	},
+}
@@ -0,0 +1,14 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/wesleywcr/dev-book/api/controllers"
+)
+
+var routeStream = Route{
+	URI:                   "/stream",
+	Method:                http.MethodGet,
+	HandleFunction:        controllers.Stream,
+	RequiredAuthorization: true,
+}
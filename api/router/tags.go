@@ -0,0 +1,22 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/wesleywcr/dev-book/api/controllers"
+)
+
+var routesTags = []Route{
+	{
+		URI:                   "/tags/trending",
+		Method:                http.MethodGet,
+		HandleFunction:        controllers.TrendingTags,
+		RequiredAuthorization: true,
+	},
+	{
+		URI:                   "/tags/{tag}/publications",
+		Method:                http.MethodGet,
+		HandleFunction:        controllers.SearchPublicationsByTag,
+		RequiredAuthorization: true,
+	},
+}
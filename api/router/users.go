@@ -61,10 +61,142 @@ var routesUsers = []Route{
 		HandleFunction:        controllers.SearchFollowing,
 		RequiredAuthorization: true,
 	},
+	{
+		URI:                   "/users/{userId}/relationship",
+		Method:                http.MethodGet,
+		HandleFunction:        controllers.GetRelationship,
+		RequiredAuthorization: true,
+	},
+	{
+		URI:                   "/users/{userId}/mutuals",
+		Method:                http.MethodGet,
+		HandleFunction:        controllers.ListMutuals,
+		RequiredAuthorization: true,
+	},
 	{
 		URI:                   "/users/{userId}/update-password",
 		Method:                http.MethodPost,
 		HandleFunction:        controllers.UpdatePassword,
 		RequiredAuthorization: true,
 	},
+	{
+		URI:                   "/users/{userId}/block",
+		Method:                http.MethodPost,
+		HandleFunction:        controllers.BlockUser,
+		RequiredAuthorization: true,
+	},
+	{
+		URI:                   "/users/{userId}/block",
+		Method:                http.MethodDelete,
+		HandleFunction:        controllers.UnblockUser,
+		RequiredAuthorization: true,
+	},
+	{
+		URI:                   "/users/{userId}/mute",
+		Method:                http.MethodPost,
+		HandleFunction:        controllers.MuteUser,
+		RequiredAuthorization: true,
+	},
+	{
+		URI:                   "/users/{userId}/mute",
+		Method:                http.MethodDelete,
+		HandleFunction:        controllers.UnmuteUser,
+		RequiredAuthorization: true,
+	},
+	{
+		URI:                   "/users/me/suggestions",
+		Method:                http.MethodGet,
+		HandleFunction:        controllers.ListSuggestions,
+		RequiredAuthorization: true,
+	},
+	{
+		URI:                   "/users/me/security-events",
+		Method:                http.MethodGet,
+		HandleFunction:        controllers.ListSecurityEvents,
+		RequiredAuthorization: true,
+	},
+	{
+		URI:                   "/users/me/sessions",
+		Method:                http.MethodGet,
+		HandleFunction:        controllers.ListSessions,
+		RequiredAuthorization: true,
+	},
+	{
+		URI:                   "/users/me/sessions",
+		Method:                http.MethodDelete,
+		HandleFunction:        controllers.RevokeOtherSessions,
+		RequiredAuthorization: true,
+	},
+	{
+		URI:                   "/users/me/sessions/{sessionId}",
+		Method:                http.MethodDelete,
+		HandleFunction:        controllers.RevokeSession,
+		RequiredAuthorization: true,
+	},
+	{
+		URI:                   "/users/me/two-factor",
+		Method:                http.MethodGet,
+		HandleFunction:        controllers.GetTwoFactor,
+		RequiredAuthorization: true,
+	},
+	{
+		URI:                   "/users/me/two-factor",
+		Method:                http.MethodPost,
+		HandleFunction:        controllers.EnrollTwoFactor,
+		RequiredAuthorization: true,
+	},
+	{
+		URI:                   "/users/me/two-factor/confirm",
+		Method:                http.MethodPost,
+		HandleFunction:        controllers.ConfirmTwoFactor,
+		RequiredAuthorization: true,
+	},
+	{
+		URI:                   "/users/me/two-factor",
+		Method:                http.MethodDelete,
+		HandleFunction:        controllers.DisableTwoFactor,
+		RequiredAuthorization: true,
+	},
+	{
+		URI:                   "/users/me/follow-requests",
+		Method:                http.MethodGet,
+		HandleFunction:        controllers.ListFollowRequests,
+		RequiredAuthorization: true,
+	},
+	{
+		URI:                   "/users/me/follow-requests/{followerId}/approve",
+		Method:                http.MethodPost,
+		HandleFunction:        controllers.ApproveFollowRequest,
+		RequiredAuthorization: true,
+	},
+	{
+		URI:                   "/users/me/follow-requests/{followerId}/reject",
+		Method:                http.MethodPost,
+		HandleFunction:        controllers.RejectFollowRequest,
+		RequiredAuthorization: true,
+	},
+	{
+		URI:                   "/users/{userId}/avatar",
+		Method:                http.MethodPost,
+		HandleFunction:        controllers.UploadAvatar,
+		RequiredAuthorization: true,
+	},
+	{
+		URI:                   "/users/{userId}/avatar",
+		Method:                http.MethodDelete,
+		HandleFunction:        controllers.DeleteAvatar,
+		RequiredAuthorization: true,
+	},
+	{
+		URI:                   "/users/{userId}/banner",
+		Method:                http.MethodPost,
+		HandleFunction:        controllers.UploadBanner,
+		RequiredAuthorization: true,
+	},
+	{
+		URI:                   "/users/{userId}/banner",
+		Method:                http.MethodDelete,
+		HandleFunction:        controllers.DeleteBanner,
+		RequiredAuthorization: true,
+	},
 }
@@ -0,0 +1,172 @@
+package search
+
+import (
+	"math"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/wesleywcr/dev-book/api/models"
+)
+
+// titleWeight is how many times a word of the title counts compared to a
+// word of the content.
+const titleWeight = 2
+
+// BM25 parameters, with their usual values.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// Memory is an inverted index of the publications kept in memory and ranked
+// with BM25. Each process has its own copy, so it suits a single instance
+// of the API, SQLite setups and tests.
+type Memory struct {
+	mutex     sync.RWMutex
+	documents map[uint64]document
+	// postings maps each word to the frequency it has in each publication.
+	postings    map[string]map[uint64]int
+	totalLength int
+}
+
+type document struct {
+	authorID  uint64
+	tags      []string
+	createdAt time.Time
+	length    int
+	terms     map[string]int
+}
+
+func NewMemory() *Memory {
+	return &Memory{documents: map[uint64]document{}, postings: map[string]map[uint64]int{}}
+}
+
+// Index adds the publication to the index, replacing a previous version.
+// Plain reposts have no text of their own and are left out.
+func (memory *Memory) Index(publication models.Publication) error {
+	memory.mutex.Lock()
+	defer memory.mutex.Unlock()
+
+	memory.remove(publication.ID)
+	if publication.IsRepost() {
+		return nil
+	}
+
+	terms := map[string]int{}
+	for _, token := range tokenize(publication.Title) {
+		terms[token] += titleWeight
+	}
+	for _, token := range tokenize(publication.Content) {
+		terms[token]++
+	}
+
+	current := document{
+		authorID:  publication.AuthorID,
+		tags:      publication.Hashtags(),
+		createdAt: publication.Created_at,
+		terms:     terms,
+	}
+	if current.createdAt.IsZero() {
+		current.createdAt = time.Now()
+	}
+	for term, frequency := range terms {
+		current.length += frequency
+		if memory.postings[term] == nil {
+			memory.postings[term] = map[uint64]int{}
+		}
+		memory.postings[term][publication.ID] = frequency
+	}
+
+	memory.documents[publication.ID] = current
+	memory.totalLength += current.length
+	return nil
+}
+
+// Remove drops the publication from the index.
+func (memory *Memory) Remove(publicationId uint64) error {
+	memory.mutex.Lock()
+	defer memory.mutex.Unlock()
+
+	memory.remove(publicationId)
+	return nil
+}
+
+func (memory *Memory) remove(publicationId uint64) {
+	previous, found := memory.documents[publicationId]
+	if !found {
+		return
+	}
+
+	for term := range previous.terms {
+		delete(memory.postings[term], publicationId)
+		if len(memory.postings[term]) == 0 {
+			delete(memory.postings, term)
+		}
+	}
+	memory.totalLength -= previous.length
+	delete(memory.documents, publicationId)
+}
+
+// Search returns the IDs of the publications containing any word of the
+// query and passing its filters, the most relevant first.
+func (memory *Memory) Search(query models.SearchQuery) ([]uint64, error) {
+	memory.mutex.RLock()
+	defer memory.mutex.RUnlock()
+
+	if len(memory.documents) == 0 {
+		return nil, nil
+	}
+	averageLength := float64(memory.totalLength) / float64(len(memory.documents))
+
+	scores := map[uint64]float64{}
+	for _, term := range tokenize(query.Terms) {
+		postings := memory.postings[term]
+		if len(postings) == 0 {
+			continue
+		}
+		idf := math.Log(1 + (float64(len(memory.documents))-float64(len(postings))+0.5)/(float64(len(postings))+0.5))
+
+		for publicationId, frequency := range postings {
+			current := memory.documents[publicationId]
+			if !matches(current, query) {
+				continue
+			}
+			tf := float64(frequency)
+			scores[publicationId] += idf * tf * (bm25K1 + 1) /
+				(tf + bm25K1*(1-bm25B+bm25B*float64(current.length)/averageLength))
+		}
+	}
+
+	ids := make([]uint64, 0, len(scores))
+	for publicationId := range scores {
+		ids = append(ids, publicationId)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if scores[ids[i]] != scores[ids[j]] {
+			return scores[ids[i]] > scores[ids[j]]
+		}
+		return ids[i] > ids[j]
+	})
+
+	offset := min(query.Page.Offset(), uint64(len(ids)))
+	end := min(offset+query.Page.Limit, uint64(len(ids)))
+	return ids[offset:end], nil
+}
+
+func matches(current document, query models.SearchQuery) bool {
+	if query.AuthorID != 0 && current.authorID != query.AuthorID {
+		return false
+	}
+	if query.Tag != "" && !slices.Contains(current.tags, query.Tag) {
+		return false
+	}
+	if !query.From.IsZero() && current.createdAt.Before(query.From) {
+		return false
+	}
+	if !query.Until.IsZero() && !current.createdAt.Before(query.Until) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,166 @@
+package search
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/wesleywcr/dev-book/api/models"
+)
+
+var firstPage = models.Page{Number: 1, Limit: 20}
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected []string
+	}{
+		{"Olá, Mundo!", []string{"olá", "mundo"}},
+		{"Go 1.23 e #golang", []string{"go", "23", "golang"}},
+		{"a b c", []string{}},
+		{"  ", []string{}},
+		{"ação—reação", []string{"ação", "reação"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.text, func(t *testing.T) {
+			if tokens := tokenize(test.text); !slices.Equal(tokens, test.expected) {
+				t.Errorf("tokenize(%q) = %q, want %q", test.text, tokens, test.expected)
+			}
+		})
+	}
+}
+
+func newTestMemory(t *testing.T, publications ...models.Publication) *Memory {
+	t.Helper()
+	memory := NewMemory()
+	for _, publication := range publications {
+		if error := memory.Index(publication); error != nil {
+			t.Fatal(error)
+		}
+	}
+	return memory
+}
+
+func search(t *testing.T, memory *Memory, query models.SearchQuery) []uint64 {
+	t.Helper()
+	if query.Page.Limit == 0 {
+		query.Page = firstPage
+	}
+	ids, error := memory.Search(query)
+	if error != nil {
+		t.Fatal(error)
+	}
+	return ids
+}
+
+func TestMemoryRanking(t *testing.T) {
+	memory := newTestMemory(t,
+		models.Publication{ID: 1, Title: "Receitas", Content: "Um bolo de cenoura com cobertura de chocolate"},
+		models.Publication{ID: 2, Title: "Golang", Content: "Concorrência em Go com canais e goroutines"},
+		models.Publication{ID: 3, Title: "Go", Content: "Go go go, tudo sobre Go"},
+		models.Publication{ID: 4, Title: "Canais", Content: "Canais de Go explicados"},
+		models.Publication{ID: 5, Title: "Nada", Content: "Outro assunto"},
+		models.Publication{ID: 6, Title: "Nada", Content: "Outro assunto"},
+	)
+
+	tests := []struct {
+		name     string
+		terms    string
+		expected []uint64
+	}{
+		// more occurrences of a word rank higher
+		{name: "frequency", terms: "go", expected: []uint64{3, 4, 2}},
+		// a rarer word weighs more than a common one
+		{name: "rarity", terms: "go goroutines", expected: []uint64{2, 3, 4}},
+		// words of the title count twice
+		{name: "title", terms: "canais", expected: []uint64{4, 2}},
+		{name: "case and accents", terms: "CONCORRÊNCIA", expected: []uint64{2}},
+		// equal scores go newest first
+		{name: "tie", terms: "assunto", expected: []uint64{6, 5}},
+		{name: "no match", terms: "python", expected: []uint64{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if ids := search(t, memory, models.SearchQuery{Terms: test.terms}); !slices.Equal(ids, test.expected) {
+				t.Errorf("Search(%q) = %v, want %v", test.terms, ids, test.expected)
+			}
+		})
+	}
+}
+
+func TestMemoryFiltersAndPages(t *testing.T) {
+	day := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	memory := newTestMemory(t,
+		models.Publication{ID: 1, AuthorID: 10, Content: "notas de go #golang", Created_at: day.AddDate(0, 0, -2)},
+		models.Publication{ID: 2, AuthorID: 20, Content: "notas de go #python", Created_at: day.AddDate(0, 0, -1)},
+		models.Publication{ID: 3, AuthorID: 10, Content: "notas de go #GoLang", Created_at: day},
+		// a plain repost has no text of its own
+		models.Publication{ID: 4, AuthorID: 20, OriginalID: 1, Created_at: day},
+	)
+
+	tests := []struct {
+		name     string
+		query    models.SearchQuery
+		expected []uint64
+	}{
+		{name: "all", query: models.SearchQuery{}, expected: []uint64{3, 2, 1}},
+		{name: "author", query: models.SearchQuery{AuthorID: 10}, expected: []uint64{3, 1}},
+		{name: "tag", query: models.SearchQuery{Tag: "golang"}, expected: []uint64{3, 1}},
+		{name: "from", query: models.SearchQuery{From: day.AddDate(0, 0, -1)}, expected: []uint64{3, 2}},
+		{name: "until", query: models.SearchQuery{Until: day.AddDate(0, 0, -1)}, expected: []uint64{1}},
+		{name: "second page", query: models.SearchQuery{Page: models.Page{Number: 2, Limit: 2}}, expected: []uint64{1}},
+		{name: "past the end", query: models.SearchQuery{Page: models.Page{Number: 3, Limit: 2}}, expected: []uint64{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.query.Terms = "notas"
+			if ids := search(t, memory, test.query); !slices.Equal(ids, test.expected) {
+				t.Errorf("Search(%+v) = %v, want %v", test.query, ids, test.expected)
+			}
+		})
+	}
+}
+
+func TestMemoryUpdateAndRemove(t *testing.T) {
+	memory := newTestMemory(t,
+		models.Publication{ID: 1, Title: "Primeira", Content: "texto sobre rust"},
+		models.Publication{ID: 2, Title: "Segunda", Content: "mais texto sobre rust"},
+	)
+
+	// an edit replaces the words of the previous version
+	if error := memory.Index(models.Publication{ID: 1, Title: "Primeira", Content: "texto sobre zig"}); error != nil {
+		t.Fatal(error)
+	}
+	if ids := search(t, memory, models.SearchQuery{Terms: "rust"}); !slices.Equal(ids, []uint64{2}) {
+		t.Errorf("rust after the edit = %v, want [2]", ids)
+	}
+	if ids := search(t, memory, models.SearchQuery{Terms: "zig"}); !slices.Equal(ids, []uint64{1}) {
+		t.Errorf("zig after the edit = %v, want [1]", ids)
+	}
+
+	if error := memory.Remove(2); error != nil {
+		t.Fatal(error)
+	}
+	if ids := search(t, memory, models.SearchQuery{Terms: "rust texto"}); !slices.Equal(ids, []uint64{1}) {
+		t.Errorf("after removing 2 = %v, want [1]", ids)
+	}
+	if _, found := memory.postings["rust"]; found {
+		t.Error("the postings of rust were kept after removing its last publication")
+	}
+	if error := memory.Remove(2); error != nil {
+		t.Errorf("removing twice: %v", error)
+	}
+
+	if error := memory.Remove(1); error != nil {
+		t.Fatal(error)
+	}
+	if memory.totalLength != 0 || len(memory.postings) != 0 {
+		t.Errorf("empty index keeps length %d and %d postings", memory.totalLength, len(memory.postings))
+	}
+	if ids := search(t, memory, models.SearchQuery{Terms: "texto"}); len(ids) != 0 {
+		t.Errorf("search of an empty index = %v", ids)
+	}
+}
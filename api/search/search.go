@@ -0,0 +1,57 @@
+// Package search keeps an in-process full-text index of the publications,
+// used when the database offers no full-text search of its own.
+package search
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/wesleywcr/dev-book/api/config"
+	"github.com/wesleywcr/dev-book/api/models"
+)
+
+// Default is the in-process index, set by Open when the memory backend is
+// configured and nil when searches go to the database.
+var Default *Memory
+
+// Open builds Default from the publications returned by load when the memory
+// backend is configured, or picked by auto for SQLite.
+func Open(load func() ([]models.Publication, error)) error {
+	backend := config.Settings.Search.Backend
+	if backend == "auto" && config.Settings.Database.Driver == "sqlite" {
+		backend = "memory"
+	}
+	if backend != "memory" {
+		Default = nil
+		return nil
+	}
+
+	publications, error := load()
+	if error != nil {
+		return error
+	}
+
+	memory := NewMemory()
+	for _, publication := range publications {
+		if error = memory.Index(publication); error != nil {
+			return error
+		}
+	}
+	Default = memory
+	return nil
+}
+
+// tokenize splits text into lowercase words of at least two letters or digits.
+func tokenize(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	tokens := words[:0]
+	for _, word := range words {
+		if len([]rune(word)) >= 2 {
+			tokens = append(tokens, word)
+		}
+	}
+	return tokens
+}
@@ -0,0 +1,115 @@
+package security
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// The codes follow RFC 6238 with the parameters every authenticator app
+// supports: HMAC-SHA1 over 30 second steps, truncated to 6 digits.
+const (
+	totpDigits = 6
+	totpPeriod = 30
+	// totpSkew is how many steps before or after the current one are
+	// accepted, for clocks running a little off.
+	totpSkew = 1
+)
+
+var secretEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// NewTOTPSecret returns a random 160-bit secret, base32 encoded as the
+// authenticator apps expect it.
+func NewTOTPSecret() (string, error) {
+	secret := make([]byte, 20)
+	if _, error := rand.Read(secret); error != nil {
+		return "", error
+	}
+	return secretEncoding.EncodeToString(secret), nil
+}
+
+// TOTPURI returns the otpauth URI that authenticator apps read, usually from
+// a QR code, to register the secret of account.
+func TOTPURI(issuer, account, secret string) string {
+	parameters := url.Values{}
+	parameters.Set("secret", secret)
+	parameters.Set("issuer", issuer)
+	parameters.Set("algorithm", "SHA1")
+	parameters.Set("digits", fmt.Sprint(totpDigits))
+	parameters.Set("period", fmt.Sprint(totpPeriod))
+	return "otpauth://totp/" + url.PathEscape(issuer+":"+account) + "?" + parameters.Encode()
+}
+
+// VerifyTOTP checks code against the secret at now, returning the time step
+// it matched. Only steps after last are accepted, so a code cannot be used
+// twice.
+func VerifyTOTP(secret, code string, now time.Time, last int64) (int64, bool) {
+	key, error := secretEncoding.DecodeString(strings.ToUpper(secret))
+	if error != nil || len(code) != totpDigits {
+		return 0, false
+	}
+
+	current := now.Unix() / totpPeriod
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if step <= last {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(totp(key, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+func totp(key []byte, step int64) string {
+	message := make([]byte, 8)
+	binary.BigEndian.PutUint64(message, uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(message)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000)
+}
+
+// NewChallengeID returns a random ID for the challenge of a two-factor login.
+func NewChallengeID() (string, error) {
+	random := make([]byte, 16)
+	if _, error := rand.Read(random); error != nil {
+		return "", error
+	}
+	return hex.EncodeToString(random), nil
+}
+
+// NewRecoveryCodes returns count random one-time codes as xxxxx-xxxxx.
+func NewRecoveryCodes(count int) ([]string, error) {
+	codes := make([]string, count)
+	for i := range codes {
+		random := make([]byte, 10)
+		if _, error := rand.Read(random); error != nil {
+			return nil, error
+		}
+		code := strings.ToLower(secretEncoding.EncodeToString(random))[:10]
+		codes[i] = code[:5] + "-" + code[5:]
+	}
+	return codes, nil
+}
+
+// HashRecoveryCode returns the hash a recovery code is stored as. The codes
+// are random, so a fast hash is enough and lets them be looked up directly.
+// Case, spaces and dashes are ignored.
+func HashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/wesleywcr/dev-book/api/models"
+)
+
+// ListAccounts returns the users, newest first, filtered by role and, when
+// suspended is not nil, by whether their account is suspended.
+func (service UserService) ListAccounts(role models.Role, suspended *bool, page models.Page) ([]models.User, error) {
+	if role != "" && !role.Valid() {
+		return nil, invalid(errors.New("O papel deve ser user, moderator ou admin"))
+	}
+	return service.withURLs(service.users.SearchAll(role, suspended, page))
+}
+
+// Suspend suspends the account of userId on behalf of the administrator
+// actorId, or lifts the suspension when suspended is false. Administrators
+// cannot be suspended.
+func (service UserService) Suspend(actorId, userId uint64, suspended bool) (models.User, error) {
+	if actorId == userId {
+		return models.User{}, forbidden("Não é possível suspender a sua própria conta")
+	}
+	user, error := service.existing(userId)
+	if error != nil {
+		return models.User{}, error
+	}
+	if user.Role == models.RoleAdmin {
+		return models.User{}, forbidden("Não é possível suspender um administrador")
+	}
+
+	if error = service.users.Suspend(userId, suspended); error != nil {
+		return models.User{}, error
+	}
+	return service.Get(userId)
+}
+
+// AssignRole gives userId a new role on behalf of the administrator actorId,
+// who cannot change their own.
+func (service UserService) AssignRole(actorId, userId uint64, role models.Role) (models.User, error) {
+	if !role.Valid() {
+		return models.User{}, invalid(errors.New("O papel deve ser user, moderator ou admin"))
+	}
+	if actorId == userId {
+		return models.User{}, forbidden("Não é possível alterar o seu próprio papel")
+	}
+	if _, error := service.existing(userId); error != nil {
+		return models.User{}, error
+	}
+
+	if error := service.users.UpdateRole(userId, role); error != nil {
+		return models.User{}, error
+	}
+	return service.Get(userId)
+}
+
+// Remove deletes any publication, whoever its author, on behalf of the staff.
+func (service PublicationService) Remove(publicationId uint64) error {
+	if _, error := service.find(publicationId); error != nil {
+		return error
+	}
+	if error := service.publications.Delete(publicationId); error != nil {
+		return error
+	}
+	return service.searchIndex.Remove(publicationId)
+}
@@ -0,0 +1,180 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/wesleywcr/dev-book/api/media"
+	"github.com/wesleywcr/dev-book/api/models"
+	"github.com/wesleywcr/dev-book/api/storage"
+)
+
+// MaxAttachments is how many images a publication can hold.
+const MaxAttachments = 4
+
+// thumbnailSide bounds the width and height of the thumbnails, in pixels.
+const thumbnailSide = 320
+
+// AttachmentRepository is the storage of the images attached to publications.
+type AttachmentRepository interface {
+	Create(attachment models.Attachment) (uint64, error)
+	SearchPerId(attachmentId uint64) (models.Attachment, error)
+	Count(publicationId uint64) (int, error)
+	SearchByPublications(publicationIds []uint64) (map[uint64][]models.Attachment, error)
+	Delete(attachmentId uint64) error
+}
+
+func attachAttachments(attachments AttachmentRepository, files storage.Storage, publications []models.Publication) ([]models.Publication, error) {
+	ids := make([]uint64, len(publications))
+	for i, publication := range publications {
+		ids[i] = publication.ID
+	}
+
+	byPublication, error := attachments.SearchByPublications(ids)
+	if error != nil {
+		return nil, error
+	}
+	for i := range publications {
+		publications[i].Attachments = byPublication[publications[i].ID]
+		for j := range publications[i].Attachments {
+			withURLs(files, &publications[i].Attachments[j])
+		}
+	}
+	return publications, nil
+}
+
+func withURLs(files storage.Storage, attachment *models.Attachment) {
+	attachment.URL = files.URL(attachment.Key)
+	attachment.ThumbnailURL = files.URL(attachment.ThumbnailKey)
+}
+
+// Attach stores the uploaded images, with their thumbnails, in a publication
+// of actorId.
+func (service PublicationService) Attach(ctx context.Context, actorId, publicationId uint64, uploads []models.Upload) ([]models.Attachment, error) {
+	publication, error := service.find(publicationId)
+	if error != nil {
+		return nil, error
+	}
+	if publication.AuthorID != actorId {
+		return nil, forbidden("Não é possível anexar imagens a uma publicação que não seja a sua")
+	}
+	if publication.IsRepost() {
+		return nil, forbidden("Não é possível anexar imagens a um repost")
+	}
+
+	if len(uploads) == 0 {
+		return nil, invalid(errors.New("Envie ao menos uma imagem no campo images"))
+	}
+	count, error := service.attachments.Count(publicationId)
+	if error != nil {
+		return nil, error
+	}
+	if count+len(uploads) > MaxAttachments {
+		return nil, invalid(fmt.Errorf("Uma publicação pode ter no máximo %d imagens", MaxAttachments))
+	}
+
+	images := make([]media.Image, len(uploads))
+	for i, upload := range uploads {
+		if images[i], error = media.Inspect(upload.Content); error != nil {
+			return nil, invalid(fmt.Errorf("%s: %w", upload.Filename, error))
+		}
+	}
+
+	var attachments []models.Attachment
+	for i, upload := range uploads {
+		attachment, error := service.store(ctx, publicationId, upload, images[i])
+		if error != nil {
+			return nil, error
+		}
+		attachments = append(attachments, attachment)
+	}
+	return attachments, nil
+}
+
+// store saves an inspected image and its thumbnail, and records them.
+func (service PublicationService) store(ctx context.Context, publicationId uint64, upload models.Upload, image media.Image) (models.Attachment, error) {
+	thumbnail, error := media.Thumbnail(upload.Content, thumbnailSide)
+	if error != nil {
+		return models.Attachment{}, invalid(fmt.Errorf("%s: %w", upload.Filename, error))
+	}
+
+	name, error := randomName()
+	if error != nil {
+		return models.Attachment{}, error
+	}
+	attachment := models.Attachment{
+		PublicationID: publicationId,
+		Key:           fmt.Sprintf("publications/%d/%s.%s", publicationId, name, image.Extension),
+		ThumbnailKey:  fmt.Sprintf("publications/%d/%s_thumb.jpg", publicationId, name),
+		ContentType:   image.ContentType,
+		Size:          len(upload.Content),
+		Width:         image.Width,
+		Height:        image.Height,
+	}
+
+	if error = service.files.Put(ctx, attachment.Key, upload.Content, image.ContentType); error != nil {
+		return models.Attachment{}, error
+	}
+	if error = service.files.Put(ctx, attachment.ThumbnailKey, thumbnail, "image/jpeg"); error != nil {
+		service.removeFiles(ctx, attachment)
+		return models.Attachment{}, error
+	}
+
+	attachmentId, error := service.attachments.Create(attachment)
+	if error != nil {
+		service.removeFiles(ctx, attachment)
+		return models.Attachment{}, error
+	}
+	if attachment, error = service.attachments.SearchPerId(attachmentId); error != nil {
+		return models.Attachment{}, error
+	}
+	withURLs(service.files, &attachment)
+	return attachment, nil
+}
+
+// Detach removes an image from a publication of actorId.
+func (service PublicationService) Detach(ctx context.Context, actorId, publicationId, attachmentId uint64) error {
+	publication, error := service.find(publicationId)
+	if error != nil {
+		return error
+	}
+	if publication.AuthorID != actorId {
+		return forbidden("Não é possível remover imagens de uma publicação que não seja a sua")
+	}
+
+	attachment, error := service.attachments.SearchPerId(attachmentId)
+	if error != nil {
+		return error
+	}
+	if attachment.ID == 0 || attachment.PublicationID != publicationId {
+		return notFound("Anexo não encontrado")
+	}
+
+	if error = service.attachments.Delete(attachmentId); error != nil {
+		return error
+	}
+	service.removeFiles(ctx, attachment)
+	return nil
+}
+
+// removeFiles deletes the files of an attachment, logging failures: an
+// orphan file only wastes space.
+func (service PublicationService) removeFiles(ctx context.Context, attachment models.Attachment) {
+	for _, key := range []string{attachment.Key, attachment.ThumbnailKey} {
+		if error := service.files.Delete(ctx, key); error != nil {
+			slog.Warn("file not deleted", "key", key, "error", error)
+		}
+	}
+}
+
+func randomName() (string, error) {
+	name := make([]byte, 16)
+	if _, error := rand.Read(name); error != nil {
+		return "", error
+	}
+	return hex.EncodeToString(name), nil
+}
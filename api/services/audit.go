@@ -0,0 +1,44 @@
+package services
+
+import "github.com/wesleywcr/dev-book/api/models"
+
+// AuditRepository is the storage of the audit log.
+type AuditRepository interface {
+	Create(event models.AuditEvent) error
+	SearchByUser(userId uint64, page models.Page) ([]models.AuditEvent, error)
+}
+
+// AuditService keeps the log of security-relevant actions, such as logins,
+// password changes and the actions of the staff.
+type AuditService struct {
+	events AuditRepository
+}
+
+func NewAuditService(events AuditRepository) *AuditService {
+	return &AuditService{events}
+}
+
+// Record appends an event to the audit log.
+func (service AuditService) Record(event models.AuditEvent) error {
+	event.Prepare()
+	return service.events.Create(event)
+}
+
+// SecurityEvents returns the recent activity on the account of userId. The
+// actions another user took on it, as a suspension by the staff, do not tell
+// who nor from where; failed logins, which have no actor, do.
+func (service AuditService) SecurityEvents(userId uint64, page models.Page) ([]models.AuditEvent, error) {
+	events, error := service.events.SearchByUser(userId, page)
+	if error != nil {
+		return nil, error
+	}
+
+	for i := range events {
+		if events[i].ActorID != 0 && events[i].ActorID != userId {
+			events[i].ActorID = 0
+			events[i].IP = ""
+			events[i].UserAgent = ""
+		}
+	}
+	return events, nil
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/wesleywcr/dev-book/api/models"
+)
+
+type fakeAudit struct {
+	events []models.AuditEvent
+}
+
+func (fake *fakeAudit) Create(event models.AuditEvent) error {
+	fake.events = append(fake.events, event)
+	return nil
+}
+
+func (fake *fakeAudit) SearchByUser(userId uint64, page models.Page) ([]models.AuditEvent, error) {
+	var events []models.AuditEvent
+	for _, event := range fake.events {
+		if event.TargetID == userId {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func TestAuditServiceSecurityEvents(t *testing.T) {
+	tests := []struct {
+		name     string
+		event    models.AuditEvent
+		expected models.AuditEvent
+	}{
+		{
+			name:     "own action",
+			event:    models.AuditEvent{ActorID: 1, Action: models.AuditLogin, TargetID: 1, IP: "10.0.0.1", UserAgent: "curl"},
+			expected: models.AuditEvent{ActorID: 1, Action: models.AuditLogin, TargetID: 1, IP: "10.0.0.1", UserAgent: "curl"},
+		},
+		{
+			// the user learns about the suspension, not who suspended them
+			name:     "action of the staff",
+			event:    models.AuditEvent{ActorID: 9, Action: models.AuditUserSuspended, TargetID: 1, IP: "10.0.0.9", UserAgent: "firefox"},
+			expected: models.AuditEvent{Action: models.AuditUserSuspended, TargetID: 1},
+		},
+		{
+			// failed logins have no actor and tell where they came from
+			name:     "failed login",
+			event:    models.AuditEvent{Action: models.AuditLoginFailed, TargetID: 1, IP: "10.0.0.7", UserAgent: "bot"},
+			expected: models.AuditEvent{Action: models.AuditLoginFailed, TargetID: 1, IP: "10.0.0.7", UserAgent: "bot"},
+		},
+		{
+			name:     "long user agent",
+			event:    models.AuditEvent{ActorID: 1, Action: models.AuditLogin, TargetID: 1, UserAgent: strings.Repeat("a", 300)},
+			expected: models.AuditEvent{ActorID: 1, Action: models.AuditLogin, TargetID: 1, UserAgent: strings.Repeat("a", 255)},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := NewAuditService(&fakeAudit{})
+			if error := service.Record(test.event); error != nil {
+				t.Fatal(error)
+			}
+
+			events, error := service.SecurityEvents(1, models.Page{Number: 1, Limit: 20})
+			if error != nil {
+				t.Fatal(error)
+			}
+			if len(events) != 1 || events[0] != test.expected {
+				t.Errorf("SecurityEvents = %+v, want %+v", events, test.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"github.com/wesleywcr/dev-book/api/events"
+	"github.com/wesleywcr/dev-book/api/models"
+)
+
+// ConversationRepository is the storage ConversationService depends on.
+type ConversationRepository interface {
+	SearchBetween(userId, otherId uint64) (uint64, error)
+	Create(userId, otherId uint64) (uint64, error)
+	SearchParticipants(conversationId uint64) ([]uint64, error)
+	SearchPerId(conversationId, userId uint64) (models.Conversation, error)
+	Search(userId uint64, page models.Page) ([]models.Conversation, error)
+	CreateMessage(message models.Message) (models.Message, error)
+	SearchMessages(conversationId uint64, page models.Page) ([]models.Message, error)
+	MarkRead(conversationId, userId, messageId uint64) error
+}
+
+// ConversationService owns the rules about direct messages: only the
+// participants of a conversation can read or write in it.
+type ConversationService struct {
+	conversations ConversationRepository
+	users         UserRepository
+	broker        events.Broker
+}
+
+func NewConversationService(conversations ConversationRepository, users UserRepository, broker events.Broker) *ConversationService {
+	return &ConversationService{conversations, users, broker}
+}
+
+// Start returns the conversation between userId and otherId, creating it when
+// they never talked; created reports whether it is new.
+func (service ConversationService) Start(userId, otherId uint64) (conversation models.Conversation, created bool, error error) {
+	if userId == otherId {
+		return models.Conversation{}, false, forbidden("Não é possível iniciar uma conversa com você mesmo")
+	}
+
+	other, error := service.users.SearchPerId(otherId)
+	if error != nil {
+		return models.Conversation{}, false, error
+	}
+	if other.ID == 0 {
+		return models.Conversation{}, false, notFound("Usuário não encontrado")
+	}
+	if error = ensureNotBlocked(service.users, userId, otherId); error != nil {
+		return models.Conversation{}, false, error
+	}
+
+	conversationId, error := service.conversations.SearchBetween(userId, otherId)
+	if error != nil {
+		return models.Conversation{}, false, error
+	}
+	if conversationId == 0 {
+		if conversationId, error = service.conversations.Create(userId, otherId); error != nil {
+			return models.Conversation{}, false, error
+		}
+		created = true
+	}
+
+	conversation, error = service.conversations.SearchPerId(conversationId, userId)
+	if error != nil {
+		return models.Conversation{}, false, error
+	}
+	if conversation.LastMessage != nil {
+		preview := conversation.LastMessage.Preview()
+		conversation.LastMessage = &preview
+	}
+	return conversation, created, nil
+}
+
+// List returns the conversations of userId with a preview of their last message.
+func (service ConversationService) List(userId uint64, page models.Page) ([]models.Conversation, error) {
+	conversations, error := service.conversations.Search(userId, page)
+	if error != nil {
+		return nil, error
+	}
+
+	for i := range conversations {
+		if conversations[i].LastMessage != nil {
+			preview := conversations[i].LastMessage.Preview()
+			conversations[i].LastMessage = &preview
+		}
+	}
+	return conversations, nil
+}
+
+// Send stores a message of userId in the conversation and streams it to the
+// other participants.
+func (service ConversationService) Send(userId, conversationId uint64, message models.Message) (models.Message, error) {
+	participants, error := service.participants(userId, conversationId)
+	if error != nil {
+		return models.Message{}, error
+	}
+	for _, participant := range participants {
+		if participant == userId {
+			continue
+		}
+		if error = ensureNotBlocked(service.users, userId, participant); error != nil {
+			return models.Message{}, error
+		}
+	}
+
+	message.ConversationID = conversationId
+	message.SenderID = userId
+	if error = message.Prepare(); error != nil {
+		return models.Message{}, invalid(error)
+	}
+
+	message, error = service.conversations.CreateMessage(message)
+	if error != nil {
+		return models.Message{}, error
+	}
+
+	for _, participant := range participants {
+		if participant != userId {
+			publish(service.broker, participant, events.Event{Type: events.TypeMessage, Data: message, Actor: userId})
+		}
+	}
+	return message, nil
+}
+
+// Messages returns the history of the conversation, newest first, and marks
+// what userId just received as read.
+func (service ConversationService) Messages(userId, conversationId uint64, page models.Page) ([]models.Message, error) {
+	if _, error := service.participants(userId, conversationId); error != nil {
+		return nil, error
+	}
+
+	messages, error := service.conversations.SearchMessages(conversationId, page)
+	if error != nil {
+		return nil, error
+	}
+
+	if len(messages) > 0 {
+		if error = service.conversations.MarkRead(conversationId, userId, messages[0].ID); error != nil {
+			return nil, error
+		}
+	}
+	return messages, nil
+}
+
+// participants returns the participants of the conversation, failing unless
+// userId is one of them.
+func (service ConversationService) participants(userId, conversationId uint64) ([]uint64, error) {
+	participants, error := service.conversations.SearchParticipants(conversationId)
+	if error != nil {
+		return nil, error
+	}
+	if len(participants) == 0 {
+		return nil, notFound("Conversa não encontrada")
+	}
+
+	for _, participant := range participants {
+		if participant == userId {
+			return participants, nil
+		}
+	}
+	return nil, forbidden("Você não participa desta conversa")
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/wesleywcr/dev-book/api/events"
+	"github.com/wesleywcr/dev-book/api/models"
+)
+
+// fakeConversations holds the conversations by the pair of their participants.
+type fakeConversations struct {
+	ConversationRepository
+	pairs map[pair]uint64
+}
+
+func (fake *fakeConversations) SearchBetween(userId, otherId uint64) (uint64, error) {
+	return fake.pairs[pair{min(userId, otherId), max(userId, otherId)}], nil
+}
+
+func (fake *fakeConversations) Create(userId, otherId uint64) (uint64, error) {
+	conversationId := uint64(len(fake.pairs) + 100)
+	fake.pairs[pair{min(userId, otherId), max(userId, otherId)}] = conversationId
+	return conversationId, nil
+}
+
+func (fake *fakeConversations) SearchPerId(conversationId, userId uint64) (models.Conversation, error) {
+	return models.Conversation{ID: conversationId}, nil
+}
+
+// conversationUsers knows the users and who blocked whom.
+type conversationUsers struct {
+	UserRepository
+	users  map[uint64]models.User
+	blocks map[pair]bool
+}
+
+func (fake conversationUsers) SearchPerId(ID uint64) (models.User, error) {
+	return fake.users[ID], nil
+}
+
+func (fake conversationUsers) Blocked(userId, otherId uint64) (bool, error) {
+	return fake.blocks[pair{userId, otherId}] || fake.blocks[pair{otherId, userId}], nil
+}
+
+func TestConversationServiceStart(t *testing.T) {
+	tests := []struct {
+		name           string
+		otherId        uint64
+		kind           error
+		conversationId uint64
+		created        bool
+	}{
+		{name: "self", otherId: 1, kind: ErrForbidden},
+		{name: "missing user", otherId: 99, kind: ErrNotFound},
+		{name: "blocked user", otherId: 3, kind: ErrForbidden},
+		{name: "existing conversation", otherId: 2, conversationId: 7},
+		{name: "new conversation", otherId: 4, conversationId: 101, created: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			users := conversationUsers{
+				users: map[uint64]models.User{
+					1: {ID: 1, Nickname: "ana"},
+					2: {ID: 2, Nickname: "bia"},
+					3: {ID: 3, Nickname: "caio"},
+					4: {ID: 4, Nickname: "duda"},
+				},
+				blocks: map[pair]bool{{1, 3}: true},
+			}
+			conversations := &fakeConversations{pairs: map[pair]uint64{{1, 2}: 7}}
+			service := NewConversationService(conversations, users, events.NewMemoryBroker(1))
+
+			conversation, created, error := service.Start(1, test.otherId)
+			if kindOf(error) != test.kind {
+				t.Fatalf("Start = %v, want %v", error, test.kind)
+			}
+			if conversation.ID != test.conversationId || created != test.created {
+				t.Errorf("Start = conversation %d, created %v, want %d, %v", conversation.ID, created, test.conversationId, test.created)
+			}
+			if test.kind != nil && len(conversations.pairs) != 1 {
+				t.Errorf("a conversation was created: %v", conversations.pairs)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package services
+
+import "errors"
+
+// Kinds of failure a service can report. Each transport (HTTP, CLI, jobs)
+// translates them into its own status codes.
+var (
+	ErrInvalid      = errors.New("invalid")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	// ErrUnprocessable: well-formed content refused by the content policy.
+	ErrUnprocessable = errors.New("unprocessable")
+)
+
+// Error is a business rule failure. errors.Is matches both its Kind and the
+// underlying Err.
+type Error struct {
+	Kind error
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+func invalid(err error) error {
+	return &Error{Kind: ErrInvalid, Err: err}
+}
+
+func unauthorized(err error) error {
+	return &Error{Kind: ErrUnauthorized, Err: err}
+}
+
+func forbidden(message string) error {
+	return &Error{Kind: ErrForbidden, Err: errors.New(message)}
+}
+
+func notFound(message string) error {
+	return &Error{Kind: ErrNotFound, Err: errors.New(message)}
+}
+
+func unprocessable(err error) error {
+	return &Error{Kind: ErrUnprocessable, Err: err}
+}
@@ -0,0 +1,15 @@
+package services
+
+import (
+	"log/slog"
+
+	"github.com/wesleywcr/dev-book/api/events"
+)
+
+// publish pushes the event to the stream of userId. Streaming is best effort:
+// a broker failure is logged and never fails the action that caused it.
+func publish(broker events.Broker, userId uint64, event events.Event) {
+	if error := broker.Publish(events.UserTopic(userId), event); error != nil {
+		slog.Warn("event not published", "type", event.Type, "user", userId, "error", error)
+	}
+}
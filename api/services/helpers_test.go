@@ -0,0 +1,51 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/wesleywcr/dev-book/api/events"
+	"github.com/wesleywcr/dev-book/api/storage"
+)
+
+// The fakes of the tests implement only the methods the service under test
+// calls. The others are left to the embedded interface, which is nil, so
+// calling one of them panics and points at what the fake is missing.
+
+// pair is a relationship between two users, as {userId, followerId} or
+// {blockerId, blockedId}.
+type pair [2]uint64
+
+// kindOf returns the kind of a service error, or the error itself when it is
+// not one, so tests can compare them.
+func kindOf(error error) error {
+	var serviceError *Error
+	if errors.As(error, &serviceError) {
+		return serviceError.Kind
+	}
+	return error
+}
+
+func newTestStorage(t *testing.T) storage.Storage {
+	t.Helper()
+	local, error := storage.NewLocal(t.TempDir(), "http://localhost/files")
+	if error != nil {
+		t.Fatal(error)
+	}
+	return local
+}
+
+// received returns the events published to topic while run runs.
+func received(broker *events.MemoryBroker, topic string, run func()) []events.Event {
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, _ := broker.Subscribe(ctx, topic)
+	run()
+	cancel()
+
+	var received []events.Event
+	for event := range stream {
+		received = append(received, event)
+	}
+	return received
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"strings"
+
+	"github.com/wesleywcr/dev-book/api/models"
+)
+
+// MentionRepository is the storage of the users mentioned by publications.
+type MentionRepository interface {
+	Replace(publicationId uint64, mentions []models.Mention) error
+	SearchByPublications(publicationIds []uint64) (map[uint64][]models.Mention, error)
+}
+
+// resolveMentions keeps the mention candidates of the publication whose
+// nickname belongs to a user, filling in the user ID.
+func resolveMentions(users UserRepository, publication models.Publication) ([]models.Mention, error) {
+	candidates := publication.MentionCandidates()
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	nicknames := make([]string, len(candidates))
+	for i, candidate := range candidates {
+		nicknames[i] = candidate.Nickname
+	}
+
+	found, error := users.SearchNicknames(nicknames)
+	if error != nil {
+		return nil, error
+	}
+	byNickname := map[string]models.User{}
+	for _, user := range found {
+		byNickname[strings.ToLower(user.Nickname)] = user
+	}
+
+	var mentions []models.Mention
+	for _, candidate := range candidates {
+		user, ok := byNickname[strings.ToLower(candidate.Nickname)]
+		if !ok {
+			continue
+		}
+		candidate.UserID = user.ID
+		candidate.Nickname = user.Nickname
+		mentions = append(mentions, candidate)
+	}
+	return mentions, nil
+}
+
+// attachMentions fills the Mentions of each publication.
+func attachMentions(mentions MentionRepository, publications []models.Publication) ([]models.Publication, error) {
+	ids := make([]uint64, len(publications))
+	for i, publication := range publications {
+		ids[i] = publication.ID
+	}
+
+	byPublication, error := mentions.SearchByPublications(ids)
+	if error != nil {
+		return nil, error
+	}
+	for i := range publications {
+		publications[i].Mentions = byPublication[publications[i].ID]
+	}
+	return publications, nil
+}
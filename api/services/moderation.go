@@ -0,0 +1,221 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/wesleywcr/dev-book/api/events"
+	"github.com/wesleywcr/dev-book/api/models"
+)
+
+// ReportRepository is the storage of the reports and of the audit log of the
+// moderators.
+type ReportRepository interface {
+	Create(reporterId uint64, report models.Report) (uint64, error)
+	SearchOpen(reporterId, userId, publicationId uint64) (uint64, error)
+	SearchPerId(reportId uint64) (models.Report, error)
+	Search(filter models.ReportFilter, page models.Page) ([]models.Report, error)
+	Resolve(userId, publicationId uint64, status models.ReportStatus, moderatorId uint64) error
+	CreateAction(action models.ModerationAction) (models.ModerationAction, error)
+	SearchActions(userId uint64, page models.Page) ([]models.ModerationAction, error)
+	SearchWarnings(userId uint64, page models.Page) ([]models.Warning, error)
+}
+
+// ModerationService owns the rules about reports: any user can flag a
+// publication or a user, and the moderators work the queue, every action
+// they take being recorded.
+type ModerationService struct {
+	reports      ReportRepository
+	users        UserRepository
+	publications PublicationRepository
+	searchIndex  SearchIndex
+	broker       events.Broker
+}
+
+func NewModerationService(
+	reports ReportRepository,
+	users UserRepository,
+	publications PublicationRepository,
+	searchIndex SearchIndex,
+	broker events.Broker,
+) *ModerationService {
+	return &ModerationService{reports, users, publications, searchIndex, broker}
+}
+
+// ReportPublication flags a publication on behalf of reporterId. Reporting a
+// plain repost reports its original. created is false when reporterId already
+// had an open report about it, which is returned instead.
+func (service ModerationService) ReportPublication(reporterId, publicationId uint64, report models.Report) (models.Report, bool, error) {
+	publication, error := service.publications.SearchPublicationsById(publicationId)
+	if error != nil {
+		return models.Report{}, false, error
+	}
+	if publication.ID != 0 && publication.IsRepost() {
+		if publication, error = service.publications.SearchPublicationsById(publication.OriginalID); error != nil {
+			return models.Report{}, false, error
+		}
+	}
+	if publication.ID == 0 {
+		return models.Report{}, false, notFound("Publicação não encontrada")
+	}
+	if publication.AuthorID == reporterId {
+		return models.Report{}, false, forbidden("Não é possível denunciar a sua própria publicação")
+	}
+
+	report.User = models.UserSummary{ID: publication.AuthorID}
+	report.PublicationID = publication.ID
+	return service.create(reporterId, report)
+}
+
+// ReportUser flags a user on behalf of reporterId. created is false when
+// reporterId already had an open report about them, which is returned instead.
+func (service ModerationService) ReportUser(reporterId, userId uint64, report models.Report) (models.Report, bool, error) {
+	if reporterId == userId {
+		return models.Report{}, false, forbidden("Não é possível denunciar a si mesmo")
+	}
+	user, error := service.users.SearchPerId(userId)
+	if error != nil {
+		return models.Report{}, false, error
+	}
+	if user.ID == 0 {
+		return models.Report{}, false, notFound("Usuário não encontrado")
+	}
+
+	report.User = models.UserSummary{ID: userId}
+	report.PublicationID = 0
+	return service.create(reporterId, report)
+}
+
+func (service ModerationService) create(reporterId uint64, report models.Report) (models.Report, bool, error) {
+	if error := report.Prepare(); error != nil {
+		return models.Report{}, false, invalid(error)
+	}
+
+	reportId, error := service.reports.SearchOpen(reporterId, report.User.ID, report.PublicationID)
+	if error != nil {
+		return models.Report{}, false, error
+	}
+	created := reportId == 0
+	if created {
+		if reportId, error = service.reports.Create(reporterId, report); error != nil {
+			return models.Report{}, false, error
+		}
+	}
+
+	report, error = service.reports.SearchPerId(reportId)
+	if error != nil {
+		return models.Report{}, false, error
+	}
+	return report, created, nil
+}
+
+// Queue returns the reports matching the filter, oldest first.
+func (service ModerationService) Queue(filter models.ReportFilter, page models.Page) ([]models.Report, error) {
+	switch filter.Status {
+	case "", models.ReportOpen, models.ReportResolved, models.ReportDismissed:
+	default:
+		return nil, invalid(errors.New("O parâmetro status deve ser open, resolved ou dismissed"))
+	}
+	if filter.Reason != "" && !filter.Reason.Valid() {
+		return nil, invalid(errors.New("O parâmetro reason deve ser spam, harassment, hate, violence, nudity, misinformation ou other"))
+	}
+	if filter.Target != "" && filter.Target != "publication" && filter.Target != "user" {
+		return nil, invalid(errors.New("O parâmetro type deve ser publication ou user"))
+	}
+	return service.reports.Search(filter, page)
+}
+
+// Act applies the decision of moderatorId about an open report: hiding the
+// publication, warning or suspending its user, or dismissing it. Every open
+// report about the same publication or user is closed with it, and the
+// action is recorded in the audit log.
+func (service ModerationService) Act(moderatorId, reportId uint64, action models.ModerationAction) (models.ModerationAction, error) {
+	if error := action.Prepare(); error != nil {
+		return models.ModerationAction{}, invalid(error)
+	}
+
+	report, error := service.reports.SearchPerId(reportId)
+	if error != nil {
+		return models.ModerationAction{}, error
+	}
+	if report.ID == 0 {
+		return models.ModerationAction{}, notFound("Denúncia não encontrada")
+	}
+	if report.Status != models.ReportOpen {
+		return models.ModerationAction{}, forbidden("Esta denúncia já foi encerrada")
+	}
+	if report.User.ID == moderatorId {
+		return models.ModerationAction{}, forbidden("Não é possível moderar uma denúncia sobre você")
+	}
+
+	switch action.Kind {
+	case models.ModerationHide:
+		if report.PublicationID == 0 {
+			return models.ModerationAction{}, invalid(errors.New("A ação hide só se aplica a denúncias de publicações"))
+		}
+		if error = service.publications.Hide(report.PublicationID); error != nil {
+			return models.ModerationAction{}, error
+		}
+		if error = service.searchIndex.Remove(report.PublicationID); error != nil {
+			return models.ModerationAction{}, error
+		}
+	case models.ModerationWarn, models.ModerationSuspend:
+		if error = service.ensureNotStaff(report.User.ID); error != nil {
+			return models.ModerationAction{}, error
+		}
+		if action.Kind == models.ModerationSuspend {
+			if error = service.users.Suspend(report.User.ID, true); error != nil {
+				return models.ModerationAction{}, error
+			}
+		}
+	}
+
+	status := models.ReportResolved
+	if action.Kind == models.ModerationDismiss {
+		status = models.ReportDismissed
+	}
+	if error = service.reports.Resolve(report.User.ID, report.PublicationID, status, moderatorId); error != nil {
+		return models.ModerationAction{}, error
+	}
+
+	action.ModeratorID = moderatorId
+	action.ReportID = report.ID
+	action.UserID = report.User.ID
+	action.PublicationID = report.PublicationID
+	if action, error = service.reports.CreateAction(action); error != nil {
+		return models.ModerationAction{}, error
+	}
+
+	if action.Kind == models.ModerationWarn {
+		publish(service.broker, action.UserID, events.Event{Type: events.TypeWarning, Data: models.Warning{
+			ID:            action.ID,
+			PublicationID: action.PublicationID,
+			Note:          action.Note,
+			Created_at:    action.Created_at,
+		}})
+	}
+	return action, nil
+}
+
+// ensureNotStaff fails when userId is a moderator or an administrator, whom
+// the moderators cannot warn nor suspend.
+func (service ModerationService) ensureNotStaff(userId uint64) error {
+	user, error := service.users.SearchPerId(userId)
+	if error != nil {
+		return error
+	}
+	if user.Role.AtLeast(models.RoleModerator) {
+		return forbidden("Não é possível advertir ou suspender um membro da moderação")
+	}
+	return nil
+}
+
+// AuditLog returns the actions of the moderators, newest first, only those
+// about userId when it is not 0.
+func (service ModerationService) AuditLog(userId uint64, page models.Page) ([]models.ModerationAction, error) {
+	return service.reports.SearchActions(userId, page)
+}
+
+// Warnings returns the warnings userId received from the moderators.
+func (service ModerationService) Warnings(userId uint64, page models.Page) ([]models.Warning, error) {
+	return service.reports.SearchWarnings(userId, page)
+}
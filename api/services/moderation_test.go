@@ -0,0 +1,155 @@
+package services
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/wesleywcr/dev-book/api/events"
+	"github.com/wesleywcr/dev-book/api/models"
+)
+
+type fakeReports struct {
+	ReportRepository
+	reports  map[uint64]models.Report
+	resolved models.ReportStatus
+	actions  []models.ModerationAction
+}
+
+func (fake *fakeReports) SearchPerId(reportId uint64) (models.Report, error) {
+	return fake.reports[reportId], nil
+}
+
+func (fake *fakeReports) Resolve(userId, publicationId uint64, status models.ReportStatus, moderatorId uint64) error {
+	fake.resolved = status
+	return nil
+}
+
+func (fake *fakeReports) CreateAction(action models.ModerationAction) (models.ModerationAction, error) {
+	action.ID = uint64(len(fake.actions) + 1)
+	fake.actions = append(fake.actions, action)
+	return action, nil
+}
+
+// moderatedUsers knows the users and records who was suspended.
+type moderatedUsers struct {
+	UserRepository
+	users     map[uint64]models.User
+	suspended []uint64
+}
+
+func (fake *moderatedUsers) SearchPerId(ID uint64) (models.User, error) {
+	return fake.users[ID], nil
+}
+
+func (fake *moderatedUsers) Suspend(ID uint64, suspended bool) error {
+	fake.suspended = append(fake.suspended, ID)
+	return nil
+}
+
+// moderatedPublications holds the publications that were not hidden.
+type moderatedPublications struct {
+	PublicationRepository
+	publications map[uint64]models.Publication
+}
+
+func (fake *moderatedPublications) Hide(publicationId uint64) error {
+	delete(fake.publications, publicationId)
+	return nil
+}
+
+// fakeIndex records the publications taken out of the search index.
+type fakeIndex struct {
+	SearchIndex
+	removed []uint64
+}
+
+func (fake *fakeIndex) Remove(publicationId uint64) error {
+	fake.removed = append(fake.removed, publicationId)
+	return nil
+}
+
+func TestModerationServiceAct(t *testing.T) {
+	const moderatorId = 8
+
+	tests := []struct {
+		name      string
+		reportId  uint64
+		kind      models.ModerationKind
+		errorKind error
+		status    models.ReportStatus
+		hidden    bool
+		suspended bool
+		warned    bool
+	}{
+		{name: "unknown action", reportId: 1, kind: "ban", errorKind: ErrInvalid},
+		{name: "missing report", reportId: 99, kind: models.ModerationDismiss, errorKind: ErrNotFound},
+		{name: "closed report", reportId: 3, kind: models.ModerationDismiss, errorKind: ErrForbidden},
+		{name: "report about the moderator", reportId: 5, kind: models.ModerationDismiss, errorKind: ErrForbidden},
+		{name: "hide a user", reportId: 2, kind: models.ModerationHide, errorKind: ErrInvalid},
+		{name: "warn the staff", reportId: 4, kind: models.ModerationWarn, errorKind: ErrForbidden},
+		{name: "hide", reportId: 1, kind: models.ModerationHide, status: models.ReportResolved, hidden: true},
+		{name: "warn", reportId: 2, kind: models.ModerationWarn, status: models.ReportResolved, warned: true},
+		{name: "suspend", reportId: 2, kind: models.ModerationSuspend, status: models.ReportResolved, suspended: true},
+		{name: "dismiss", reportId: 1, kind: models.ModerationDismiss, status: models.ReportDismissed},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reports := &fakeReports{reports: map[uint64]models.Report{
+				1: {ID: 1, User: models.UserSummary{ID: 2}, PublicationID: 20, Status: models.ReportOpen},
+				2: {ID: 2, User: models.UserSummary{ID: 2}, Status: models.ReportOpen},
+				3: {ID: 3, User: models.UserSummary{ID: 2}, Status: models.ReportResolved},
+				4: {ID: 4, User: models.UserSummary{ID: 9}, Status: models.ReportOpen},
+				5: {ID: 5, User: models.UserSummary{ID: moderatorId}, Status: models.ReportOpen},
+			}}
+			users := &moderatedUsers{users: map[uint64]models.User{
+				2:           {ID: 2, Nickname: "bia", Role: models.RoleUser},
+				moderatorId: {ID: moderatorId, Nickname: "mod", Role: models.RoleModerator},
+				9:           {ID: 9, Nickname: "admin", Role: models.RoleAdmin},
+			}}
+			publications := &moderatedPublications{publications: map[uint64]models.Publication{
+				20: {ID: 20, AuthorID: 2, Content: "spam"},
+			}}
+			index := &fakeIndex{}
+			broker := events.NewMemoryBroker(4)
+			service := NewModerationService(reports, users, publications, index, broker)
+
+			var action models.ModerationAction
+			var error error
+			streamed := received(broker, events.UserTopic(2), func() {
+				action, error = service.Act(moderatorId, test.reportId, models.ModerationAction{Kind: test.kind})
+			})
+			if kindOf(error) != test.errorKind {
+				t.Fatalf("Act = %v, want %v", error, test.errorKind)
+			}
+
+			if reports.resolved != test.status {
+				t.Errorf("reports resolved as %q, want %q", reports.resolved, test.status)
+			}
+			if test.errorKind == nil {
+				expected := models.ModerationAction{
+					ID:            1,
+					ModeratorID:   moderatorId,
+					Kind:          test.kind,
+					ReportID:      test.reportId,
+					UserID:        2,
+					PublicationID: reports.reports[test.reportId].PublicationID,
+				}
+				if action != expected || len(reports.actions) != 1 {
+					t.Errorf("Act = %+v, recorded %+v, want %+v", action, reports.actions, expected)
+				}
+			}
+
+			_, exists := publications.publications[20]
+			if hidden := !exists && slices.Equal(index.removed, []uint64{20}); hidden != test.hidden {
+				t.Errorf("hidden = %v, want %v", hidden, test.hidden)
+			}
+			if suspended := slices.Equal(users.suspended, []uint64{2}); suspended != test.suspended {
+				t.Errorf("suspended %v, want the user suspended: %v", users.suspended, test.suspended)
+			}
+			if warned := len(streamed) == 1 && streamed[0].Type == events.TypeWarning; warned != test.warned {
+				t.Errorf("streamed %+v, want a warning: %v", streamed, test.warned)
+			}
+		})
+	}
+}
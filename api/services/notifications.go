@@ -0,0 +1,49 @@
+package services
+
+import (
+	"github.com/wesleywcr/dev-book/api/models"
+)
+
+// NotificationRepository is the storage of notification events.
+type NotificationRepository interface {
+	Create(event models.NotificationEvent) error
+	Search(userId uint64, page models.Page) ([]models.Notification, error)
+	CountUnread(userId uint64) (uint64, error)
+	MarkRead(userId uint64) error
+}
+
+// notify records the event unless users are acting on their own content.
+func notify(notifications NotificationRepository, event models.NotificationEvent) error {
+	if event.UserID == event.ActorID {
+		return nil
+	}
+	return notifications.Create(event)
+}
+
+// NotificationService lets users review what happened around their account.
+type NotificationService struct {
+	notifications NotificationRepository
+}
+
+func NewNotificationService(notifications NotificationRepository) *NotificationService {
+	return &NotificationService{notifications}
+}
+
+// List returns a page of the aggregated notifications of userId and how many
+// events are still unread.
+func (service NotificationService) List(userId uint64, page models.Page) (models.NotificationPage, error) {
+	notifications, error := service.notifications.Search(userId, page)
+	if error != nil {
+		return models.NotificationPage{}, error
+	}
+
+	unread, error := service.notifications.CountUnread(userId)
+	if error != nil {
+		return models.NotificationPage{}, error
+	}
+	return models.NotificationPage{Notifications: notifications, Unread: unread}, nil
+}
+
+func (service NotificationService) MarkRead(userId uint64) error {
+	return service.notifications.MarkRead(userId)
+}
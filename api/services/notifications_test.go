@@ -0,0 +1,105 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/wesleywcr/dev-book/api/models"
+)
+
+type fakeNotifications struct {
+	NotificationRepository
+	events []models.NotificationEvent
+	// read holds the users who marked their notifications as read.
+	read map[uint64]bool
+}
+
+func (fake *fakeNotifications) Create(event models.NotificationEvent) error {
+	fake.events = append(fake.events, event)
+	return nil
+}
+
+// Search returns a notification per event, without aggregating them.
+func (fake *fakeNotifications) Search(userId uint64, page models.Page) ([]models.Notification, error) {
+	var notifications []models.Notification
+	for _, event := range fake.events {
+		if event.UserID == userId {
+			notifications = append(notifications, models.Notification{
+				Kind:          event.Kind,
+				PublicationID: event.PublicationID,
+				Read:          fake.read[userId],
+			})
+		}
+	}
+	return notifications, nil
+}
+
+func (fake *fakeNotifications) CountUnread(userId uint64) (uint64, error) {
+	if fake.read[userId] {
+		return 0, nil
+	}
+	var unread uint64
+	for _, event := range fake.events {
+		if event.UserID == userId {
+			unread++
+		}
+	}
+	return unread, nil
+}
+
+func (fake *fakeNotifications) MarkRead(userId uint64) error {
+	if fake.read == nil {
+		fake.read = map[uint64]bool{}
+	}
+	fake.read[userId] = true
+	return nil
+}
+
+func TestNotificationServiceList(t *testing.T) {
+	notifications := &fakeNotifications{}
+	for _, event := range []models.NotificationEvent{
+		{UserID: 1, ActorID: 2, Kind: models.NotificationFollow},
+		{UserID: 1, ActorID: 3, Kind: models.NotificationLike, PublicationID: 10},
+		// users acting on their own content are not notified
+		{UserID: 1, ActorID: 1, Kind: models.NotificationLike, PublicationID: 10},
+		{UserID: 2, ActorID: 1, Kind: models.NotificationMention, PublicationID: 20},
+	} {
+		if error := notify(notifications, event); error != nil {
+			t.Fatal(error)
+		}
+	}
+	service := NewNotificationService(notifications)
+	if error := service.MarkRead(2); error != nil {
+		t.Fatal(error)
+	}
+
+	tests := []struct {
+		name   string
+		userId uint64
+		kinds  []models.NotificationKind
+		unread uint64
+	}{
+		{name: "unread", userId: 1, kinds: []models.NotificationKind{models.NotificationFollow, models.NotificationLike}, unread: 2},
+		{name: "read", userId: 2, kinds: []models.NotificationKind{models.NotificationMention}, unread: 0},
+		{name: "none", userId: 3, kinds: nil, unread: 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			page, error := service.List(test.userId, models.Page{Number: 1, Limit: 20})
+			if error != nil {
+				t.Fatal(error)
+			}
+			if page.Unread != test.unread {
+				t.Errorf("Unread = %d, want %d", page.Unread, test.unread)
+			}
+			if len(page.Notifications) != len(test.kinds) {
+				t.Fatalf("Notifications = %+v, want %v", page.Notifications, test.kinds)
+			}
+			for i, notification := range page.Notifications {
+				if notification.Kind != test.kinds[i] {
+					t.Errorf("notification %d is %q, want %q", i, notification.Kind, test.kinds[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/wesleywcr/dev-book/api/models"
+	"github.com/wesleywcr/dev-book/api/policy"
+)
+
+// maxFlagDetails is the size of reports.details, in characters.
+const maxFlagDetails = 500
+
+// ContentPolicy decides whether a publication can be stored, as the
+// policy.Pipeline does.
+type ContentPolicy interface {
+	Lookback() time.Duration
+	Evaluate(submission policy.Submission) ([]policy.Verdict, error)
+}
+
+// screen runs the content policy over a publication about to be created,
+// when its ID is 0, or edited, returning the flags it raised.
+func (service PublicationService) screen(publication models.Publication) ([]policy.Verdict, error) {
+	submission := policy.Submission{Publication: publication, Now: time.Now().UTC()}
+	if lookback := service.policy.Lookback(); lookback > 0 {
+		var error error
+		submission.Recent, error = service.publications.SearchRecent(publication.AuthorID, submission.Now.Add(-lookback))
+		if error != nil {
+			return nil, error
+		}
+	}
+
+	flags, error := service.policy.Evaluate(submission)
+	if policy.IsRejection(error) {
+		return nil, unprocessable(error)
+	}
+	return flags, error
+}
+
+// flag files an automatic report about a stored publication that raised
+// flags, unless one is already open.
+func (service PublicationService) flag(publication models.Publication, flags []policy.Verdict) error {
+	if len(flags) == 0 {
+		return nil
+	}
+
+	reportId, error := service.reports.SearchOpen(publication.AuthorID, publication.AuthorID, publication.ID)
+	if error != nil || reportId != 0 {
+		return error
+	}
+
+	reasons := make([]string, len(flags))
+	for i, flag := range flags {
+		reasons[i] = flag.Reason
+	}
+	details := strings.Join(reasons, "; ")
+	if utf8.RuneCountInString(details) > maxFlagDetails {
+		details = string([]rune(details)[:maxFlagDetails-1]) + "…"
+	}
+
+	_, error = service.reports.Create(publication.AuthorID, models.Report{
+		User:          models.UserSummary{ID: publication.AuthorID},
+		PublicationID: publication.ID,
+		Reason:        flags[0].Category,
+		Details:       details,
+		Automatic:     true,
+	})
+	return error
+}
@@ -0,0 +1,34 @@
+package services
+
+import (
+	"github.com/wesleywcr/dev-book/api/models"
+	"github.com/wesleywcr/dev-book/api/storage"
+)
+
+// presenter prepares publications to be shown to clients.
+type presenter struct {
+	publications PublicationRepository
+	mentions     MentionRepository
+	attachments  AttachmentRepository
+	files        storage.Storage
+}
+
+// present replaces plain reposts by the publication they repost, attaches
+// the original of quotes, and fills mentions and attachments, as viewerId
+// sees them.
+func (presenter presenter) present(viewerId uint64, list []models.Publication) ([]models.Publication, error) {
+	list, error := presenter.resolveReposts(viewerId, list)
+	if error != nil {
+		return nil, error
+	}
+	return presenter.decorate(list)
+}
+
+// decorate fills the mentions and the attachments of the publications.
+func (presenter presenter) decorate(list []models.Publication) ([]models.Publication, error) {
+	list, error := attachMentions(presenter.mentions, list)
+	if error != nil {
+		return nil, error
+	}
+	return attachAttachments(presenter.attachments, presenter.files, list)
+}
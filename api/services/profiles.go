@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/wesleywcr/dev-book/api/media"
+	"github.com/wesleywcr/dev-book/api/models"
+	"github.com/wesleywcr/dev-book/api/storage"
+)
+
+// ProfileImage is one of the pictures shown on a profile.
+type ProfileImage int
+
+const (
+	Avatar ProfileImage = iota
+	Banner
+)
+
+// size is the width and height a profile image is cropped and scaled to.
+func (image ProfileImage) size() (int, int) {
+	if image == Banner {
+		return 1500, 500
+	}
+	return 400, 400
+}
+
+func (image ProfileImage) String() string {
+	if image == Banner {
+		return "banner"
+	}
+	return "avatar"
+}
+
+// key returns the storage key of the image of the user, empty when not set.
+func (image ProfileImage) key(user models.User) string {
+	if image == Banner {
+		return user.BannerKey
+	}
+	return user.AvatarKey
+}
+
+func (image ProfileImage) save(users UserRepository, userId uint64, key string) error {
+	if image == Banner {
+		return users.UpdateBanner(userId, key)
+	}
+	return users.UpdateAvatar(userId, key)
+}
+
+// withProfileURLs fills the image URLs of users from their storage keys.
+func withProfileURLs(files storage.Storage, users ...*models.User) {
+	for _, user := range users {
+		if user.AvatarKey != "" {
+			user.AvatarURL = files.URL(user.AvatarKey)
+		}
+		if user.BannerKey != "" {
+			user.BannerURL = files.URL(user.BannerKey)
+		}
+	}
+}
+
+// SetProfileImage crops and scales the uploaded picture to the size of the
+// image and makes it the avatar or banner of userId, replacing the previous one.
+func (service UserService) SetProfileImage(ctx context.Context, actorId, userId uint64, image ProfileImage, upload models.Upload) (models.User, error) {
+	user, error := service.owned(actorId, userId)
+	if error != nil {
+		return models.User{}, error
+	}
+
+	if _, error = media.Inspect(upload.Content); error != nil {
+		return models.User{}, invalid(fmt.Errorf("%s: %w", upload.Filename, error))
+	}
+	width, height := image.size()
+	content, error := media.Cover(upload.Content, width, height)
+	if error != nil {
+		return models.User{}, invalid(fmt.Errorf("%s: %w", upload.Filename, error))
+	}
+
+	name, error := randomName()
+	if error != nil {
+		return models.User{}, error
+	}
+	key := fmt.Sprintf("users/%d/%s_%s.jpg", userId, image, name)
+	if error = service.files.Put(ctx, key, content, "image/jpeg"); error != nil {
+		return models.User{}, error
+	}
+	if error = image.save(service.users, userId, key); error != nil {
+		service.removeFile(ctx, key)
+		return models.User{}, error
+	}
+	service.removeFile(ctx, image.key(user))
+
+	return service.Get(userId)
+}
+
+// RemoveProfileImage clears the avatar or banner of userId.
+func (service UserService) RemoveProfileImage(ctx context.Context, actorId, userId uint64, image ProfileImage) error {
+	user, error := service.owned(actorId, userId)
+	if error != nil {
+		return error
+	}
+	if image.key(user) == "" {
+		return notFound(fmt.Sprintf("O usuário não possui %s", image))
+	}
+
+	if error = image.save(service.users, userId, ""); error != nil {
+		return error
+	}
+	service.removeFile(ctx, image.key(user))
+	return nil
+}
+
+// owned returns userId when actorId is that user.
+func (service UserService) owned(actorId, userId uint64) (models.User, error) {
+	if actorId != userId {
+		return models.User{}, forbidden("Não é possível alterar o perfil de um usuário que não é o seu")
+	}
+	return service.existing(userId)
+}
+
+// removeFile deletes a replaced image, logging failures: an orphan file only
+// wastes space.
+func (service UserService) removeFile(ctx context.Context, key string) {
+	if key == "" {
+		return
+	}
+	if error := service.files.Delete(ctx, key); error != nil {
+		slog.Warn("file not deleted", "key", key, "error", error)
+	}
+}
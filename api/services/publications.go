@@ -0,0 +1,494 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/wesleywcr/dev-book/api/events"
+	"github.com/wesleywcr/dev-book/api/models"
+	"github.com/wesleywcr/dev-book/api/storage"
+)
+
+// PublicationRepository is the storage PublicationService depends on.
+type PublicationRepository interface {
+	Create(publication models.Publication) (uint64, error)
+	SearchPublicationsById(publicationId uint64) (models.Publication, error)
+	Update(publicationId uint64, publication models.Publication) error
+	Delete(publicationId uint64) error
+	SearchPublicationByUserId(userId uint64) ([]models.Publication, error)
+	Like(publicationId uint64) error
+	Deslike(publicationId uint64) error
+	SearchPublicationsByTag(viewerId uint64, tag string, page models.Page) ([]models.Publication, error)
+	SearchPublicationsMentioning(userId uint64, page models.Page) ([]models.Publication, error)
+	SearchDeleted(publicationId uint64) (models.Publication, time.Time, error)
+	Restore(publicationId uint64) error
+	SearchRevisions(publicationId uint64) ([]models.PublicationRevision, error)
+	SearchPublicationsByIds(publicationIds []uint64) ([]models.Publication, error)
+	SearchVisiblePublicationsByIds(viewerId uint64, publicationIds []uint64) ([]models.Publication, error)
+	SearchRepost(userId, originalId uint64) (uint64, error)
+	DeleteRepost(repostId uint64) error
+	Hide(publicationId uint64) error
+	SearchRecent(authorId uint64, since time.Time) ([]models.Publication, error)
+}
+
+// RestoreGracePeriod is how long a deleted publication can be restored by its author.
+const RestoreGracePeriod = 30 * 24 * time.Hour
+
+// PublicationService owns the rules about publications, their hashtags,
+// mentions and likes.
+type PublicationService struct {
+	publications  PublicationRepository
+	tags          TagRepository
+	mentions      MentionRepository
+	users         UserRepository
+	notifications NotificationRepository
+	attachments   AttachmentRepository
+	broker        events.Broker
+	files         storage.Storage
+	searchIndex   SearchIndex
+	timeline      *Timeline
+	policy        ContentPolicy
+	reports       ReportRepository
+}
+
+func NewPublicationService(
+	publications PublicationRepository,
+	tags TagRepository,
+	mentions MentionRepository,
+	users UserRepository,
+	notifications NotificationRepository,
+	attachments AttachmentRepository,
+	broker events.Broker,
+	files storage.Storage,
+	searchIndex SearchIndex,
+	timeline *Timeline,
+	policy ContentPolicy,
+	reports ReportRepository,
+) *PublicationService {
+	return &PublicationService{
+		publications, tags, mentions, users, notifications, attachments, broker, files, searchIndex, timeline, policy, reports,
+	}
+}
+
+func (service PublicationService) presenter() presenter {
+	return presenter{service.publications, service.mentions, service.attachments, service.files}
+}
+
+// Create validates and stores a publication written by authorId, indexing
+// the hashtags and mentions of its content and streaming it to the followers.
+// A publication with OriginalID quotes that publication.
+func (service PublicationService) Create(authorId uint64, publication models.Publication) (models.Publication, error) {
+	publication.AuthorID = authorId
+	if error := publication.Prepare(); error != nil {
+		return models.Publication{}, invalid(error)
+	}
+
+	var original models.Publication
+	if publication.OriginalID != 0 {
+		var error error
+		if original, error = service.repostable(authorId, publication.OriginalID); error != nil {
+			return models.Publication{}, error
+		}
+		publication.OriginalID = original.ID
+	}
+
+	flags, error := service.screen(publication)
+	if error != nil {
+		return models.Publication{}, error
+	}
+
+	ID, error := service.publications.Create(publication)
+	if error != nil {
+		return models.Publication{}, error
+	}
+	publication.ID = ID
+	if error = service.flag(publication, flags); error != nil {
+		return models.Publication{}, error
+	}
+
+	if publication.Mentions, error = service.index(publication); error != nil {
+		return models.Publication{}, error
+	}
+	if error = service.searchIndex.Index(publication); error != nil {
+		return models.Publication{}, error
+	}
+
+	if original.ID != 0 {
+		publication.Original = &original
+		if error = notify(service.notifications, models.NotificationEvent{
+			UserID:        original.AuthorID,
+			ActorID:       authorId,
+			Kind:          models.NotificationQuote,
+			PublicationID: original.ID,
+		}); error != nil {
+			return models.Publication{}, error
+		}
+	}
+
+	if error = service.fanOut(authorId, publication.ID, publication); error != nil {
+		return models.Publication{}, error
+	}
+	return publication, nil
+}
+
+// fanOut places a new publication of authorId, stored under publicationId,
+// in the timelines and streams it to their followers.
+func (service PublicationService) fanOut(authorId, publicationId uint64, publication models.Publication) error {
+	followers, error := service.users.SearchFollowers(authorId)
+	if error != nil {
+		return error
+	}
+	if error = service.timeline.publish(publicationId, authorId, len(followers)); error != nil {
+		return error
+	}
+	for _, follower := range followers {
+		publish(service.broker, follower.ID, events.Event{Type: events.TypePublication, Data: publication, Actor: authorId})
+	}
+	return nil
+}
+
+// index stores the hashtags and the resolved mentions of a saved publication,
+// notifying the users it did not mention before the edit.
+func (service PublicationService) index(publication models.Publication) ([]models.Mention, error) {
+	if error := service.tags.Replace(publication.ID, publication.Hashtags()); error != nil {
+		return nil, error
+	}
+
+	mentions, error := resolveMentions(service.users, publication)
+	if error != nil {
+		return nil, error
+	}
+	previous, error := service.mentions.SearchByPublications([]uint64{publication.ID})
+	if error != nil {
+		return nil, error
+	}
+	if error = service.mentions.Replace(publication.ID, mentions); error != nil {
+		return nil, error
+	}
+
+	mentioned := map[uint64]bool{}
+	for _, mention := range previous[publication.ID] {
+		mentioned[mention.UserID] = true
+	}
+	for _, mention := range mentions {
+		if mentioned[mention.UserID] {
+			continue
+		}
+		if error = notify(service.notifications, models.NotificationEvent{
+			UserID:        mention.UserID,
+			ActorID:       publication.AuthorID,
+			Kind:          models.NotificationMention,
+			PublicationID: publication.ID,
+		}); error != nil {
+			return nil, error
+		}
+	}
+	return mentions, nil
+}
+
+// Get returns the publication, as long as viewerId can see its author.
+func (service PublicationService) Get(viewerId, publicationId uint64) (models.Publication, error) {
+	publication, error := service.find(publicationId)
+	if error != nil {
+		return models.Publication{}, error
+	}
+	if error = service.ensureReadable(viewerId, publication); error != nil {
+		return models.Publication{}, error
+	}
+
+	publications, error := service.presenter().present(viewerId, []models.Publication{publication})
+	if error != nil {
+		return models.Publication{}, error
+	}
+	if len(publications) == 0 {
+		return models.Publication{}, notFound("Publicação não encontrada")
+	}
+	return publications[0], nil
+}
+
+// Feed returns the home timeline of userId: their publications and those of
+// the users they follow, newest first.
+func (service PublicationService) Feed(userId uint64, page models.Page) ([]models.Publication, error) {
+	publications, error := service.timeline.read(userId, page)
+	if error != nil {
+		return nil, error
+	}
+	return service.presenter().present(userId, publications)
+}
+
+// RankedFeed returns the publications of the last rankingWindow from the
+// timeline of userId and from their second-degree connections, ordered by
+// the ranker of the timeline.
+func (service PublicationService) RankedFeed(userId uint64, page models.Page) ([]models.Publication, error) {
+	now := time.Now()
+	candidates, error := service.timeline.candidates(userId, now.Add(-rankingWindow).UTC(), service.presenter())
+	if error != nil {
+		return nil, error
+	}
+
+	ranked := rank(service.timeline.ranker, candidates, now)
+	start := min(page.Offset(), uint64(len(ranked)))
+	end := min(start+page.Limit, uint64(len(ranked)))
+	return ranked[start:end], nil
+}
+
+// ByAuthor returns the publications of userId, as long as viewerId can see
+// the account.
+func (service PublicationService) ByAuthor(viewerId, userId uint64) ([]models.Publication, error) {
+	if error := ensureNotBlocked(service.users, viewerId, userId); error != nil {
+		return nil, error
+	}
+	if error := ensureVisible(service.users, viewerId, userId); error != nil {
+		return nil, error
+	}
+
+	publications, error := service.publications.SearchPublicationByUserId(userId)
+	if error != nil {
+		return nil, error
+	}
+	return service.presenter().present(viewerId, publications)
+}
+
+// Mentions returns the publications mentioning userId, newest first.
+func (service PublicationService) Mentions(userId uint64, page models.Page) ([]models.Publication, error) {
+	publications, error := service.publications.SearchPublicationsMentioning(userId, page)
+	if error != nil {
+		return nil, error
+	}
+	return service.presenter().present(userId, publications)
+}
+
+// Update replaces title, content, hashtags and mentions; only the author can
+// edit a publication.
+func (service PublicationService) Update(actorId, publicationId uint64, publication models.Publication) error {
+	publicationSalvedDB, error := service.find(publicationId)
+	if error != nil {
+		return error
+	}
+	if publicationSalvedDB.AuthorID != actorId {
+		return forbidden("Não é possível atualizar uma publicação que não seja a sua")
+	}
+	if publicationSalvedDB.IsRepost() {
+		return forbidden("Não é possível editar um repost")
+	}
+
+	if error = publication.Prepare(); error != nil {
+		return invalid(error)
+	}
+	publication.ID = publicationId
+	publication.AuthorID = actorId
+	flags, error := service.screen(publication)
+	if error != nil {
+		return error
+	}
+	if error = service.publications.Update(publicationId, publication); error != nil {
+		return error
+	}
+	if error = service.flag(publication, flags); error != nil {
+		return error
+	}
+
+	publication.Created_at = publicationSalvedDB.Created_at
+	if _, error = service.index(publication); error != nil {
+		return error
+	}
+	return service.searchIndex.Index(publication)
+}
+
+// Delete hides a publication, which its author can restore within
+// RestoreGracePeriod; only the author can delete it.
+func (service PublicationService) Delete(actorId, publicationId uint64) error {
+	publicationSalvedDB, error := service.find(publicationId)
+	if error != nil {
+		return error
+	}
+	if publicationSalvedDB.AuthorID != actorId {
+		return forbidden("Não é possível deletar uma publicação que não seja a sua")
+	}
+	if error = service.publications.Delete(publicationId); error != nil {
+		return error
+	}
+	return service.searchIndex.Remove(publicationId)
+}
+
+// Restore brings back a publication its author deleted less than
+// RestoreGracePeriod ago.
+func (service PublicationService) Restore(actorId, publicationId uint64) (models.Publication, error) {
+	publication, deletedAt, error := service.publications.SearchDeleted(publicationId)
+	if error != nil {
+		return models.Publication{}, error
+	}
+	if publication.ID == 0 {
+		return models.Publication{}, notFound("Publicação excluída não encontrada")
+	}
+	if publication.AuthorID != actorId {
+		return models.Publication{}, forbidden("Não é possível restaurar uma publicação que não seja a sua")
+	}
+	if time.Since(deletedAt) > RestoreGracePeriod {
+		return models.Publication{}, forbidden("O prazo para restaurar esta publicação expirou")
+	}
+
+	if error = service.publications.Restore(publicationId); error != nil {
+		return models.Publication{}, error
+	}
+	if publication, error = service.Get(actorId, publicationId); error != nil {
+		return models.Publication{}, error
+	}
+	return publication, service.searchIndex.Index(publication)
+}
+
+// Revisions returns the previous versions of a publication; only the author
+// can see them.
+func (service PublicationService) Revisions(actorId, publicationId uint64) ([]models.PublicationRevision, error) {
+	publication, error := service.find(publicationId)
+	if error != nil {
+		return nil, error
+	}
+	if publication.AuthorID != actorId {
+		return nil, forbidden("Não é possível ver o histórico de uma publicação que não seja a sua")
+	}
+	return service.publications.SearchRevisions(publicationId)
+}
+
+// Repost shares a publication with the followers of actorId, attributed to
+// them, and notifies its author. Reposting a repost reposts its original.
+func (service PublicationService) Repost(actorId, publicationId uint64) (models.Publication, error) {
+	original, error := service.repostable(actorId, publicationId)
+	if error != nil {
+		return models.Publication{}, error
+	}
+
+	repostId, error := service.publications.SearchRepost(actorId, original.ID)
+	if error != nil {
+		return models.Publication{}, error
+	}
+	if repostId != 0 {
+		return models.Publication{}, invalid(errors.New("Você já repostou esta publicação"))
+	}
+
+	repostId, error = service.publications.Create(models.Publication{AuthorID: actorId, OriginalID: original.ID})
+	if error != nil {
+		return models.Publication{}, error
+	}
+
+	actor, error := service.users.SearchPerId(actorId)
+	if error != nil {
+		return models.Publication{}, error
+	}
+	publications, error := service.presenter().decorate([]models.Publication{original})
+	if error != nil {
+		return models.Publication{}, error
+	}
+	repost := publications[0]
+	repost.Reposts++
+	repost.RepostedBy = &models.UserSummary{ID: actor.ID, Nickname: actor.Nickname}
+
+	if error = notify(service.notifications, models.NotificationEvent{
+		UserID:        original.AuthorID,
+		ActorID:       actorId,
+		Kind:          models.NotificationRepost,
+		PublicationID: original.ID,
+	}); error != nil {
+		return models.Publication{}, error
+	}
+	if error = service.fanOut(actorId, repostId, repost); error != nil {
+		return models.Publication{}, error
+	}
+	return repost, nil
+}
+
+// Unrepost undoes the repost of publicationId by actorId.
+func (service PublicationService) Unrepost(actorId, publicationId uint64) error {
+	repostId, error := service.publications.SearchRepost(actorId, publicationId)
+	if error != nil {
+		return error
+	}
+	if repostId == 0 {
+		return notFound("Repost não encontrado")
+	}
+	return service.publications.DeleteRepost(repostId)
+}
+
+// repostable returns the publication actorId wants to repost or quote,
+// following a plain repost to its original. Publications of private accounts
+// can only be shared by their authors.
+func (service PublicationService) repostable(actorId, publicationId uint64) (models.Publication, error) {
+	publication, error := service.find(publicationId)
+	if error != nil {
+		return models.Publication{}, error
+	}
+	if publication.IsRepost() {
+		if publication, error = service.find(publication.OriginalID); error != nil {
+			return models.Publication{}, error
+		}
+	}
+
+	if error = ensureNotBlocked(service.users, actorId, publication.AuthorID); error != nil {
+		return models.Publication{}, error
+	}
+
+	author, error := service.users.SearchPerId(publication.AuthorID)
+	if error != nil {
+		return models.Publication{}, error
+	}
+	if author.IsPrivate && author.ID != actorId {
+		return models.Publication{}, forbidden("Não é possível compartilhar publicações de uma conta privada")
+	}
+	return publication, nil
+}
+
+// Like adds a like of actorId to the publication and notifies its author,
+// unless one of them blocked the other.
+func (service PublicationService) Like(actorId, publicationId uint64) error {
+	publication, error := service.find(publicationId)
+	if error != nil {
+		return error
+	}
+	if error = service.ensureReadable(actorId, publication); error != nil {
+		return error
+	}
+
+	if error = service.publications.Like(publicationId); error != nil {
+		return error
+	}
+
+	if publication.AuthorID != actorId {
+		publish(service.broker, publication.AuthorID, events.Event{
+			Type:  events.TypeLike,
+			Data:  events.Like{PublicationID: publicationId, UserID: actorId},
+			Actor: actorId,
+		})
+	}
+	return notify(service.notifications, models.NotificationEvent{
+		UserID:        publication.AuthorID,
+		ActorID:       actorId,
+		Kind:          models.NotificationLike,
+		PublicationID: publicationId,
+	})
+}
+
+func (service PublicationService) Deslike(publicationId uint64) error {
+	return service.publications.Deslike(publicationId)
+}
+
+// ensureReadable fails, as the listings leave the publication out, when one
+// of viewerId and its author blocked the other or the author is a private
+// account viewerId does not follow.
+func (service PublicationService) ensureReadable(viewerId uint64, publication models.Publication) error {
+	if error := ensureNotBlocked(service.users, viewerId, publication.AuthorID); error != nil {
+		return error
+	}
+	return ensureVisible(service.users, viewerId, publication.AuthorID)
+}
+
+// find returns the publication, failing when it does not exist or was deleted.
+func (service PublicationService) find(publicationId uint64) (models.Publication, error) {
+	publication, error := service.publications.SearchPublicationsById(publicationId)
+	if error != nil {
+		return models.Publication{}, error
+	}
+	if publication.ID == 0 {
+		return models.Publication{}, notFound("Publicação não encontrada")
+	}
+	return publication, nil
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/wesleywcr/dev-book/api/events"
+	"github.com/wesleywcr/dev-book/api/models"
+)
+
+type fakePublications struct {
+	PublicationRepository
+	publications map[uint64]models.Publication
+	likes        map[uint64]int
+}
+
+func newFakePublications(publications ...models.Publication) *fakePublications {
+	fake := &fakePublications{publications: map[uint64]models.Publication{}, likes: map[uint64]int{}}
+	for _, publication := range publications {
+		fake.publications[publication.ID] = publication
+	}
+	return fake
+}
+
+func (fake *fakePublications) SearchPublicationsById(publicationId uint64) (models.Publication, error) {
+	return fake.publications[publicationId], nil
+}
+
+func (fake *fakePublications) Like(publicationId uint64) error {
+	fake.likes[publicationId]++
+	return nil
+}
+
+func TestPublicationServiceLike(t *testing.T) {
+	tests := []struct {
+		name          string
+		actorId       uint64
+		publicationId uint64
+		kind          error
+		notified      bool
+	}{
+		{name: "public account", actorId: 1, publicationId: 20, notified: true},
+		{name: "own publication", actorId: 2, publicationId: 20},
+		{name: "private account not followed", actorId: 1, publicationId: 30, kind: ErrForbidden},
+		{name: "private account followed", actorId: 4, publicationId: 30, notified: true},
+		{name: "private account of the author", actorId: 3, publicationId: 30},
+		{name: "blocked author", actorId: 5, publicationId: 20, kind: ErrForbidden},
+		{name: "missing publication", actorId: 1, publicationId: 99, kind: ErrNotFound},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			users := newFakeUsers(
+				models.User{ID: 1, Nickname: "ana"},
+				models.User{ID: 2, Nickname: "bia"},
+				models.User{ID: 3, Nickname: "caio", IsPrivate: true},
+				models.User{ID: 4, Nickname: "duda"},
+				models.User{ID: 5, Nickname: "edu"},
+			)
+			users.followers[pair{3, 4}] = true
+			users.blocks[pair{2, 5}] = true
+			publications := newFakePublications(
+				models.Publication{ID: 20, AuthorID: 2, Content: "pública"},
+				models.Publication{ID: 30, AuthorID: 3, Content: "privada"},
+			)
+			notifications := &recordedNotifications{}
+			broker := events.NewMemoryBroker(4)
+			service := PublicationService{
+				publications:  publications,
+				users:         users,
+				notifications: notifications,
+				broker:        broker,
+			}
+			author := publications.publications[test.publicationId].AuthorID
+
+			var error error
+			streamed := received(broker, events.UserTopic(author), func() {
+				error = service.Like(test.actorId, test.publicationId)
+			})
+			if kindOf(error) != test.kind {
+				t.Fatalf("Like = %v, want %v", error, test.kind)
+			}
+
+			likes := 0
+			if test.kind == nil {
+				likes = 1
+			}
+			if publications.likes[test.publicationId] != likes {
+				t.Errorf("likes = %d, want %d", publications.likes[test.publicationId], likes)
+			}
+			if notified := len(notifications.events) == 1; notified != test.notified {
+				t.Errorf("notified %+v, want a notification: %v", notifications.events, test.notified)
+			}
+			if notified := len(streamed) == 1 && streamed[0].Type == events.TypeLike; notified != test.notified {
+				t.Errorf("streamed %+v, want a like: %v", streamed, test.notified)
+			}
+		})
+	}
+}
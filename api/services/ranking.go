@@ -0,0 +1,97 @@
+package services
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/wesleywcr/dev-book/api/models"
+)
+
+// rankingWindow is how old a publication can be to enter the ranked feed.
+const rankingWindow = 7 * 24 * time.Hour
+
+// Limits of the candidates read for a ranked feed, from the timeline and from
+// second-degree connections.
+const (
+	maxTimelineCandidates     = 500
+	maxSecondDegreeCandidates = 100
+)
+
+// Candidate is a publication considered for the ranked feed of a viewer.
+type Candidate struct {
+	Publication models.Publication
+	// Affinity counts the recent interactions of the viewer with the author:
+	// likes, follows, mentions, reposts and quotes.
+	Affinity int
+	// SecondDegree is set when the viewer does not follow the author, who is
+	// followed by someone the viewer follows.
+	SecondDegree bool
+}
+
+// Ranker scores the candidates of a ranked feed; higher scores come first.
+// Implementations must be deterministic for a given now.
+type Ranker interface {
+	Score(candidate Candidate, now time.Time) float64
+}
+
+// EngagementRanker favours publications with more likes and reposts, from
+// authors the viewer interacts with, and lets every score decay with age.
+// The tree has no comments, so quotes, counted among reposts, stand for them.
+type EngagementRanker struct {
+	LikeWeight     float64
+	RepostWeight   float64
+	AffinityWeight float64
+	// SecondDegreeWeight multiplies the score of publications from authors
+	// the viewer does not follow.
+	SecondDegreeWeight float64
+	// Gravity is how fast scores decay as publications age, in hours.
+	Gravity float64
+}
+
+// DefaultRanker is the ranker of the ranked feed.
+var DefaultRanker Ranker = EngagementRanker{
+	LikeWeight:         1,
+	RepostWeight:       2,
+	AffinityWeight:     0.5,
+	SecondDegreeWeight: 0.5,
+	Gravity:            1.5,
+}
+
+func (ranker EngagementRanker) Score(candidate Candidate, now time.Time) float64 {
+	publication := candidate.Publication
+
+	engagement := 1 + ranker.LikeWeight*float64(publication.Likes) + ranker.RepostWeight*float64(publication.Reposts)
+	score := engagement * (1 + ranker.AffinityWeight*float64(candidate.Affinity))
+	if candidate.SecondDegree {
+		score *= ranker.SecondDegreeWeight
+	}
+
+	age := max(now.Sub(publication.Created_at).Hours(), 0)
+	return score / math.Pow(age+2, ranker.Gravity)
+}
+
+// rank orders the candidates by descending score, breaking ties by the
+// newest publication first.
+func rank(ranker Ranker, candidates []Candidate, now time.Time) []models.Publication {
+	scores := make([]float64, len(candidates))
+	order := make([]int, len(candidates))
+	for i, candidate := range candidates {
+		scores[i] = ranker.Score(candidate, now)
+		order[i] = i
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := order[i], order[j]
+		if scores[a] != scores[b] {
+			return scores[a] > scores[b]
+		}
+		return candidates[a].Publication.ID > candidates[b].Publication.ID
+	})
+
+	ranked := make([]models.Publication, len(order))
+	for i, index := range order {
+		ranked[i] = candidates[index].Publication
+	}
+	return ranked
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/wesleywcr/dev-book/api/models"
+)
+
+var now = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// testRanker has a gravity of 1, so that scores are easy to work out by hand.
+var testRanker = EngagementRanker{
+	LikeWeight:         1,
+	RepostWeight:       2,
+	AffinityWeight:     0.5,
+	SecondDegreeWeight: 0.5,
+	Gravity:            1,
+}
+
+// candidate returns a candidate of publication id, hoursOld hours old.
+func candidate(id uint64, hoursOld float64, likes, reposts uint64) Candidate {
+	return Candidate{Publication: models.Publication{
+		ID:         id,
+		Likes:      likes,
+		Reposts:    reposts,
+		Created_at: now.Add(-time.Duration(hoursOld * float64(time.Hour))),
+	}}
+}
+
+func TestEngagementRankerScore(t *testing.T) {
+	withAffinity := candidate(1, 0, 0, 0)
+	withAffinity.Affinity = 4
+	secondDegree := candidate(1, 0, 0, 0)
+	secondDegree.SecondDegree = true
+
+	tests := []struct {
+		name      string
+		candidate Candidate
+		expected  float64
+	}{
+		// (1 + likes + 2*reposts) * (1 + affinity/2) / (age + 2)
+		{name: "new without engagement", candidate: candidate(1, 0, 0, 0), expected: 0.5},
+		{name: "likes and reposts", candidate: candidate(1, 0, 3, 2), expected: 4},
+		{name: "affinity", candidate: withAffinity, expected: 1.5},
+		{name: "second degree", candidate: secondDegree, expected: 0.25},
+		{name: "aged", candidate: candidate(1, 8, 9, 0), expected: 1},
+		// clocks out of step do not make a publication score more than a new one
+		{name: "from the future", candidate: candidate(1, -5, 0, 0), expected: 0.5},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if score := testRanker.Score(test.candidate, now); math.Abs(score-test.expected) > 1e-9 {
+				t.Errorf("Score = %v, want %v", score, test.expected)
+			}
+		})
+	}
+}
+
+func TestEngagementRankerDecay(t *testing.T) {
+	ranker := DefaultRanker.(EngagementRanker)
+	popular := candidate(1, 0, 50, 10)
+
+	previous := ranker.Score(popular, now)
+	for _, hours := range []float64{1, 6, 24, 72, 168} {
+		score := ranker.Score(popular, now.Add(time.Duration(hours)*time.Hour))
+		if score <= 0 || score >= previous {
+			t.Fatalf("score after %vh = %v, want below %v and above 0", hours, score, previous)
+		}
+		previous = score
+	}
+
+	// a day later, the popular publication falls behind a new one with a
+	// few likes
+	if old, fresh := ranker.Score(candidate(1, 24, 50, 10), now), ranker.Score(candidate(2, 0, 5, 0), now); old >= fresh {
+		t.Errorf("day-old popular publication scores %v, not below %v of a new one", old, fresh)
+	}
+}
+
+func TestRank(t *testing.T) {
+	tests := []struct {
+		name       string
+		candidates []Candidate
+		expected   []uint64
+	}{
+		{
+			name:       "engagement",
+			candidates: []Candidate{candidate(1, 1, 0, 0), candidate(2, 1, 10, 0), candidate(3, 1, 0, 3)},
+			expected:   []uint64{2, 3, 1},
+		},
+		{
+			name:       "age",
+			candidates: []Candidate{candidate(1, 30, 4, 0), candidate(2, 2, 4, 0), candidate(3, 10, 4, 0)},
+			expected:   []uint64{2, 3, 1},
+		},
+		{
+			// equal scores put the newest publication first
+			name:       "ties",
+			candidates: []Candidate{candidate(4, 3, 1, 0), candidate(9, 3, 1, 0), candidate(7, 3, 1, 0)},
+			expected:   []uint64{9, 7, 4},
+		},
+		{
+			name:       "empty",
+			candidates: nil,
+			expected:   []uint64{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ranked := rank(testRanker, test.candidates, now)
+			ids := make([]uint64, len(ranked))
+			for i, publication := range ranked {
+				ids[i] = publication.ID
+			}
+			if !slices.Equal(ids, test.expected) {
+				t.Errorf("rank = %v, want %v", ids, test.expected)
+			}
+		})
+	}
+}
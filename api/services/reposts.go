@@ -0,0 +1,48 @@
+package services
+
+import "github.com/wesleywcr/dev-book/api/models"
+
+// resolveReposts replaces each plain repost by a copy of its original
+// attributed to the reposter, and attaches the original of each quote.
+// Plain reposts whose original was deleted or is hidden from viewerId are
+// dropped, and quotes of such originals are shown without them.
+func (presenter presenter) resolveReposts(viewerId uint64, list []models.Publication) ([]models.Publication, error) {
+	var originalIds []uint64
+	for _, publication := range list {
+		if publication.OriginalID != 0 {
+			originalIds = append(originalIds, publication.OriginalID)
+		}
+	}
+
+	originals, error := presenter.publications.SearchVisiblePublicationsByIds(viewerId, originalIds)
+	if error != nil {
+		return nil, error
+	}
+	if originals, error = presenter.decorate(originals); error != nil {
+		return nil, error
+	}
+
+	byId := make(map[uint64]models.Publication, len(originals))
+	for _, original := range originals {
+		byId[original.ID] = original
+	}
+
+	resolved := make([]models.Publication, 0, len(list))
+	for _, publication := range list {
+		original, found := byId[publication.OriginalID]
+
+		switch {
+		case publication.IsRepost() && found:
+			original.RepostedBy = &models.UserSummary{ID: publication.AuthorID, Nickname: publication.AuthorNickaname}
+			resolved = append(resolved, original)
+		case publication.IsRepost() || (publication.OriginalID == 0 && publication.Content == ""):
+			continue
+		default:
+			if found {
+				publication.Original = &original
+			}
+			resolved = append(resolved, publication)
+		}
+	}
+	return resolved, nil
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/wesleywcr/dev-book/api/models"
+)
+
+// hiddenOriginals finds the originals of reposts, except those hidden from
+// the viewer.
+type hiddenOriginals struct {
+	PublicationRepository
+	publications map[uint64]models.Publication
+	hidden       []uint64
+}
+
+func (fake hiddenOriginals) SearchVisiblePublicationsByIds(viewerId uint64, publicationIds []uint64) ([]models.Publication, error) {
+	var publications []models.Publication
+	for _, publicationId := range publicationIds {
+		if publication, found := fake.publications[publicationId]; found && !slices.Contains(fake.hidden, publicationId) {
+			publications = append(publications, publication)
+		}
+	}
+	return publications, nil
+}
+
+// noMentions and noAttachments have nothing to add to the publications.
+type noMentions struct {
+	MentionRepository
+}
+
+func (noMentions) SearchByPublications(publicationIds []uint64) (map[uint64][]models.Mention, error) {
+	return nil, nil
+}
+
+type noAttachments struct {
+	AttachmentRepository
+}
+
+func (noAttachments) SearchByPublications(publicationIds []uint64) (map[uint64][]models.Attachment, error) {
+	return nil, nil
+}
+
+func TestPresenterResolveReposts(t *testing.T) {
+	originals := map[uint64]models.Publication{
+		1: {ID: 1, AuthorID: 10, Content: "visível"},
+		2: {ID: 2, AuthorID: 11, Content: "de quem bloqueou"},
+	}
+	list := []models.Publication{
+		{ID: 3, AuthorID: 20, AuthorNickaname: "ana", OriginalID: 1},
+		{ID: 4, AuthorID: 20, AuthorNickaname: "ana", OriginalID: 2},
+		{ID: 5, AuthorID: 21, Content: "citando", OriginalID: 1},
+		{ID: 6, AuthorID: 21, Content: "citando", OriginalID: 2},
+		{ID: 7, AuthorID: 21, Content: "sem repost"},
+	}
+	presenter := presenter{
+		publications: hiddenOriginals{publications: originals, hidden: []uint64{2}},
+		mentions:     noMentions{},
+		attachments:  noAttachments{},
+		files:        newTestStorage(t),
+	}
+
+	resolved, error := presenter.resolveReposts(20, list)
+	if error != nil {
+		t.Fatal(error)
+	}
+
+	// the plain repost of the hidden original is dropped, and its quote is
+	// shown without it
+	expected := []struct {
+		id         uint64
+		repostedBy uint64
+		original   uint64
+	}{
+		{id: 1, repostedBy: 20},
+		{id: 5, original: 1},
+		{id: 6},
+		{id: 7},
+	}
+	if len(resolved) != len(expected) {
+		t.Fatalf("resolveReposts = %+v, want %+v", resolved, expected)
+	}
+	for i, publication := range resolved {
+		var repostedBy, original uint64
+		if publication.RepostedBy != nil {
+			repostedBy = publication.RepostedBy.ID
+		}
+		if publication.Original != nil {
+			original = publication.Original.ID
+		}
+		if publication.ID != expected[i].id || repostedBy != expected[i].repostedBy || original != expected[i].original {
+			t.Errorf("publication %d = %d reposted by %d quoting %d, want %+v", i, publication.ID, repostedBy, original, expected[i])
+		}
+	}
+}
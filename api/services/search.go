@@ -0,0 +1,157 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/wesleywcr/dev-book/api/models"
+	"github.com/wesleywcr/dev-book/api/storage"
+)
+
+// SearchIndex keeps the searchable copy of the publications up to date.
+type SearchIndex interface {
+	Index(publication models.Publication) error
+	Remove(publicationId uint64) error
+}
+
+// SearchBackend finds the publications matching a query.
+type SearchBackend interface {
+	SearchIndex
+	// Search returns the IDs of the matching publications, the most
+	// relevant first.
+	Search(query models.SearchQuery) ([]uint64, error)
+}
+
+const (
+	// searchBatchSize is how many results are read from the backend at once
+	// when filling a page of search results.
+	searchBatchSize = 50
+	// maxSearchScanned is how many results a single search reads from the
+	// backend at most, so results the viewer cannot see do not make it read
+	// the whole index. The page comes back short when it is reached.
+	maxSearchScanned = 1000
+)
+
+// SearchService serves the full-text search over publications.
+type SearchService struct {
+	backend      SearchBackend
+	publications PublicationRepository
+	users        UserRepository
+	mentions     MentionRepository
+	attachments  AttachmentRepository
+	files        storage.Storage
+}
+
+func NewSearchService(
+	backend SearchBackend,
+	publications PublicationRepository,
+	users UserRepository,
+	mentions MentionRepository,
+	attachments AttachmentRepository,
+	files storage.Storage,
+) *SearchService {
+	return &SearchService{backend, publications, users, mentions, attachments, files}
+}
+
+// Publications returns the publications matching the query, the most
+// relevant first, optionally written by the author with the given nickname.
+// Publications viewerId cannot see, from private accounts or blocked users,
+// are left out before paginating, so pages stay full.
+func (service SearchService) Publications(viewerId uint64, author string, query models.SearchQuery) ([]models.Publication, error) {
+	query.Terms = strings.TrimSpace(query.Terms)
+	if query.Terms == "" {
+		return nil, invalid(errors.New("O termo de busca é obrigatório"))
+	}
+	if !query.From.IsZero() && !query.Until.IsZero() && !query.From.Before(query.Until) {
+		return nil, invalid(errors.New("A data inicial deve ser anterior à data final"))
+	}
+	query.Tag = models.NormalizeTag(query.Tag)
+
+	if author = strings.TrimPrefix(strings.TrimSpace(author), "@"); author != "" {
+		users, error := service.users.SearchNicknames([]string{author})
+		if error != nil {
+			return nil, error
+		}
+		if len(users) == 0 {
+			return []models.Publication{}, nil
+		}
+		query.AuthorID = users[0].ID
+	}
+
+	// The backend knows nothing of who may see what, so the results are
+	// filtered before paginating: they are read in batches from the first
+	// one until the page is full, there are no more or maxSearchScanned
+	// were read.
+	page := query.Page
+	skip := page.Offset()
+	query.Page = models.Page{Number: 1, Limit: max(page.Limit, searchBatchSize)}
+
+	visible := map[uint64]bool{}
+	publications := make([]models.Publication, 0, page.Limit)
+	for scanned := uint64(0); uint64(len(publications)) < page.Limit && scanned < maxSearchScanned; {
+		ids, error := service.backend.Search(query)
+		if error != nil {
+			return nil, error
+		}
+		scanned += uint64(len(ids))
+		batch, error := service.visible(viewerId, ids, visible)
+		if error != nil {
+			return nil, error
+		}
+
+		for _, publication := range batch {
+			if skip > 0 {
+				skip--
+				continue
+			}
+			if uint64(len(publications)) < page.Limit {
+				publications = append(publications, publication)
+			}
+		}
+		if uint64(len(ids)) < query.Page.Limit {
+			break
+		}
+		query.Page.Number++
+	}
+	return presenter{service.publications, service.mentions, service.attachments, service.files}.present(viewerId, publications)
+}
+
+// visible returns the publications of ids that viewerId can see, in the same
+// order. visible caches whether each author was already found visible.
+func (service SearchService) visible(viewerId uint64, ids []uint64, visible map[uint64]bool) ([]models.Publication, error) {
+	found, error := service.publications.SearchPublicationsByIds(ids)
+	if error != nil {
+		return nil, error
+	}
+
+	byId := make(map[uint64]models.Publication, len(found))
+	for _, publication := range found {
+		byId[publication.ID] = publication
+	}
+
+	publications := make([]models.Publication, 0, len(ids))
+	for _, publicationId := range ids {
+		publication, exists := byId[publicationId]
+		if !exists {
+			continue
+		}
+
+		authorId := publication.AuthorID
+		if _, checked := visible[authorId]; !checked {
+			visible[authorId] = true
+			if error = ensureVisible(service.users, viewerId, authorId); error == nil {
+				error = ensureNotBlocked(service.users, viewerId, authorId)
+			}
+			var serviceError *Error
+			if errors.As(error, &serviceError) {
+				visible[authorId] = false
+			} else if error != nil {
+				return nil, error
+			}
+		}
+		if visible[authorId] {
+			publications = append(publications, publication)
+		}
+	}
+	return publications, nil
+}
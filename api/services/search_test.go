@@ -0,0 +1,176 @@
+package services
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/wesleywcr/dev-book/api/models"
+)
+
+// rankedBackend returns the same ranked IDs for any query, a page at a time.
+type rankedBackend struct {
+	SearchBackend
+	ids []uint64
+}
+
+func (backend rankedBackend) Search(query models.SearchQuery) ([]uint64, error) {
+	offset := min(query.Page.Offset(), uint64(len(backend.ids)))
+	end := min(offset+query.Page.Limit, uint64(len(backend.ids)))
+	return backend.ids[offset:end], nil
+}
+
+// countingBackend counts the searches made to the backend it wraps.
+type countingBackend struct {
+	rankedBackend
+	searches *int
+	scanned  *int
+}
+
+func (backend countingBackend) Search(query models.SearchQuery) ([]uint64, error) {
+	ids, error := backend.rankedBackend.Search(query)
+	*backend.searches++
+	*backend.scanned += len(ids)
+	return ids, error
+}
+
+// indexedPublications holds the publications found by the searches.
+type indexedPublications struct {
+	PublicationRepository
+	publications map[uint64]models.Publication
+}
+
+func (fake indexedPublications) SearchPublicationsByIds(publicationIds []uint64) ([]models.Publication, error) {
+	var publications []models.Publication
+	for _, publicationId := range publicationIds {
+		if publication, found := fake.publications[publicationId]; found {
+			publications = append(publications, publication)
+		}
+	}
+	return publications, nil
+}
+
+// SearchVisiblePublicationsByIds finds no originals, as there are no reposts.
+func (fake indexedPublications) SearchVisiblePublicationsByIds(viewerId uint64, publicationIds []uint64) ([]models.Publication, error) {
+	return nil, nil
+}
+
+// searchUsers knows the authors, whom they blocked and who follows them.
+type searchUsers struct {
+	UserRepository
+	users     map[uint64]models.User
+	blocks    map[pair]bool
+	followers map[pair]bool
+}
+
+func (fake searchUsers) SearchPerId(ID uint64) (models.User, error) {
+	return fake.users[ID], nil
+}
+
+func (fake searchUsers) Blocked(userId, otherId uint64) (bool, error) {
+	return fake.blocks[pair{userId, otherId}] || fake.blocks[pair{otherId, userId}], nil
+}
+
+func (fake searchUsers) IsFollower(userId, followerId uint64) (bool, error) {
+	return fake.followers[pair{userId, followerId}], nil
+}
+
+// everyThird returns every third ID from from up to to.
+func everyThird(from, to uint64) []uint64 {
+	var ids []uint64
+	for id := from; id <= to; id += 3 {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func TestSearchServicePublications(t *testing.T) {
+	// 130 results, most relevant first, cycling through the authors: the
+	// public account 10, the private account 11 and 12, who blocked ana.
+	backend := rankedBackend{}
+	publications := indexedPublications{publications: map[uint64]models.Publication{}}
+	for id := uint64(1); id <= 130; id++ {
+		backend.ids = append(backend.ids, id)
+		publications.publications[id] = models.Publication{ID: id, AuthorID: []uint64{12, 10, 11}[id%3], Content: "go"}
+	}
+	users := searchUsers{
+		users: map[uint64]models.User{
+			1:  {ID: 1, Nickname: "ana"},
+			10: {ID: 10, Nickname: "bia"},
+			11: {ID: 11, Nickname: "caio", IsPrivate: true},
+			12: {ID: 12, Nickname: "duda"},
+		},
+		blocks: map[pair]bool{{12, 1}: true},
+	}
+	service := NewSearchService(backend, publications, users, noMentions{}, noAttachments{}, newTestStorage(t))
+
+	tests := []struct {
+		name     string
+		viewerId uint64
+		terms    string
+		page     models.Page
+		kind     error
+		expected []uint64
+	}{
+		// ana sees only the publications of 10, every third result
+		{name: "first page", viewerId: 1, terms: "go", page: models.Page{Number: 1, Limit: 5}, expected: []uint64{1, 4, 7, 10, 13}},
+		{name: "page past a batch", viewerId: 1, terms: "go", page: models.Page{Number: 2, Limit: 20}, expected: everyThird(61, 118)},
+		{name: "last page", viewerId: 1, terms: "go", page: models.Page{Number: 3, Limit: 20}, expected: everyThird(121, 130)},
+		{name: "past the end", viewerId: 1, terms: "go", page: models.Page{Number: 4, Limit: 20}, expected: []uint64{}},
+		// the private account sees its own publications, and no one blocked it
+		{name: "own private account", viewerId: 11, terms: "go", page: models.Page{Number: 1, Limit: 4}, expected: []uint64{1, 2, 3, 4}},
+		{name: "no terms", viewerId: 1, terms: "  ", page: models.Page{Number: 1, Limit: 5}, kind: ErrInvalid},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			found, error := service.Publications(test.viewerId, "", models.SearchQuery{Terms: test.terms, Page: test.page})
+			if kindOf(error) != test.kind {
+				t.Fatalf("Publications = %v, want %v", error, test.kind)
+			}
+			if test.kind != nil {
+				return
+			}
+
+			ids := make([]uint64, len(found))
+			for i, publication := range found {
+				ids[i] = publication.ID
+			}
+			if !slices.Equal(ids, test.expected) {
+				t.Errorf("Publications = %v, want %v", ids, test.expected)
+			}
+		})
+	}
+}
+
+func TestSearchServicePublicationsScansAtMost(t *testing.T) {
+	// only the last of the results can be seen by ana, who was blocked by the
+	// author of all the others
+	var searches, scanned int
+	backend := countingBackend{searches: &searches, scanned: &scanned}
+	publications := indexedPublications{publications: map[uint64]models.Publication{}}
+	for id := uint64(1); id <= 3*maxSearchScanned; id++ {
+		backend.ids = append(backend.ids, id)
+		publications.publications[id] = models.Publication{ID: id, AuthorID: 12, Content: "go"}
+	}
+	publications.publications[3*maxSearchScanned] = models.Publication{ID: 3 * maxSearchScanned, AuthorID: 10, Content: "go"}
+	users := searchUsers{
+		users: map[uint64]models.User{
+			1:  {ID: 1, Nickname: "ana"},
+			10: {ID: 10, Nickname: "bia"},
+			12: {ID: 12, Nickname: "duda"},
+		},
+		blocks: map[pair]bool{{12, 1}: true},
+	}
+	service := NewSearchService(backend, publications, users, noMentions{}, noAttachments{}, newTestStorage(t))
+
+	found, error := service.Publications(1, "", models.SearchQuery{Terms: "go", Page: models.Page{Number: 1, Limit: 10}})
+	if error != nil {
+		t.Fatal(error)
+	}
+	if len(found) != 0 {
+		t.Errorf("Publications = %d publications, want none", len(found))
+	}
+	if scanned != maxSearchScanned || searches != maxSearchScanned/searchBatchSize {
+		t.Errorf("scanned %d results in %d searches, want %d in %d", scanned, searches, maxSearchScanned, maxSearchScanned/searchBatchSize)
+	}
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/wesleywcr/dev-book/api/events"
+	"github.com/wesleywcr/dev-book/api/models"
+)
+
+// SessionRepository is the storage of the sessions of the users.
+type SessionRepository interface {
+	Create(session models.Session) (uint64, error)
+	Search(userId uint64, since time.Time) ([]models.Session, error)
+	Check(sessionId, userId uint64) (open, suspended bool, role models.Role, error error)
+	Delete(sessionId, userId uint64) (bool, error)
+	DeleteOthers(userId, keepId uint64) (int64, error)
+	DeleteExpired(userId uint64, before time.Time) error
+}
+
+// SessionService keeps track of where each user is logged in. A session
+// lasts as long as the token issued with it. Revoking a session ends the
+// event streams opened with it.
+type SessionService struct {
+	sessions SessionRepository
+	broker   events.Broker
+	lifetime time.Duration
+}
+
+func NewSessionService(sessions SessionRepository, broker events.Broker, lifetime time.Duration) *SessionService {
+	return &SessionService{sessions, broker, lifetime}
+}
+
+// Start opens a session for userId on the device described by ip and
+// userAgent, forgetting the expired ones.
+func (service SessionService) Start(userId uint64, ip, userAgent string) (models.Session, error) {
+	if error := service.sessions.DeleteExpired(userId, service.since()); error != nil {
+		return models.Session{}, error
+	}
+
+	session := models.Session{UserID: userId, IP: ip, UserAgent: userAgent}
+	session.Prepare()
+	ID, error := service.sessions.Create(session)
+	if error != nil {
+		return models.Session{}, error
+	}
+	session.ID = ID
+	return session, nil
+}
+
+// List returns the open sessions of userId, marking currentId.
+func (service SessionService) List(userId, currentId uint64) ([]models.Session, error) {
+	sessions, error := service.sessions.Search(userId, service.since())
+	if error != nil {
+		return nil, error
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentId
+	}
+	return sessions, nil
+}
+
+// Revoke ends a session of userId, whose tokens stop working.
+func (service SessionService) Revoke(userId, sessionId uint64) error {
+	revoked, error := service.sessions.Delete(sessionId, userId)
+	if error != nil {
+		return error
+	}
+	if !revoked {
+		return notFound("Sessão não encontrada")
+	}
+	service.ended(sessionId)
+	return nil
+}
+
+// RevokeOthers ends every session of userId but currentId, returning how many.
+func (service SessionService) RevokeOthers(userId, currentId uint64) (int64, error) {
+	sessions, error := service.sessions.Search(userId, time.Time{})
+	if error != nil {
+		return 0, error
+	}
+	revoked, error := service.sessions.DeleteOthers(userId, currentId)
+	if error != nil {
+		return 0, error
+	}
+	for _, session := range sessions {
+		if session.ID != currentId {
+			service.ended(session.ID)
+		}
+	}
+	return revoked, nil
+}
+
+// Active tells whether the session of userId is still open and the account
+// not suspended, for the streams that outlive the request checking it.
+func (service SessionService) Active(sessionId, userId uint64) (bool, error) {
+	open, suspended, _, error := service.sessions.Check(sessionId, userId)
+	return open && !suspended, error
+}
+
+// ended tells the streams of a revoked session to close. Streams on other
+// instances notice it when they next check the session.
+func (service SessionService) ended(sessionId uint64) {
+	if error := service.broker.Publish(events.SessionTopic(sessionId), events.Event{Type: events.TypeSessionRevoked}); error != nil {
+		slog.Warn("event not published", "type", events.TypeSessionRevoked, "session", sessionId, "error", error)
+	}
+}
+
+// since is when the oldest session still open was started.
+func (service SessionService) since() time.Time {
+	return time.Now().Add(-service.lifetime).UTC()
+}
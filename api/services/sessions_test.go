@@ -0,0 +1,99 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wesleywcr/dev-book/api/events"
+	"github.com/wesleywcr/dev-book/api/models"
+)
+
+type fakeSessions struct {
+	SessionRepository
+	sessions  map[uint64]models.Session
+	suspended map[uint64]bool
+}
+
+func (fake *fakeSessions) Check(sessionId, userId uint64) (open, suspended bool, role models.Role, error error) {
+	session, found := fake.sessions[sessionId]
+	if !found || session.UserID != userId {
+		return false, false, "", nil
+	}
+	return true, fake.suspended[userId], models.RoleUser, nil
+}
+
+func (fake *fakeSessions) Delete(sessionId, userId uint64) (bool, error) {
+	if session, found := fake.sessions[sessionId]; !found || session.UserID != userId {
+		return false, nil
+	}
+	delete(fake.sessions, sessionId)
+	return true, nil
+}
+
+func TestSessionServiceRevoke(t *testing.T) {
+	tests := []struct {
+		name      string
+		userId    uint64
+		sessionId uint64
+		kind      error
+	}{
+		{name: "own session", userId: 1, sessionId: 10},
+		{name: "session of someone else", userId: 2, sessionId: 10, kind: ErrNotFound},
+		{name: "missing session", userId: 1, sessionId: 99, kind: ErrNotFound},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sessions := &fakeSessions{sessions: map[uint64]models.Session{10: {ID: 10, UserID: 1}}}
+			broker := events.NewMemoryBroker(4)
+			service := NewSessionService(sessions, broker, time.Hour)
+
+			var error error
+			streamed := received(broker, events.SessionTopic(test.sessionId), func() {
+				error = service.Revoke(test.userId, test.sessionId)
+			})
+			if kindOf(error) != test.kind {
+				t.Fatalf("Revoke = %v, want %v", error, test.kind)
+			}
+
+			// the streams of the session are told to close only when it ends
+			_, open := sessions.sessions[10]
+			ended := len(streamed) == 1 && streamed[0].Type == events.TypeSessionRevoked
+			if open == (test.kind == nil) || ended != (test.kind == nil) {
+				t.Errorf("session open: %v, streams told: %v", open, ended)
+			}
+		})
+	}
+}
+
+func TestSessionServiceActive(t *testing.T) {
+	tests := []struct {
+		name      string
+		userId    uint64
+		sessionId uint64
+		active    bool
+	}{
+		{name: "open session", userId: 1, sessionId: 10, active: true},
+		{name: "revoked session", userId: 1, sessionId: 11},
+		{name: "session of someone else", userId: 2, sessionId: 10},
+		{name: "suspended account", userId: 3, sessionId: 30},
+	}
+
+	sessions := &fakeSessions{
+		sessions:  map[uint64]models.Session{10: {ID: 10, UserID: 1}, 30: {ID: 30, UserID: 3}},
+		suspended: map[uint64]bool{3: true},
+	}
+	service := NewSessionService(sessions, events.NewMemoryBroker(1), time.Hour)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			active, error := service.Active(test.sessionId, test.userId)
+			if error != nil {
+				t.Fatal(error)
+			}
+			if active != test.active {
+				t.Errorf("Active = %v, want %v", active, test.active)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"sort"
+
+	"github.com/wesleywcr/dev-book/api/models"
+)
+
+// Weights of the signals behind a suggestion.
+const (
+	followedByWeight     = 3
+	mutualFollowerWeight = 1
+	sharedTagWeight      = 2
+)
+
+// maxListedSignals bounds the users and hashtags listed in a suggestion.
+const maxListedSignals = 3
+
+// SuggestionRepository reads the signals used to recommend accounts.
+type SuggestionRepository interface {
+	SearchFollowedByFollowing(userId uint64) (map[uint64][]models.UserSummary, error)
+	SearchMutualFollowers(userId uint64) (map[uint64]int, error)
+	SearchSharedTags(userId uint64) (map[uint64][]string, error)
+	SearchSummaries(userIds []uint64) (map[uint64]models.UserSummary, error)
+}
+
+// SuggestionService recommends accounts to follow from the follower graph
+// and the hashtags in common.
+type SuggestionService struct {
+	suggestions SuggestionRepository
+}
+
+func NewSuggestionService(suggestions SuggestionRepository) *SuggestionService {
+	return &SuggestionService{suggestions}
+}
+
+// Suggest returns accounts userId may want to follow, the best first: those
+// followed by the users they follow, sharing followers with them, or using
+// the same hashtags.
+func (service SuggestionService) Suggest(userId uint64, page models.Page) ([]models.Suggestion, error) {
+	followedBy, error := service.suggestions.SearchFollowedByFollowing(userId)
+	if error != nil {
+		return nil, error
+	}
+	mutuals, error := service.suggestions.SearchMutualFollowers(userId)
+	if error != nil {
+		return nil, error
+	}
+	sharedTags, error := service.suggestions.SearchSharedTags(userId)
+	if error != nil {
+		return nil, error
+	}
+
+	scores := map[uint64]int{}
+	for candidateId, followers := range followedBy {
+		scores[candidateId] += followedByWeight * len(followers)
+	}
+	for candidateId, count := range mutuals {
+		scores[candidateId] += mutualFollowerWeight * count
+	}
+	for candidateId, tags := range sharedTags {
+		scores[candidateId] += sharedTagWeight * len(tags)
+	}
+
+	candidates := make([]uint64, 0, len(scores))
+	for candidateId := range scores {
+		candidates = append(candidates, candidateId)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if scores[a] != scores[b] {
+			return scores[a] > scores[b]
+		}
+		return a < b
+	})
+
+	start := min(page.Offset(), uint64(len(candidates)))
+	end := min(start+page.Limit, uint64(len(candidates)))
+	candidates = candidates[start:end]
+
+	summaries, error := service.suggestions.SearchSummaries(candidates)
+	if error != nil {
+		return nil, error
+	}
+
+	suggestions := make([]models.Suggestion, 0, len(candidates))
+	for _, candidateId := range candidates {
+		suggestion := models.Suggestion{
+			User:            summaries[candidateId],
+			FollowedBy:      followedBy[candidateId],
+			FollowedByCount: len(followedBy[candidateId]),
+			MutualFollowers: mutuals[candidateId],
+			SharedTags:      sharedTags[candidateId],
+		}
+		if len(suggestion.FollowedBy) > maxListedSignals {
+			suggestion.FollowedBy = suggestion.FollowedBy[:maxListedSignals]
+		}
+		if len(suggestion.SharedTags) > maxListedSignals {
+			suggestion.SharedTags = suggestion.SharedTags[:maxListedSignals]
+		}
+		suggestion.Explain()
+		suggestions = append(suggestions, suggestion)
+	}
+	return suggestions, nil
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/wesleywcr/dev-book/api/models"
+)
+
+// fakeSuggestions returns the same signals for any user.
+type fakeSuggestions struct {
+	followedBy map[uint64][]models.UserSummary
+	mutuals    map[uint64]int
+	sharedTags map[uint64][]string
+}
+
+func (fake fakeSuggestions) SearchFollowedByFollowing(userId uint64) (map[uint64][]models.UserSummary, error) {
+	return fake.followedBy, nil
+}
+
+func (fake fakeSuggestions) SearchMutualFollowers(userId uint64) (map[uint64]int, error) {
+	return fake.mutuals, nil
+}
+
+func (fake fakeSuggestions) SearchSharedTags(userId uint64) (map[uint64][]string, error) {
+	return fake.sharedTags, nil
+}
+
+func (fake fakeSuggestions) SearchSummaries(userIds []uint64) (map[uint64]models.UserSummary, error) {
+	summaries := map[uint64]models.UserSummary{}
+	for _, userId := range userIds {
+		summaries[userId] = models.UserSummary{ID: userId}
+	}
+	return summaries, nil
+}
+
+func TestSuggestionServiceSuggest(t *testing.T) {
+	followers := []models.UserSummary{{ID: 50}, {ID: 51}, {ID: 52}, {ID: 53}}
+	service := NewSuggestionService(fakeSuggestions{
+		// scores: 20 = 3*4 + 2*1 = 14, 21 = 3*1 + 1*2 = 5, 22 = 2*2 = 4,
+		// 23 = 1*4 = 4, 24 = 1*1 = 1
+		followedBy: map[uint64][]models.UserSummary{20: followers, 21: followers[:1]},
+		mutuals:    map[uint64]int{21: 2, 23: 4, 24: 1},
+		sharedTags: map[uint64][]string{20: {"go"}, 22: {"go", "rust"}},
+	})
+
+	tests := []struct {
+		name     string
+		page     models.Page
+		expected []uint64
+	}{
+		// equal scores put the lower ID first
+		{name: "first page", page: models.Page{Number: 1, Limit: 3}, expected: []uint64{20, 21, 22}},
+		{name: "second page", page: models.Page{Number: 2, Limit: 3}, expected: []uint64{23, 24}},
+		{name: "past the end", page: models.Page{Number: 3, Limit: 3}, expected: []uint64{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			suggestions, error := service.Suggest(1, test.page)
+			if error != nil {
+				t.Fatal(error)
+			}
+			ids := make([]uint64, len(suggestions))
+			for i, suggestion := range suggestions {
+				ids[i] = suggestion.User.ID
+			}
+			if !slices.Equal(ids, test.expected) {
+				t.Errorf("Suggest = %v, want %v", ids, test.expected)
+			}
+		})
+	}
+
+	suggestions, error := service.Suggest(1, models.Page{Number: 1, Limit: 1})
+	if error != nil {
+		t.Fatal(error)
+	}
+	if first := suggestions[0]; first.FollowedByCount != 4 || len(first.FollowedBy) != maxListedSignals {
+		t.Errorf("suggestion lists %d of %d followers, want %d of 4", len(first.FollowedBy), first.FollowedByCount, maxListedSignals)
+	}
+}
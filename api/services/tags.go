@@ -0,0 +1,61 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/wesleywcr/dev-book/api/models"
+	"github.com/wesleywcr/dev-book/api/storage"
+)
+
+// maxTrendingWindow bounds how far back trending tags are computed.
+const maxTrendingWindow = 30 * 24 * time.Hour
+
+// TagRepository is the storage of hashtags and of the publications using them.
+type TagRepository interface {
+	Replace(publicationId uint64, names []string) error
+	Trending(since time.Time, limit uint64) ([]models.Tag, error)
+}
+
+// TagService serves the discovery of publications through hashtags.
+type TagService struct {
+	tags         TagRepository
+	publications PublicationRepository
+	mentions     MentionRepository
+	attachments  AttachmentRepository
+	files        storage.Storage
+}
+
+func NewTagService(
+	tags TagRepository,
+	publications PublicationRepository,
+	mentions MentionRepository,
+	attachments AttachmentRepository,
+	files storage.Storage,
+) *TagService {
+	return &TagService{tags, publications, mentions, attachments, files}
+}
+
+// Publications lists the publications using tag that viewerId can see,
+// newest first.
+func (service TagService) Publications(viewerId uint64, tag string, page models.Page) ([]models.Publication, error) {
+	tag = models.NormalizeTag(tag)
+	if tag == "" {
+		return nil, invalid(errors.New("A hashtag é obrigatória"))
+	}
+
+	publications, error := service.publications.SearchPublicationsByTag(viewerId, tag, page)
+	if error != nil {
+		return nil, error
+	}
+	return presenter{service.publications, service.mentions, service.attachments, service.files}.present(viewerId, publications)
+}
+
+// Trending lists the limit most used tags among publications created in the
+// last window.
+func (service TagService) Trending(window time.Duration, limit uint64) ([]models.Tag, error) {
+	if window <= 0 || window > maxTrendingWindow {
+		return nil, invalid(errors.New("A janela deve estar entre 0 e 720h"))
+	}
+	return service.tags.Trending(time.Now().Add(-window).UTC(), limit)
+}
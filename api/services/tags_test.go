@@ -0,0 +1,57 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wesleywcr/dev-book/api/models"
+)
+
+// fakeTags records the period trending tags were asked for.
+type fakeTags struct {
+	TagRepository
+	since time.Time
+}
+
+func (fake *fakeTags) Trending(since time.Time, limit uint64) ([]models.Tag, error) {
+	fake.since = since
+	return []models.Tag{{Name: "go"}}, nil
+}
+
+func TestTagServiceTrending(t *testing.T) {
+	tests := []struct {
+		name   string
+		window time.Duration
+		kind   error
+	}{
+		{name: "a day", window: 24 * time.Hour},
+		{name: "the longest window", window: maxTrendingWindow},
+		{name: "no window", window: 0, kind: ErrInvalid},
+		{name: "negative window", window: -time.Hour, kind: ErrInvalid},
+		{name: "too long", window: maxTrendingWindow + time.Hour, kind: ErrInvalid},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tags := &fakeTags{}
+			service := NewTagService(tags, nil, nil, nil, nil)
+
+			trending, error := service.Trending(test.window, 10)
+			if kindOf(error) != test.kind {
+				t.Fatalf("Trending = %v, want %v", error, test.kind)
+			}
+			if test.kind != nil {
+				if !tags.since.IsZero() {
+					t.Error("the repository was queried")
+				}
+				return
+			}
+			if len(trending) != 1 {
+				t.Errorf("Trending = %v", trending)
+			}
+			if elapsed := time.Since(tags.since); elapsed < test.window || elapsed > test.window+time.Minute {
+				t.Errorf("trending since %v ago, want %v", elapsed, test.window)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"time"
+
+	"github.com/wesleywcr/dev-book/api/models"
+)
+
+// backfillSize is how many publications of a newly followed user are copied
+// into the timeline of the follower.
+const backfillSize = 100
+
+// TimelineRepository is the storage of the materialized home timelines.
+type TimelineRepository interface {
+	Add(publicationId, authorId uint64, toFollowers bool) error
+	Backfill(userId, authorId uint64, limit int) error
+	RemoveAuthor(userId, authorId uint64) error
+	SearchPopularFollowed(userId uint64, limit int) ([]uint64, error)
+	Search(userId uint64, authorIds []uint64, page models.Page) ([]models.Publication, error)
+	SearchSince(userId uint64, authorIds []uint64, since time.Time, limit uint64) ([]models.Publication, error)
+	SearchSecondDegree(userId uint64, since time.Time, limit uint64) ([]models.Publication, error)
+	SearchInteractions(userId uint64, since time.Time) (map[uint64]int, error)
+}
+
+// Timeline keeps the home timelines materialized: a publication is copied
+// into the timelines of the followers of its author when it is written.
+// Authors followed by more than fanOutLimit users are the exception; their
+// publications are merged into the timelines when they are read. The ranker
+// orders the ranked feed.
+type Timeline struct {
+	timelines   TimelineRepository
+	fanOutLimit int
+	ranker      Ranker
+}
+
+func NewTimeline(timelines TimelineRepository, fanOutLimit int, ranker Ranker) *Timeline {
+	return &Timeline{timelines, fanOutLimit, ranker}
+}
+
+// publish places a new publication of authorId, who has the given number of
+// followers, in the timelines.
+func (timeline Timeline) publish(publicationId, authorId uint64, followers int) error {
+	return timeline.timelines.Add(publicationId, authorId, followers <= timeline.fanOutLimit)
+}
+
+// follow brings the latest publications of authorId into the timeline of userId.
+func (timeline Timeline) follow(userId, authorId uint64) error {
+	return timeline.timelines.Backfill(userId, authorId, backfillSize)
+}
+
+// unfollow takes the publications of authorId out of the timeline of userId.
+func (timeline Timeline) unfollow(userId, authorId uint64) error {
+	return timeline.timelines.RemoveAuthor(userId, authorId)
+}
+
+// read returns a page of the timeline of userId, newest first.
+func (timeline Timeline) read(userId uint64, page models.Page) ([]models.Publication, error) {
+	popular, error := timeline.timelines.SearchPopularFollowed(userId, timeline.fanOutLimit)
+	if error != nil {
+		return nil, error
+	}
+	return timeline.timelines.Search(userId, popular, page)
+}
+
+// candidates gathers the publications created since the given time that can
+// enter the ranked feed of userId, as shown to clients: those of their
+// timeline and those of their second-degree connections.
+func (timeline Timeline) candidates(userId uint64, since time.Time, presenter presenter) ([]Candidate, error) {
+	popular, error := timeline.timelines.SearchPopularFollowed(userId, timeline.fanOutLimit)
+	if error != nil {
+		return nil, error
+	}
+	followed, error := timeline.timelines.SearchSince(userId, popular, since, maxTimelineCandidates)
+	if error != nil {
+		return nil, error
+	}
+	if followed, error = presenter.present(userId, followed); error != nil {
+		return nil, error
+	}
+
+	secondDegree, error := timeline.timelines.SearchSecondDegree(userId, since, maxSecondDegreeCandidates)
+	if error != nil {
+		return nil, error
+	}
+	if secondDegree, error = presenter.present(userId, secondDegree); error != nil {
+		return nil, error
+	}
+
+	interactions, error := timeline.timelines.SearchInteractions(userId, since)
+	if error != nil {
+		return nil, error
+	}
+
+	// Reposts turn into their originals, which may already be candidates.
+	seen := map[uint64]bool{}
+	var candidates []Candidate
+	for i, publications := range [][]models.Publication{followed, secondDegree} {
+		for _, publication := range publications {
+			if seen[publication.ID] {
+				continue
+			}
+			seen[publication.ID] = true
+			candidates = append(candidates, Candidate{
+				Publication:  publication,
+				Affinity:     interactions[publication.AuthorID],
+				SecondDegree: i == 1,
+			})
+		}
+	}
+	return candidates, nil
+}
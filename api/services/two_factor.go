@@ -0,0 +1,238 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/wesleywcr/dev-book/api/models"
+	"github.com/wesleywcr/dev-book/api/security"
+)
+
+const (
+	// totpIssuer names the account in the authenticator apps.
+	totpIssuer = "DevBook"
+	// recoveryCodeCount is how many recovery codes are issued at once.
+	recoveryCodeCount = 10
+	// maxTwoFactorFailures wrong codes in a row lock the second step of the
+	// login for twoFactorLockout, so the codes cannot be guessed.
+	maxTwoFactorFailures = 5
+	twoFactorLockout     = 15 * time.Minute
+)
+
+// TwoFactorRepository is the storage of the TOTP secrets and recovery codes.
+type TwoFactorRepository interface {
+	Search(userId uint64) (models.TwoFactor, error)
+	Create(userId uint64, secret string) error
+	Enable(userId uint64, step int64, hashes []string) error
+	StartChallenge(userId uint64, challengeId string) error
+	UseChallenge(userId uint64, challengeId string) (bool, error)
+	RestoreChallenge(userId uint64, challengeId string) error
+	UseStep(userId uint64, step int64) (bool, error)
+	UseRecoveryCode(userId uint64, hash string) (bool, error)
+	CountRecoveryCodes(userId uint64) (int, error)
+	CountAttempt(userId uint64, maxFailures int, lockedSince time.Time) (bool, error)
+	Delete(userId uint64) error
+}
+
+// TwoFactorService owns the opt-in two-factor authentication: once enabled,
+// logging in also takes a code of an authenticator app or a recovery code.
+type TwoFactorService struct {
+	twoFactor TwoFactorRepository
+	users     UserRepository
+}
+
+func NewTwoFactorService(twoFactor TwoFactorRepository, users UserRepository) *TwoFactorService {
+	return &TwoFactorService{twoFactor, users}
+}
+
+// Status tells whether two-factor authentication is on for userId.
+func (service TwoFactorService) Status(userId uint64) (models.TwoFactorStatus, error) {
+	twoFactor, error := service.twoFactor.Search(userId)
+	if error != nil || !twoFactor.Enabled {
+		return models.TwoFactorStatus{}, error
+	}
+	count, error := service.twoFactor.CountRecoveryCodes(userId)
+	if error != nil {
+		return models.TwoFactorStatus{}, error
+	}
+	return models.TwoFactorStatus{Enabled: true, RecoveryCodes: count}, nil
+}
+
+// Enabled reports whether logging in as userId takes a code.
+func (service TwoFactorService) Enabled(userId uint64) (bool, error) {
+	twoFactor, error := service.twoFactor.Search(userId)
+	return twoFactor.Enabled, error
+}
+
+// Enroll generates a new secret for userId, which takes effect once
+// confirmed with a code. Enrolling again before confirming replaces it.
+func (service TwoFactorService) Enroll(userId uint64) (models.TwoFactorEnrollment, error) {
+	twoFactor, error := service.twoFactor.Search(userId)
+	if error != nil {
+		return models.TwoFactorEnrollment{}, error
+	}
+	if twoFactor.Enabled {
+		return models.TwoFactorEnrollment{}, forbidden("A autenticação em dois fatores já está ativada")
+	}
+
+	user, error := service.users.SearchPerId(userId)
+	if error != nil {
+		return models.TwoFactorEnrollment{}, error
+	}
+	if user.ID == 0 {
+		return models.TwoFactorEnrollment{}, notFound("Usuário não encontrado")
+	}
+
+	secret, error := security.NewTOTPSecret()
+	if error != nil {
+		return models.TwoFactorEnrollment{}, error
+	}
+	if error = service.twoFactor.Create(userId, secret); error != nil {
+		return models.TwoFactorEnrollment{}, error
+	}
+	return models.TwoFactorEnrollment{
+		Secret: secret,
+		URI:    security.TOTPURI(totpIssuer, user.Email, secret),
+	}, nil
+}
+
+// Confirm enables two-factor authentication for userId with a first code of
+// the enrolled secret, returning the recovery codes, which are only stored
+// hashed.
+func (service TwoFactorService) Confirm(userId uint64, code string) ([]string, error) {
+	twoFactor, error := service.twoFactor.Search(userId)
+	if error != nil {
+		return nil, error
+	}
+	if twoFactor.UserID == 0 {
+		return nil, forbidden("Inicie a ativação da autenticação em dois fatores antes de confirmá-la")
+	}
+	if twoFactor.Enabled {
+		return nil, forbidden("A autenticação em dois fatores já está ativada")
+	}
+
+	step, valid := security.VerifyTOTP(twoFactor.Secret, normalizeCode(code), time.Now(), twoFactor.LastStep)
+	if !valid {
+		return nil, invalid(errors.New("Código inválido"))
+	}
+
+	codes, error := security.NewRecoveryCodes(recoveryCodeCount)
+	if error != nil {
+		return nil, error
+	}
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = security.HashRecoveryCode(code)
+	}
+	if error = service.twoFactor.Enable(userId, step, hashes); error != nil {
+		return nil, error
+	}
+	return codes, nil
+}
+
+// Disable turns off two-factor authentication for userId, who proves it is
+// them with a code or a recovery code.
+func (service TwoFactorService) Disable(userId uint64, code string) error {
+	twoFactor, error := service.twoFactor.Search(userId)
+	if error != nil {
+		return error
+	}
+	if !twoFactor.Enabled {
+		return forbidden("A autenticação em dois fatores não está ativada")
+	}
+	if _, error = service.verify(twoFactor, code); error != nil {
+		return error
+	}
+	return service.twoFactor.Delete(userId)
+}
+
+// Challenge starts the second step of the login of userId, returning the ID
+// of its challenge. Only the challenge of the last login can be completed.
+func (service TwoFactorService) Challenge(userId uint64) (string, error) {
+	challengeId, error := security.NewChallengeID()
+	if error != nil {
+		return "", error
+	}
+	if error = service.twoFactor.StartChallenge(userId, challengeId); error != nil {
+		return "", error
+	}
+	return challengeId, nil
+}
+
+// Complete checks the code of the second step of the login of userId,
+// returning the user to issue the token to and whether a recovery code was
+// spent. The challenge is taken before the code is checked, so a replayed or
+// concurrent request cannot spend a code, and it is put back when the code
+// is wrong so the user can try again.
+func (service TwoFactorService) Complete(userId uint64, challengeId, code string) (models.User, bool, error) {
+	twoFactor, error := service.twoFactor.Search(userId)
+	if error != nil {
+		return models.User{}, false, error
+	}
+	if !twoFactor.Enabled || challengeId == "" || twoFactor.Challenge != challengeId {
+		return models.User{}, false, unauthorized(errors.New("Desafio inválido, faça login novamente"))
+	}
+
+	used, error := service.twoFactor.UseChallenge(userId, challengeId)
+	if error != nil {
+		return models.User{}, false, error
+	}
+	if !used {
+		return models.User{}, false, unauthorized(errors.New("Desafio inválido, faça login novamente"))
+	}
+
+	recovery, error := service.verify(twoFactor, code)
+	if error != nil {
+		if restoreError := service.twoFactor.RestoreChallenge(userId, challengeId); restoreError != nil {
+			return models.User{}, false, restoreError
+		}
+		return models.User{}, false, error
+	}
+
+	user, error := service.users.SearchPerId(userId)
+	if error != nil {
+		return models.User{}, false, error
+	}
+	if user.ID == 0 {
+		return models.User{}, false, unauthorized(errors.New("Desafio inválido, faça login novamente"))
+	}
+	if user.SuspendedAt != nil {
+		return models.User{}, false, forbidden("Esta conta está suspensa")
+	}
+	return models.User{ID: user.ID, Role: user.Role}, recovery, nil
+}
+
+// verify accepts a TOTP code newer than the last one used, or an unused
+// recovery code, which is spent. Every attempt is counted until a code is
+// accepted, and too many wrong ones lock the verification for a while.
+func (service TwoFactorService) verify(twoFactor models.TwoFactor, code string) (bool, error) {
+	counted, error := service.twoFactor.CountAttempt(
+		twoFactor.UserID, maxTwoFactorFailures, time.Now().Add(-twoFactorLockout).UTC(),
+	)
+	if error != nil {
+		return false, error
+	}
+	if !counted {
+		return false, forbidden("Muitas tentativas com código inválido, tente novamente mais tarde")
+	}
+
+	code = normalizeCode(code)
+	if step, valid := security.VerifyTOTP(twoFactor.Secret, code, time.Now(), twoFactor.LastStep); valid {
+		used, error := service.twoFactor.UseStep(twoFactor.UserID, step)
+		if error != nil || used {
+			return false, error
+		}
+	} else if len(code) > 6 {
+		used, error := service.twoFactor.UseRecoveryCode(twoFactor.UserID, security.HashRecoveryCode(code))
+		if error != nil || used {
+			return used, error
+		}
+	}
+	return false, unauthorized(errors.New("Código inválido"))
+}
+
+// normalizeCode drops the spaces users type or paste along with a code.
+func normalizeCode(code string) string {
+	return strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+}
@@ -0,0 +1,228 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/wesleywcr/dev-book/api/models"
+	"github.com/wesleywcr/dev-book/api/security"
+)
+
+// fakeTwoFactor holds the enrollment of a single user.
+type fakeTwoFactor struct {
+	TwoFactorRepository
+	twoFactor models.TwoFactor
+	// recoveryCodes tells, by hash, whether each recovery code is unused.
+	recoveryCodes map[string]bool
+	// raced completes the challenge from another request right before this
+	// one does.
+	raced     bool
+	completed bool
+}
+
+func (fake *fakeTwoFactor) Search(userId uint64) (models.TwoFactor, error) {
+	if userId != fake.twoFactor.UserID {
+		return models.TwoFactor{}, nil
+	}
+	return fake.twoFactor, nil
+}
+
+func (fake *fakeTwoFactor) UseStep(userId uint64, step int64) (bool, error) {
+	if step <= fake.twoFactor.LastStep {
+		return false, nil
+	}
+	fake.twoFactor.LastStep = step
+	fake.twoFactor.Failures = 0
+	return true, nil
+}
+
+func (fake *fakeTwoFactor) UseRecoveryCode(userId uint64, hash string) (bool, error) {
+	if !fake.recoveryCodes[hash] {
+		return false, nil
+	}
+	fake.recoveryCodes[hash] = false
+	fake.twoFactor.Failures = 0
+	return true, nil
+}
+
+func (fake *fakeTwoFactor) CountAttempt(userId uint64, maxFailures int, lockedSince time.Time) (bool, error) {
+	if fake.twoFactor.Failures >= maxFailures {
+		if fake.twoFactor.FailedAt.After(lockedSince) {
+			return false, nil
+		}
+		fake.twoFactor.Failures = 0
+	}
+	fake.twoFactor.Failures++
+	fake.twoFactor.FailedAt = at(time.Now())
+	return true, nil
+}
+
+func (fake *fakeTwoFactor) UseChallenge(userId uint64, challengeId string) (bool, error) {
+	if fake.raced {
+		fake.twoFactor.Challenge = ""
+	}
+	if challengeId == "" || fake.twoFactor.Challenge != challengeId {
+		return false, nil
+	}
+	fake.twoFactor.Challenge = ""
+	fake.completed = true
+	return true, nil
+}
+
+func (fake *fakeTwoFactor) RestoreChallenge(userId uint64, challengeId string) error {
+	if fake.twoFactor.Challenge == "" {
+		fake.twoFactor.Challenge = challengeId
+		fake.completed = false
+	}
+	return nil
+}
+
+// twoFactorUsers holds the users who log in.
+type twoFactorUsers struct {
+	UserRepository
+	users map[uint64]models.User
+}
+
+func (fake twoFactorUsers) SearchPerId(ID uint64) (models.User, error) {
+	return fake.users[ID], nil
+}
+
+// at returns a pointer to the time, for the optional dates of the models.
+func at(moment time.Time) *time.Time {
+	return &moment
+}
+
+// totpCode returns the code an authenticator app shows for secret at moment.
+func totpCode(t *testing.T, secret string, moment time.Time) string {
+	t.Helper()
+	key, error := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if error != nil {
+		t.Fatal(error)
+	}
+	mac := hmac.New(sha1.New, key)
+	binary.Write(mac, binary.BigEndian, uint64(moment.Unix()/30))
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	return fmt.Sprintf("%06d", (binary.BigEndian.Uint32(sum[offset:offset+4])&0x7fffffff)%1000000)
+}
+
+func TestTwoFactorServiceComplete(t *testing.T) {
+	const (
+		secret      = "JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"
+		challengeId = "3f2a9c"
+	)
+	code := totpCode(t, secret, time.Now())
+
+	tests := []struct {
+		name        string
+		challengeId string
+		code        string
+		// change alters the enrollment and the users before completing.
+		change    func(fake *fakeTwoFactor, users twoFactorUsers)
+		kind      error
+		recovery  bool
+		completed bool
+		failures  int
+		// spent tells whether the recovery code was spent.
+		spent bool
+	}{
+		{name: "code", challengeId: challengeId, code: code, completed: true},
+		{name: "code with spaces", challengeId: challengeId, code: code[:3] + " " + code[3:], completed: true},
+		{name: "recovery code", challengeId: challengeId, code: "ABCDE-fghij", recovery: true, completed: true, spent: true},
+		{name: "wrong code", challengeId: challengeId, code: "000000", kind: ErrUnauthorized, failures: 1},
+		{
+			name: "used code", challengeId: challengeId, code: code, kind: ErrUnauthorized, failures: 1,
+			change: func(fake *fakeTwoFactor, users twoFactorUsers) { fake.twoFactor.LastStep = time.Now().Unix()/30 + 1 },
+		},
+		{
+			name: "used recovery code", challengeId: challengeId, code: "abcde-fghij", kind: ErrUnauthorized, failures: 1,
+			change: func(fake *fakeTwoFactor, users twoFactorUsers) {
+				fake.recoveryCodes[security.HashRecoveryCode("abcde-fghij")] = false
+			},
+			spent: true,
+		},
+		// a challenge is completed once, and only the last one can be
+		{name: "challenge of an older login", challengeId: "older", code: code, kind: ErrUnauthorized},
+		{name: "token without a challenge", challengeId: "", code: code, kind: ErrUnauthorized},
+		{
+			name: "completed challenge", challengeId: "", code: code, kind: ErrUnauthorized,
+			change: func(fake *fakeTwoFactor, users twoFactorUsers) { fake.twoFactor.Challenge = "" },
+		},
+		{
+			name: "challenge completed concurrently", challengeId: challengeId, code: code, kind: ErrUnauthorized,
+			change: func(fake *fakeTwoFactor, users twoFactorUsers) { fake.raced = true },
+		},
+		{
+			name: "replayed recovery code", challengeId: challengeId, code: "abcde-fghij", kind: ErrUnauthorized,
+			change: func(fake *fakeTwoFactor, users twoFactorUsers) { fake.raced = true },
+		},
+		{
+			name: "locked", challengeId: challengeId, code: code, kind: ErrForbidden, failures: maxTwoFactorFailures,
+			change: func(fake *fakeTwoFactor, users twoFactorUsers) {
+				fake.twoFactor.Failures = maxTwoFactorFailures
+				fake.twoFactor.FailedAt = at(time.Now().Add(-time.Minute))
+			},
+		},
+		{
+			name: "lock expired", challengeId: challengeId, code: code, completed: true,
+			change: func(fake *fakeTwoFactor, users twoFactorUsers) {
+				fake.twoFactor.Failures = maxTwoFactorFailures
+				fake.twoFactor.FailedAt = at(time.Now().Add(-twoFactorLockout - time.Minute))
+			},
+		},
+		{
+			name: "disabled", challengeId: challengeId, code: code, kind: ErrUnauthorized,
+			change: func(fake *fakeTwoFactor, users twoFactorUsers) { fake.twoFactor.Enabled = false },
+		},
+		{
+			name: "suspended account", challengeId: challengeId, code: code, kind: ErrForbidden, completed: true,
+			change: func(fake *fakeTwoFactor, users twoFactorUsers) {
+				users.users[1] = models.User{ID: 1, SuspendedAt: at(time.Now())}
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			twoFactor := &fakeTwoFactor{
+				twoFactor: models.TwoFactor{
+					UserID:    1,
+					Secret:    secret,
+					Enabled:   true,
+					Challenge: challengeId,
+				},
+				recoveryCodes: map[string]bool{security.HashRecoveryCode("abcde-fghij"): true},
+			}
+			users := twoFactorUsers{users: map[uint64]models.User{1: {ID: 1, Nickname: "ana", Role: models.RoleModerator}}}
+			if test.change != nil {
+				test.change(twoFactor, users)
+			}
+			service := NewTwoFactorService(twoFactor, users)
+
+			user, recovery, error := service.Complete(1, test.challengeId, test.code)
+			if kindOf(error) != test.kind {
+				t.Fatalf("Complete = %v, want %v", error, test.kind)
+			}
+			if test.kind == nil && user != (models.User{ID: 1, Role: models.RoleModerator}) {
+				t.Errorf("Complete = %+v, want user 1 as a moderator", user)
+			}
+			if recovery != test.recovery {
+				t.Errorf("recovery = %v, want %v", recovery, test.recovery)
+			}
+			if twoFactor.completed != test.completed {
+				t.Errorf("challenge completed = %v, want %v", twoFactor.completed, test.completed)
+			}
+			if spent := !twoFactor.recoveryCodes[security.HashRecoveryCode("abcde-fghij")]; spent != test.spent {
+				t.Errorf("recovery code spent = %v, want %v", spent, test.spent)
+			}
+			if twoFactor.twoFactor.Failures != test.failures {
+				t.Errorf("failures = %d, want %d", twoFactor.twoFactor.Failures, test.failures)
+			}
+		})
+	}
+}
@@ -0,0 +1,418 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/wesleywcr/dev-book/api/events"
+	"github.com/wesleywcr/dev-book/api/models"
+	"github.com/wesleywcr/dev-book/api/security"
+	"github.com/wesleywcr/dev-book/api/storage"
+)
+
+// UserRepository is the storage UserService depends on.
+type UserRepository interface {
+	Create(user models.User) (uint64, error)
+	Search(nameOrNickname string) ([]models.User, error)
+	SearchPerId(ID uint64) (models.User, error)
+	Update(ID uint64, user models.User) error
+	Delete(ID uint64) error
+	SearchEmail(email string) (models.User, error)
+	Follow(userId, followerId uint64) error
+	UnFollow(userId, followerId uint64) error
+	SearchFollowers(userId uint64) ([]models.User, error)
+	SearchFollowing(userId uint64) ([]models.User, error)
+	GetPassword(userId uint64) (string, error)
+	UpdatePassword(userId uint64, password string) error
+	SearchNicknames(nicknames []string) ([]models.User, error)
+	Block(blockerId, blockedId uint64) error
+	Unblock(blockerId, blockedId uint64) error
+	Blocked(userId, otherId uint64) (bool, error)
+	Silenced(userId, otherId uint64) (bool, error)
+	Mute(muterId, mutedId uint64) error
+	Unmute(muterId, mutedId uint64) error
+	IsFollower(userId, followerId uint64) (bool, error)
+	CreateFollowRequest(userId, followerId uint64) error
+	DeleteFollowRequest(userId, followerId uint64) (bool, error)
+	SearchFollowRequests(userId uint64, page models.Page) ([]models.FollowRequest, error)
+	UpdateAvatar(ID uint64, key string) error
+	UpdateBanner(ID uint64, key string) error
+	SearchCounts(ID uint64) (models.UserCounts, error)
+	SearchRelationship(viewerId, userId uint64) (models.Relationship, error)
+	SearchMutuals(viewerId, userId uint64, page models.Page) ([]models.User, error)
+	SearchAll(role models.Role, suspended *bool, page models.Page) ([]models.User, error)
+	Suspend(ID uint64, suspended bool) error
+	UpdateRole(ID uint64, role models.Role) error
+}
+
+// UserService owns the rules about accounts and follow relationships.
+type UserService struct {
+	users         UserRepository
+	notifications NotificationRepository
+	broker        events.Broker
+	files         storage.Storage
+	timeline      *Timeline
+}
+
+func NewUserService(
+	users UserRepository,
+	notifications NotificationRepository,
+	broker events.Broker,
+	files storage.Storage,
+	timeline *Timeline,
+) *UserService {
+	return &UserService{users, notifications, broker, files, timeline}
+}
+
+// Create validates and registers a new user.
+func (service UserService) Create(user models.User) (models.User, error) {
+	if error := user.Prepare("register"); error != nil {
+		return models.User{}, invalid(error)
+	}
+
+	ID, error := service.users.Create(user)
+	if error != nil {
+		return models.User{}, error
+	}
+	user.ID = ID
+	return user, nil
+}
+
+// Authenticate returns the user owning the email/password pair, with their
+// role. Suspended accounts cannot log in. When the email belongs to an
+// account, the user returned along with the error carries its ID, so the
+// failed attempt can be audited.
+func (service UserService) Authenticate(email, password string) (models.User, error) {
+	userSalvedInDB, error := service.users.SearchEmail(email)
+	if error != nil {
+		return models.User{}, error
+	}
+
+	if error = security.VerificatedPassoword(userSalvedInDB.Password, password); error != nil {
+		return models.User{ID: userSalvedInDB.ID}, unauthorized(error)
+	}
+	if userSalvedInDB.SuspendedAt != nil {
+		return models.User{ID: userSalvedInDB.ID}, forbidden("Esta conta está suspensa")
+	}
+	return models.User{ID: userSalvedInDB.ID, Role: userSalvedInDB.Role}, nil
+}
+
+func (service UserService) Search(nameOrNickname string) ([]models.User, error) {
+	return service.withURLs(service.users.Search(nameOrNickname))
+}
+
+// Get returns the profile of userId with their follower, following and
+// publication counts.
+func (service UserService) Get(userId uint64) (models.User, error) {
+	user, error := service.users.SearchPerId(userId)
+	if error != nil || user.ID == 0 {
+		return user, error
+	}
+
+	counts, error := service.users.SearchCounts(userId)
+	if error != nil {
+		return models.User{}, error
+	}
+	user.Counts = &counts
+	withProfileURLs(service.files, &user)
+	return user, nil
+}
+
+// Update changes the profile of userId; only the user can update their own profile.
+func (service UserService) Update(actorId, userId uint64, user models.User) error {
+	if actorId != userId {
+		return forbidden("Não é possível atualizar um usuário que não é o seu")
+	}
+
+	if error := user.Prepare("update"); error != nil {
+		return invalid(error)
+	}
+	return service.users.Update(userId, user)
+}
+
+// Delete removes the account of userId; only the user can delete their own account.
+func (service UserService) Delete(actorId, userId uint64) error {
+	if actorId != userId {
+		return forbidden("Não é possível deletar um usuário que não é o seu")
+	}
+	return service.users.Delete(userId)
+}
+
+// Follow makes followerId follow userId, who gets notified. Following a
+// private account only requests it until the owner approves; pending
+// reports that case.
+func (service UserService) Follow(followerId, userId uint64) (pending bool, error error) {
+	if followerId == userId {
+		return false, forbidden("Não é possível seguir você mesmo")
+	}
+	if error = ensureNotBlocked(service.users, followerId, userId); error != nil {
+		return false, error
+	}
+
+	user, error := service.users.SearchPerId(userId)
+	if error != nil {
+		return false, error
+	}
+	if user.ID == 0 {
+		return false, notFound("Usuário não encontrado")
+	}
+
+	if user.IsPrivate {
+		following, error := service.users.IsFollower(userId, followerId)
+		if error != nil {
+			return false, error
+		}
+		if !following {
+			if error = service.users.CreateFollowRequest(userId, followerId); error != nil {
+				return false, error
+			}
+			return true, notify(service.notifications, models.NotificationEvent{
+				UserID:  userId,
+				ActorID: followerId,
+				Kind:    models.NotificationFollowRequest,
+			})
+		}
+	}
+
+	return false, service.follow(userId, followerId)
+}
+
+// follow stores the relationship and tells userId about the new follower.
+func (service UserService) follow(userId, followerId uint64) error {
+	if error := service.users.Follow(userId, followerId); error != nil {
+		return error
+	}
+	if error := service.timeline.follow(followerId, userId); error != nil {
+		return error
+	}
+
+	publish(service.broker, userId, events.Event{
+		Type:  events.TypeFollow,
+		Data:  events.Follow{FollowerID: followerId},
+		Actor: followerId,
+	})
+	return notify(service.notifications, models.NotificationEvent{
+		UserID:  userId,
+		ActorID: followerId,
+		Kind:    models.NotificationFollow,
+	})
+}
+
+// UnFollow makes followerId stop following userId, or withdraws their
+// pending request.
+func (service UserService) UnFollow(followerId, userId uint64) error {
+	if followerId == userId {
+		return forbidden("Não é possível deixar de seguir você mesmo")
+	}
+	if _, error := service.users.DeleteFollowRequest(userId, followerId); error != nil {
+		return error
+	}
+	if error := service.users.UnFollow(userId, followerId); error != nil {
+		return error
+	}
+	return service.timeline.unfollow(followerId, userId)
+}
+
+// FollowRequests returns the pending requests to follow userId.
+func (service UserService) FollowRequests(userId uint64, page models.Page) ([]models.FollowRequest, error) {
+	return service.users.SearchFollowRequests(userId, page)
+}
+
+// ApproveFollowRequest makes followerId a follower of userId, who had
+// requested it; followerId gets notified.
+func (service UserService) ApproveFollowRequest(userId, followerId uint64) error {
+	requested, error := service.users.DeleteFollowRequest(userId, followerId)
+	if error != nil {
+		return error
+	}
+	if !requested {
+		return notFound("Solicitação para seguir não encontrada")
+	}
+
+	if error = service.follow(userId, followerId); error != nil {
+		return error
+	}
+	return notify(service.notifications, models.NotificationEvent{
+		UserID:  followerId,
+		ActorID: userId,
+		Kind:    models.NotificationFollowAccepted,
+	})
+}
+
+func (service UserService) RejectFollowRequest(userId, followerId uint64) error {
+	requested, error := service.users.DeleteFollowRequest(userId, followerId)
+	if error != nil {
+		return error
+	}
+	if !requested {
+		return notFound("Solicitação para seguir não encontrada")
+	}
+	return nil
+}
+
+// Block makes blockerId block userId; neither can follow, like or message
+// the other until it is undone.
+func (service UserService) Block(blockerId, userId uint64) error {
+	if blockerId == userId {
+		return forbidden("Não é possível bloquear você mesmo")
+	}
+	if error := service.users.Block(blockerId, userId); error != nil {
+		return error
+	}
+
+	// Blocking ends the follows in both directions.
+	if error := service.timeline.unfollow(blockerId, userId); error != nil {
+		return error
+	}
+	return service.timeline.unfollow(userId, blockerId)
+}
+
+func (service UserService) Unblock(blockerId, userId uint64) error {
+	return service.users.Unblock(blockerId, userId)
+}
+
+// Mute hides the publications of userId from the feed of muterId.
+func (service UserService) Mute(muterId, userId uint64) error {
+	if muterId == userId {
+		return forbidden("Não é possível silenciar você mesmo")
+	}
+	return service.users.Mute(muterId, userId)
+}
+
+func (service UserService) Unmute(muterId, userId uint64) error {
+	return service.users.Unmute(muterId, userId)
+}
+
+// Silenced tells whether the events caused by actorId are kept from userId,
+// because either blocked the other or userId muted actorId.
+func (service UserService) Silenced(userId, actorId uint64) (bool, error) {
+	return service.users.Silenced(userId, actorId)
+}
+
+// Followers returns who follows userId, as long as viewerId can see the account
+// and neither blocked the other.
+func (service UserService) Followers(viewerId, userId uint64) ([]models.User, error) {
+	if error := ensureNotBlocked(service.users, viewerId, userId); error != nil {
+		return nil, error
+	}
+	if error := ensureVisible(service.users, viewerId, userId); error != nil {
+		return nil, error
+	}
+	return service.withURLs(service.users.SearchFollowers(userId))
+}
+
+// Following returns who userId follows, as long as viewerId can see the account
+// and neither blocked the other.
+func (service UserService) Following(viewerId, userId uint64) ([]models.User, error) {
+	if error := ensureNotBlocked(service.users, viewerId, userId); error != nil {
+		return nil, error
+	}
+	if error := ensureVisible(service.users, viewerId, userId); error != nil {
+		return nil, error
+	}
+	return service.withURLs(service.users.SearchFollowing(userId))
+}
+
+// Relationship returns how viewerId relates to userId.
+func (service UserService) Relationship(viewerId, userId uint64) (models.Relationship, error) {
+	if _, error := service.existing(userId); error != nil {
+		return models.Relationship{}, error
+	}
+	return service.users.SearchRelationship(viewerId, userId)
+}
+
+// Mutuals returns the users viewerId follows who follow userId, as long as
+// viewerId can see the account and neither blocked the other.
+func (service UserService) Mutuals(viewerId, userId uint64, page models.Page) ([]models.User, error) {
+	if _, error := service.existing(userId); error != nil {
+		return nil, error
+	}
+	if error := ensureNotBlocked(service.users, viewerId, userId); error != nil {
+		return nil, error
+	}
+	if error := ensureVisible(service.users, viewerId, userId); error != nil {
+		return nil, error
+	}
+	return service.withURLs(service.users.SearchMutuals(viewerId, userId, page))
+}
+
+// existing returns userId, failing when there is no such user.
+func (service UserService) existing(userId uint64) (models.User, error) {
+	user, error := service.users.SearchPerId(userId)
+	if error != nil {
+		return models.User{}, error
+	}
+	if user.ID == 0 {
+		return models.User{}, notFound("Usuário não encontrado")
+	}
+	return user, nil
+}
+
+// withURLs fills the image URLs of a list of users as it is returned by the
+// repository.
+func (service UserService) withURLs(users []models.User, error error) ([]models.User, error) {
+	if error != nil {
+		return nil, error
+	}
+	for i := range users {
+		withProfileURLs(service.files, &users[i])
+	}
+	return users, nil
+}
+
+// UpdatePassword replaces the password of userId after checking the current one.
+func (service UserService) UpdatePassword(actorId, userId uint64, password models.Password) error {
+	if actorId != userId {
+		return forbidden("Não é possível atualizar senha de um usuário que não seja o seu")
+	}
+
+	passwordSavedDB, error := service.users.GetPassword(userId)
+	if error != nil {
+		return error
+	}
+	if error = security.VerificatedPassoword(passwordSavedDB, password.Current); error != nil {
+		return unauthorized(errors.New("A senha atual está incorreta"))
+	}
+
+	passwordWithHash, error := security.Hash(password.New)
+	if error != nil {
+		return invalid(error)
+	}
+	return service.users.UpdatePassword(userId, string(passwordWithHash))
+}
+
+// ensureNotBlocked fails when either user blocked the other.
+func ensureNotBlocked(users UserRepository, actorId, userId uint64) error {
+	blocked, error := users.Blocked(actorId, userId)
+	if error != nil {
+		return error
+	}
+	if blocked {
+		return forbidden("Não é possível interagir com este usuário")
+	}
+	return nil
+}
+
+// ensureVisible fails when userId is a private account and viewerId is
+// neither its owner nor an approved follower.
+func ensureVisible(users UserRepository, viewerId, userId uint64) error {
+	if viewerId == userId {
+		return nil
+	}
+
+	user, error := users.SearchPerId(userId)
+	if error != nil {
+		return error
+	}
+	if !user.IsPrivate {
+		return nil
+	}
+
+	following, error := users.IsFollower(userId, viewerId)
+	if error != nil {
+		return error
+	}
+	if !following {
+		return forbidden("Esta conta é privada")
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/wesleywcr/dev-book/api/events"
+	"github.com/wesleywcr/dev-book/api/models"
+)
+
+type fakeUsers struct {
+	UserRepository
+	users     map[uint64]models.User
+	followers map[pair]bool
+	blocks    map[pair]bool
+	requests  map[pair]bool
+}
+
+func newFakeUsers(users ...models.User) *fakeUsers {
+	fake := &fakeUsers{
+		users:     map[uint64]models.User{},
+		followers: map[pair]bool{},
+		blocks:    map[pair]bool{},
+		requests:  map[pair]bool{},
+	}
+	for _, user := range users {
+		fake.users[user.ID] = user
+	}
+	return fake
+}
+
+func (fake *fakeUsers) SearchPerId(ID uint64) (models.User, error) {
+	return fake.users[ID], nil
+}
+
+func (fake *fakeUsers) Blocked(userId, otherId uint64) (bool, error) {
+	return fake.blocks[pair{userId, otherId}] || fake.blocks[pair{otherId, userId}], nil
+}
+
+func (fake *fakeUsers) IsFollower(userId, followerId uint64) (bool, error) {
+	return fake.followers[pair{userId, followerId}], nil
+}
+
+func (fake *fakeUsers) Follow(userId, followerId uint64) error {
+	fake.followers[pair{userId, followerId}] = true
+	return nil
+}
+
+func (fake *fakeUsers) SearchFollowers(userId uint64) ([]models.User, error) {
+	var followers []models.User
+	for relationship := range fake.followers {
+		if relationship[0] == userId {
+			followers = append(followers, fake.users[relationship[1]])
+		}
+	}
+	return followers, nil
+}
+
+func (fake *fakeUsers) SearchFollowing(followerId uint64) ([]models.User, error) {
+	var following []models.User
+	for relationship := range fake.followers {
+		if relationship[1] == followerId {
+			following = append(following, fake.users[relationship[0]])
+		}
+	}
+	return following, nil
+}
+
+// SearchMutuals returns the users followerId follows who follow userId.
+func (fake *fakeUsers) SearchMutuals(followerId, userId uint64, page models.Page) ([]models.User, error) {
+	var mutuals []models.User
+	for relationship := range fake.followers {
+		if relationship[1] == followerId && fake.followers[pair{userId, relationship[0]}] {
+			mutuals = append(mutuals, fake.users[relationship[0]])
+		}
+	}
+	return mutuals, nil
+}
+
+func (fake *fakeUsers) CreateFollowRequest(userId, followerId uint64) error {
+	fake.requests[pair{userId, followerId}] = true
+	return nil
+}
+
+// recordedNotifications keeps the notification events created.
+type recordedNotifications struct {
+	NotificationRepository
+	events []models.NotificationEvent
+}
+
+func (fake *recordedNotifications) Create(event models.NotificationEvent) error {
+	fake.events = append(fake.events, event)
+	return nil
+}
+
+// fakeTimelines accepts the backfill of the timelines of new followers.
+type fakeTimelines struct {
+	TimelineRepository
+}
+
+func (fakeTimelines) Backfill(userId, authorId uint64, limit int) error { return nil }
+
+func TestUserServiceFollow(t *testing.T) {
+	tests := []struct {
+		name         string
+		userId       uint64
+		pending      bool
+		kind         error
+		following    bool
+		notification models.NotificationKind
+	}{
+		{name: "self", userId: 1, kind: ErrForbidden},
+		{name: "missing user", userId: 99, kind: ErrNotFound},
+		{name: "blocked user", userId: 5, kind: ErrForbidden},
+		{name: "public account", userId: 2, following: true, notification: models.NotificationFollow},
+		{name: "private account", userId: 3, pending: true, notification: models.NotificationFollowRequest},
+		{name: "private account already followed", userId: 4, following: true, notification: models.NotificationFollow},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			users := newFakeUsers(
+				models.User{ID: 1, Nickname: "ana"},
+				models.User{ID: 2, Nickname: "bia"},
+				models.User{ID: 3, Nickname: "caio", IsPrivate: true},
+				models.User{ID: 4, Nickname: "duda", IsPrivate: true},
+				models.User{ID: 5, Nickname: "edu"},
+			)
+			users.followers[pair{4, 1}] = true
+			users.blocks[pair{5, 1}] = true
+			notifications := &recordedNotifications{}
+			service := NewUserService(users, notifications, events.NewMemoryBroker(1), newTestStorage(t), NewTimeline(fakeTimelines{}, 100, DefaultRanker))
+
+			pending, error := service.Follow(1, test.userId)
+			if kindOf(error) != test.kind {
+				t.Fatalf("Follow = %v, want %v", error, test.kind)
+			}
+			if pending != test.pending {
+				t.Errorf("pending = %v, want %v", pending, test.pending)
+			}
+			if following := users.followers[pair{test.userId, 1}]; following != test.following {
+				t.Errorf("following = %v, want %v", following, test.following)
+			}
+			if requested := users.requests[pair{test.userId, 1}]; requested != test.pending {
+				t.Errorf("follow requested = %v, want %v", requested, test.pending)
+			}
+
+			if test.notification == "" {
+				if len(notifications.events) != 0 {
+					t.Errorf("notified %+v", notifications.events)
+				}
+				return
+			}
+			expected := models.NotificationEvent{UserID: test.userId, ActorID: 1, Kind: test.notification}
+			if len(notifications.events) != 1 || notifications.events[0] != expected {
+				t.Errorf("notified %+v, want %+v", notifications.events, expected)
+			}
+		})
+	}
+}
+
+func TestUserServiceFollowers(t *testing.T) {
+	tests := []struct {
+		name     string
+		viewerId uint64
+		kind     error
+	}{
+		{name: "public account", viewerId: 2},
+		{name: "own account", viewerId: 1},
+		{name: "blocked by the account", viewerId: 3, kind: ErrForbidden},
+		{name: "blocking the account", viewerId: 4, kind: ErrForbidden},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			users := newFakeUsers(
+				models.User{ID: 1, Nickname: "ana"},
+				models.User{ID: 2, Nickname: "bia"},
+				models.User{ID: 3, Nickname: "caio"},
+				models.User{ID: 4, Nickname: "duda"},
+			)
+			users.followers[pair{1, 2}] = true
+			users.blocks[pair{1, 3}] = true
+			users.blocks[pair{4, 1}] = true
+			service := NewUserService(users, &recordedNotifications{}, events.NewMemoryBroker(1), newTestStorage(t), NewTimeline(fakeTimelines{}, 100, DefaultRanker))
+
+			for name, list := range map[string]func(viewerId, userId uint64) ([]models.User, error){
+				"Followers": service.Followers,
+				"Following": service.Following,
+				"Mutuals": func(viewerId, userId uint64) ([]models.User, error) {
+					return service.Mutuals(viewerId, userId, models.Page{Number: 1, Limit: 20})
+				},
+			} {
+				if _, error := list(test.viewerId, 1); kindOf(error) != test.kind {
+					t.Errorf("%s = %v, want %v", name, error, test.kind)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Local keeps the files in a directory of the server and serves them itself.
+type Local struct {
+	root    string
+	baseURL string
+	route   string
+	files   http.Handler
+}
+
+func NewLocal(root, baseURL string) (*Local, error) {
+	if error := os.MkdirAll(root, 0o755); error != nil {
+		return nil, error
+	}
+
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	base, error := url.Parse(baseURL)
+	if error != nil {
+		return nil, error
+	}
+
+	route := base.Path + "/"
+	return &Local{
+		root:    root,
+		baseURL: baseURL,
+		route:   route,
+		files:   http.StripPrefix(route, http.FileServer(http.Dir(root))),
+	}, nil
+}
+
+// Put writes the file through a temporary one, so readers never see it half written.
+func (local *Local) Put(_ context.Context, key string, content []byte, _ string) error {
+	path := local.path(key)
+	if error := os.MkdirAll(filepath.Dir(path), 0o755); error != nil {
+		return error
+	}
+
+	temporary, error := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if error != nil {
+		return error
+	}
+	defer os.Remove(temporary.Name())
+
+	if _, error = temporary.Write(content); error != nil {
+		temporary.Close()
+		return error
+	}
+	if error = temporary.Close(); error != nil {
+		return error
+	}
+	return os.Rename(temporary.Name(), path)
+}
+
+func (local *Local) Delete(_ context.Context, key string) error {
+	if error := os.Remove(local.path(key)); error != nil && !errors.Is(error, fs.ErrNotExist) {
+		return error
+	}
+	return nil
+}
+
+func (local *Local) URL(key string) string {
+	return local.baseURL + "/" + key
+}
+
+// Route is the path prefix under which ServeHTTP expects to be mounted.
+func (local *Local) Route() string {
+	return local.route
+}
+
+// ServeHTTP serves the stored files, never directory listings.
+func (local *Local) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.HasSuffix(r.URL.Path, "/") {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	local.files.ServeHTTP(w, r)
+}
+
+func (local *Local) path(key string) string {
+	return filepath.Join(local.root, filepath.FromSlash(filepath.Clean("/"+key)))
+}
@@ -0,0 +1,175 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/wesleywcr/dev-book/api/config"
+)
+
+// S3 keeps the files in a bucket of an S3-compatible service, such as AWS S3
+// or a MinIO instance, signing its requests with AWS Signature Version 4.
+type S3 struct {
+	settings config.S3
+	endpoint *url.URL
+	baseURL  string
+	client   *http.Client
+}
+
+// NewS3 returns the storage of a bucket. Without baseURL, the URLs of the
+// files point to the bucket itself, which must then allow public reads.
+func NewS3(settings config.S3, baseURL string) (*S3, error) {
+	endpoint, error := url.Parse(settings.Endpoint)
+	if error != nil {
+		return nil, error
+	}
+	if endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("Endpoint S3 inválido: %q", settings.Endpoint)
+	}
+
+	return &S3{
+		settings: settings,
+		endpoint: endpoint,
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		client:   &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+func (s3 *S3) Put(ctx context.Context, key string, content []byte, contentType string) error {
+	request, error := http.NewRequestWithContext(ctx, http.MethodPut, s3.objectURL(key), bytes.NewReader(content))
+	if error != nil {
+		return error
+	}
+	request.Header.Set("Content-Type", contentType)
+	return s3.do(request, content)
+}
+
+func (s3 *S3) Delete(ctx context.Context, key string) error {
+	request, error := http.NewRequestWithContext(ctx, http.MethodDelete, s3.objectURL(key), nil)
+	if error != nil {
+		return error
+	}
+	return s3.do(request, nil)
+}
+
+func (s3 *S3) URL(key string) string {
+	if s3.baseURL != "" && !strings.HasPrefix(s3.baseURL, "/") {
+		return s3.baseURL + "/" + key
+	}
+	return s3.objectURL(key)
+}
+
+// objectURL addresses key in the bucket, in path or virtual-hosted style.
+func (s3 *S3) objectURL(key string) string {
+	object := *s3.endpoint
+	path, rawPath := "/"+key, "/"+escapePath(key)
+	if s3.settings.PathStyle {
+		path, rawPath = "/"+s3.settings.Bucket+path, "/"+s3.settings.Bucket+rawPath
+	} else {
+		object.Host = s3.settings.Bucket + "." + object.Host
+	}
+	object.RawPath = strings.TrimSuffix(s3.endpoint.EscapedPath(), "/") + rawPath
+	object.Path = strings.TrimSuffix(s3.endpoint.Path, "/") + path
+	return object.String()
+}
+
+func (s3 *S3) do(request *http.Request, payload []byte) error {
+	s3.sign(request, payload, time.Now().UTC())
+
+	response, error := s3.client.Do(request)
+	if error != nil {
+		return error
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= 300 && !(request.Method == http.MethodDelete && response.StatusCode == http.StatusNotFound) {
+		body, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		return fmt.Errorf("S3 respondeu %s a %s %s: %s", response.Status, request.Method, request.URL.Path, body)
+	}
+	return nil
+}
+
+// sign adds the headers of AWS Signature Version 4 to request.
+func (s3 *S3) sign(request *http.Request, payload []byte, now time.Time) {
+	payloadHash := sha256.Sum256(payload)
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	request.Header.Set("X-Amz-Date", amzDate)
+	request.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(payloadHash[:]))
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		request.URL.EscapedPath(),
+		canonicalQuery(request.URL.Query()),
+		"host:" + request.URL.Host,
+		"x-amz-content-sha256:" + hex.EncodeToString(payloadHash[:]),
+		"x-amz-date:" + amzDate,
+		"",
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	scope := date + "/" + s3.settings.Region + "/s3/aws4_request"
+	canonicalHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hex.EncodeToString(canonicalHash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s3.settings.SecretKey), date)
+	key = hmacSHA256(key, s3.settings.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	request.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3.settings.AccessKey, scope, signedHeaders, signature,
+	))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// canonicalQuery sorts and encodes the query parameters as S3 expects in
+// canonical requests, where parameters without a value keep their "=".
+func canonicalQuery(query url.Values) string {
+	parameters := make([]string, 0, len(query))
+	for name, values := range query {
+		for _, value := range values {
+			parameters = append(parameters, escapeQuery(name)+"="+escapeQuery(value))
+		}
+	}
+	sort.Strings(parameters)
+	return strings.Join(parameters, "&")
+}
+
+func escapeQuery(value string) string {
+	return strings.ReplaceAll(url.QueryEscape(value), "+", "%20")
+}
+
+// escapePath encodes each segment of key as S3 expects in canonical requests.
+func escapePath(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = strings.ReplaceAll(url.PathEscape(segment), "+", "%2B")
+	}
+	return strings.Join(segments, "/")
+}
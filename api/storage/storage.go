@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/wesleywcr/dev-book/api/config"
+)
+
+// Storage keeps uploaded files under keys such as "publications/1/3f2a.jpg".
+type Storage interface {
+	Put(ctx context.Context, key string, content []byte, contentType string) error
+	// Delete removes the file stored under key; removing a missing file is
+	// not an error.
+	Delete(ctx context.Context, key string) error
+	// URL is where clients download the file stored under key.
+	URL(key string) string
+}
+
+// Default is the storage used by the services, set by Open.
+var Default Storage
+
+// Open creates Default from the storage settings.
+func Open() error {
+	settings := config.Settings.Storage
+
+	switch settings.Driver {
+	case "local":
+		local, error := NewLocal(settings.Path, settings.BaseURL)
+		if error != nil {
+			return error
+		}
+		Default = local
+	case "s3":
+		s3, error := NewS3(settings.S3, settings.BaseURL)
+		if error != nil {
+			return error
+		}
+		Default = s3
+	default:
+		return fmt.Errorf("Driver de armazenamento não suportado: %q", settings.Driver)
+	}
+	return nil
+}